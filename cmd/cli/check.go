@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/monitor"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// CheckCmd représente la commande 'check'
+var CheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Vérifie immédiatement l'accessibilité de la destination d'un lien court.",
+	Long: `Cette commande lance une vérification du moniteur sur un lien, sans attendre
+sa prochaine échéance, et affiche le résultat (état, code HTTP, certificat, contenu).
+
+Exemple :
+  url-shortener check --code="xyz123"
+  url-shortener check --code="xyz123" --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		// Lecture des flags
+		code, err := cmd.Flags().GetString("code")
+		if err != nil {
+			log.Fatalf("Erreur lors de la lecture du flag --code : %v", err)
+		}
+		if code == "" {
+			fmt.Fprintln(os.Stderr, "ERREUR : le flag --code est requis.")
+			os.Exit(1)
+		}
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		// Chargement de la configuration globale
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatal("FATAL : La configuration n'a pas été chargée correctement.")
+		}
+
+		// Connexion à la base de données via GORM
+		db, err := database.Open(cfg.Database)
+		if err != nil {
+			log.Fatalf("FATAL : Échec de la connexion à la base de données : %v", err)
+		}
+		defer database.Close(db)
+
+		// Repositories + Services
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+		urlMonitor := monitor.NewUrlMonitorFromConfig(linkRepo, repository.NewLinkCheckRepository(db), cfg.Monitor)
+
+		link, err := linkService.GetLinkByShortCode(code)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fmt.Fprintf(os.Stderr, "ERREUR : Aucun lien trouvé pour le code court \"%s\".\n", code)
+				os.Exit(1)
+			}
+			log.Fatalf("FATAL : Échec de la récupération du lien : %v", err)
+		}
+
+		health := urlMonitor.CheckNow(link)
+
+		if asJSON {
+			out, _ := json.MarshalIndent(health, "", "  ")
+			fmt.Println(string(out))
+			return
+		}
+
+		fmt.Printf("Vérification du lien %s (%s)\n", link.ShortCode, link.LongURL)
+		fmt.Printf("État : %s\n", health.State)
+		if health.StatusCode != 0 {
+			fmt.Printf("Code HTTP : %d\n", health.StatusCode)
+		}
+		if health.LastError != "" {
+			fmt.Printf("Erreur : %s\n", health.LastError)
+		}
+		if health.TLS != nil {
+			fmt.Printf("Certificat : valide=%t, expire le %s (%d jour(s))",
+				health.TLS.Valid, health.TLS.ExpiresAt.Format("2006-01-02"), health.TLS.DaysRemaining)
+			if health.TLS.Problem != "" {
+				fmt.Printf(", problème : %s", health.TLS.Problem)
+			}
+			fmt.Println()
+		}
+		if health.Content != nil && health.Content.Current != nil {
+			fmt.Printf("Titre de la page : %s\n", health.Content.Current.Title)
+		}
+		if health.State != monitor.StateAccessible {
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	CheckCmd.Flags().String("code", "", "Le code court du lien à vérifier")
+	CheckCmd.Flags().Bool("json", false, "Afficher le résultat au format JSON")
+	CheckCmd.MarkFlagRequired("code")
+
+	cmd2.RootCmd.AddCommand(CheckCmd)
+}
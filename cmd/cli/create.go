@@ -1,17 +1,19 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
 // CreateCmd représente la commande 'create'
@@ -20,8 +22,26 @@ var CreateCmd = &cobra.Command{
 	Short: "Crée une URL courte à partir d'une URL longue.",
 	Long: `Cette commande raccourcit une URL longue fournie via --url et affiche le code court généré.
 
+Avec --file, les URLs sont lues dans un fichier CSV dont l'en-tête contient une colonne
+"long_url" (ou "url"), une colonne "alias" facultative ; les autres colonnes sont enregistrées
+comme métadonnées du lien. Le résultat de chaque ligne est écrit en CSV sur la sortie standard
+ou dans --output. Avec --atomic, aucun lien n'est créé si une seule ligne échoue.
+
 Exemple :
-  url-shortener create --url="https://www.google.com/search?q=go+lang"`,
+  url-shortener create --url="https://www.google.com/search?q=go+lang"
+  url-shortener create --url="https://example.com" --alias=promo
+  url-shortener create --url="https://example.com" --on-failure=fallback --fallback-url="https://example.org"
+  url-shortener create --url="https://docs.example.com" --alias=docs --forward-path --redirect-type=308
+  url-shortener create --url="https://example.com/promo" --utm-source=newsletter --utm-medium=email --utm-campaign=rentree
+  url-shortener create --url="https://example.com/app" --rule="os=ios=>https://apps.apple.com/app/id1" --rule="os=android=>https://play.google.com/store/apps/details?id=app"
+  url-shortener create --url="https://example.com" --rule="country=CH|AT=>https://example.com/dach"
+  url-shortener create --url="https://example.com" --rule="lang=fr|de,time=08:00-18:00,tz=Europe/Paris=>https://example.com/support"
+  url-shortener create --url="https://example.com/v1" --variant="ancienne:70=>https://example.com/v1" --variant="nouvelle:30=>https://example.com/v2" --sticky-variants
+  url-shortener create --url="https://example.com/offre" --goal=inscription
+  url-shortener create --url="https://intranet.example.com/rapport.pdf" --password="s3cret"
+  url-shortener create --url="https://app.example.com/reset" --require-signature
+  url-shortener create --url="https://example.com/soldes" --og-title="Soldes d'hiver" --og-image="https://example.com/soldes.jpg"
+  url-shortener create --file=urls.csv --output=resultats.csv --atomic`,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		// Lecture du flag --url
@@ -29,16 +49,133 @@ Exemple :
 		if err != nil {
 			log.Fatalf("Erreur lors de la lecture du flag --url : %v", err)
 		}
-		if urlStr == "" {
-			fmt.Fprintln(os.Stderr, "ERREUR : le flag --url est requis.")
+		file, _ := cmd.Flags().GetString("file")
+		if (urlStr == "") == (file == "") {
+			fmt.Fprintln(os.Stderr, "ERREUR : l'un des flags --url ou --file est requis (mais pas les deux).")
+			os.Exit(1)
+		}
+		alias, _ := cmd.Flags().GetString("alias")
+		if alias != "" && file != "" {
+			fmt.Fprintln(os.Stderr, "ERREUR : --alias s'utilise avec --url ; avec --file, utilisez la colonne \"alias\".")
 			os.Exit(1)
 		}
 
-		// Validation du format de l’URL
-		if _, err := url.ParseRequestURI(urlStr); err != nil {
-			fmt.Fprintf(os.Stderr, "ERREUR : URL invalide \"%s\" : %v\n", urlStr, err)
+		onFailure, _ := cmd.Flags().GetString("on-failure")
+		failureThreshold, _ := cmd.Flags().GetInt("failure-threshold")
+		fallbackURL, _ := cmd.Flags().GetString("fallback-url")
+		watchContent, _ := cmd.Flags().GetBool("watch-content")
+		interstitial, _ := cmd.Flags().GetBool("interstitial")
+		redirectType, _ := cmd.Flags().GetInt("redirect-type")
+		forwardQuery, _ := cmd.Flags().GetBool("forward-query")
+		forwardPath, _ := cmd.Flags().GetBool("forward-path")
+		var utm models.UTMParams
+		utm.Source, _ = cmd.Flags().GetString("utm-source")
+		utm.Medium, _ = cmd.Flags().GetString("utm-medium")
+		utm.Campaign, _ = cmd.Flags().GetString("utm-campaign")
+		utm.Term, _ = cmd.Flags().GetString("utm-term")
+		utm.Content, _ = cmd.Flags().GetString("utm-content")
+
+		ruleFlags, _ := cmd.Flags().GetStringArray("rule")
+		if len(ruleFlags) > 0 && file != "" {
+			fmt.Fprintln(os.Stderr, "ERREUR : --rule s'utilise avec --url uniquement.")
 			os.Exit(1)
 		}
+		rules := make([]models.LinkRule, 0, len(ruleFlags))
+		for _, value := range ruleFlags {
+			rule, err := parseRuleFlag(value)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERREUR : --rule \"%s\" : %v\n", value, err)
+				os.Exit(1)
+			}
+			rules = append(rules, rule)
+		}
+
+		variantFlags, _ := cmd.Flags().GetStringArray("variant")
+		stickyVariants, _ := cmd.Flags().GetBool("sticky-variants")
+		trackConversions, _ := cmd.Flags().GetBool("track-conversions")
+		conversionGoal, _ := cmd.Flags().GetString("goal")
+		if len(variantFlags) > 0 && file != "" {
+			fmt.Fprintln(os.Stderr, "ERREUR : --variant s'utilise avec --url uniquement.")
+			os.Exit(1)
+		}
+		variants := make([]models.LinkVariant, 0, len(variantFlags))
+		for _, value := range variantFlags {
+			variant, err := parseVariantFlag(value)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERREUR : --variant \"%s\" : %v\n", value, err)
+				os.Exit(1)
+			}
+			variants = append(variants, variant)
+		}
+
+		// Aperçu Open Graph : propre à une destination, il n'a pas de sens pour tout un fichier
+		var preview models.LinkPreview
+		preview.Title, _ = cmd.Flags().GetString("og-title")
+		preview.Description, _ = cmd.Flags().GetString("og-description")
+		preview.Image, _ = cmd.Flags().GetString("og-image")
+		if !preview.IsZero() && file != "" {
+			fmt.Fprintln(os.Stderr, "ERREUR : --og-title, --og-description et --og-image s'utilisent avec --url uniquement.")
+			os.Exit(1)
+		}
+
+		password, _ := cmd.Flags().GetString("password")
+		requireSignature, _ := cmd.Flags().GetBool("require-signature")
+
+		// Réglages de surveillance : seuls les flags fournis sont appliqués
+		var monitoring services.MonitoringSettings
+		if cmd.Flags().Changed("no-monitoring") {
+			noMonitoring, _ := cmd.Flags().GetBool("no-monitoring")
+			enabled := !noMonitoring
+			monitoring.Enabled = &enabled
+		}
+		if cmd.Flags().Changed("monitor-interval") {
+			interval, _ := cmd.Flags().GetInt("monitor-interval")
+			monitoring.IntervalMinutes = &interval
+		}
+		if cmd.Flags().Changed("monitor-priority") {
+			priority, _ := cmd.Flags().GetInt("monitor-priority")
+			monitoring.Priority = &priority
+		}
+
+		opts := services.CreateLinkOptions{
+			FailurePolicy:    onFailure,
+			FailureThreshold: failureThreshold,
+			FallbackURL:      fallbackURL,
+
+			ContentMonitoring: watchContent,
+			Interstitial:      interstitial,
+			Monitoring:        monitoring,
+			Alias:             alias,
+
+			RedirectType: redirectType,
+			ForwardQuery: forwardQuery,
+			ForwardPath:  forwardPath,
+			UTM:          utm,
+			Rules:        rules,
+
+			Variants:       variants,
+			StickyVariants: stickyVariants,
+
+			TrackConversions: trackConversions,
+			ConversionGoal:   conversionGoal,
+
+			Preview:  preview,
+			Password: password,
+
+			RequireSignature: requireSignature,
+		}
+		if cmd.Flags().Changed("reuse-existing") {
+			reuse, _ := cmd.Flags().GetBool("reuse-existing")
+			opts.ReuseExisting = &reuse
+		}
+
+		// Création par lot depuis un fichier CSV
+		if file != "" {
+			output, _ := cmd.Flags().GetString("output")
+			atomic, _ := cmd.Flags().GetBool("atomic")
+			runBatchCreate(file, output, atomic, opts)
+			return
+		}
 
 		// Chargement de la configuration globale
 		cfg := cmd2.Cfg
@@ -47,27 +184,30 @@ Exemple :
 		}
 
 		// Connexion à la base de données via GORM
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		db, err := database.Open(cfg.Database)
 		if err != nil {
 			log.Fatalf("FATAL : Échec de la connexion à la base de données : %v", err)
 		}
-
-		sqlDB, err := db.DB()
-		if err != nil {
-			log.Fatalf("FATAL : Échec d'accès au driver SQL natif : %v", err)
-		}
-		defer func() {
-			if err := sqlDB.Close(); err != nil {
-				log.Printf("WARN : Échec de la fermeture de la connexion DB : %v", err)
-			}
-		}()
+		defer database.Close(db)
 
 		// Repositories + Services
 		linkRepo := repository.NewLinkRepository(db)
 		linkService := services.NewLinkService(linkRepo)
+		linkService.SetReuseExisting(cfg.Links.ReuseExisting)
+		linkService.SetURLPolicy(services.NewURLPolicy(cfg.Links))
+		applyThreatLists(linkService, cfg)
 
 		// Création du lien court
-		link, err := linkService.CreateLink(urlStr)
+		link, reused, err := linkService.CreateOrReuseLink(urlStr, opts)
+		var urlErr *services.URLError
+		if errors.As(err, &urlErr) {
+			fmt.Fprintf(os.Stderr, "ERREUR : URL invalide \"%s\" (%s) : %v\n", urlStr, urlErr.Code, urlErr)
+			os.Exit(1)
+		}
+		if errors.Is(err, services.ErrDestinationBlocked) {
+			fmt.Fprintf(os.Stderr, "ERREUR : Destination bloquée par les listes de menaces \"%s\"\n", urlStr)
+			os.Exit(1)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "ERREUR : Échec de la création de l'URL courte : %v\n", err)
 			os.Exit(1)
@@ -75,18 +215,132 @@ Exemple :
 
 		fullShortURL := fmt.Sprintf("%s/%s", cfg.Server.BaseURL, link.ShortCode)
 
-		fmt.Println("URL courte créée avec succès ✔️")
+		if reused {
+			fmt.Println("Un lien existe déjà vers cette destination, il est réutilisé ✔️")
+		} else {
+			fmt.Println("URL courte créée avec succès ✔️")
+		}
 		fmt.Printf("Code court : %s\n", link.ShortCode)
 		fmt.Printf("URL complète : %s\n", fullShortURL)
 	},
 }
 
+// parseVariantFlag lit une variante A/B "[nom:]poids=>destination" (ex: "nouvelle:30=>https://...").
+func parseVariantFlag(value string) (models.LinkVariant, error) {
+	var variant models.LinkVariant
+	spec, destination, ok := strings.Cut(value, "=>")
+	if !ok || strings.TrimSpace(destination) == "" {
+		return variant, errors.New("format attendu : [nom:]poids=>destination")
+	}
+	variant.Destination = strings.TrimSpace(destination)
+	name, weight, hasName := strings.Cut(spec, ":")
+	if !hasName {
+		name, weight = "", spec
+	}
+	variant.Name = strings.TrimSpace(name)
+	if weight = strings.TrimSpace(weight); weight != "" {
+		w, err := strconv.Atoi(weight)
+		if err != nil {
+			return variant, fmt.Errorf("poids invalide %q", weight)
+		}
+		variant.Weight = w
+	}
+	return variant, nil
+}
+
+// parseRuleFlag lit une règle de ciblage "conditions=>destination", où les conditions sont
+// des paires clé=valeur séparées par des virgules : os, device, lang et country (valeurs
+// séparées par '|'), time (HH:MM-HH:MM) et tz. Les valeurs sont validées par le service.
+func parseRuleFlag(value string) (models.LinkRule, error) {
+	var rule models.LinkRule
+	conditions, destination, ok := strings.Cut(value, "=>")
+	if !ok || strings.TrimSpace(destination) == "" {
+		return rule, errors.New("format attendu : conditions=>destination")
+	}
+	rule.Destination = strings.TrimSpace(destination)
+	for _, cond := range strings.Split(conditions, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(cond), "=")
+		if !ok {
+			return rule, fmt.Errorf("condition invalide %q (clé=valeur attendu)", cond)
+		}
+		switch strings.TrimSpace(key) {
+		case "os":
+			rule.OS = val
+		case "device":
+			rule.Device = val
+		case "lang":
+			rule.Languages = strings.ReplaceAll(val, "|", ",")
+		case "country":
+			rule.Countries = strings.ReplaceAll(val, "|", ",")
+		case "time":
+			from, to, ok := strings.Cut(val, "-")
+			if !ok {
+				return rule, fmt.Errorf("plage horaire invalide %q (HH:MM-HH:MM attendu)", val)
+			}
+			rule.TimeFrom, rule.TimeTo = from, to
+		case "tz":
+			rule.Timezone = val
+		default:
+			return rule, fmt.Errorf("condition inconnue %q (os, device, lang, country, time ou tz)", key)
+		}
+	}
+	return rule, nil
+}
+
 func init() {
 	// Définition du flag --url
 	CreateCmd.Flags().String("url", "", "L'URL longue à raccourcir")
+	CreateCmd.Flags().String("alias", "", "Code court souhaité (3 à 10 caractères : lettres, chiffres, '-' et '_')")
+
+	// Création par lot
+	CreateCmd.Flags().String("file", "", "Fichier CSV d'URLs à raccourcir (colonnes long_url, alias et métadonnées)")
+	CreateCmd.Flags().StringP("output", "o", "", "Fichier CSV des résultats avec --file (sortie standard par défaut)")
+	CreateCmd.Flags().Bool("atomic", false, "Avec --file : ne créer aucun lien si une ligne échoue")
+	CreateCmd.Flags().Bool("reuse-existing", false, "Réutiliser le lien existant vers la même destination (défaut : links.reuse_existing)")
+
+	// Politique appliquée quand la destination est durablement inaccessible
+	CreateCmd.Flags().String("on-failure", "keep", "Politique en cas de destination cassée : keep, unavailable ou fallback")
+	CreateCmd.Flags().Int("failure-threshold", 0, "Échecs consécutifs avant d'appliquer la politique (0 = valeur de la configuration)")
+	CreateCmd.Flags().String("fallback-url", "", "URL de repli utilisée avec --on-failure=fallback")
+
+	// Détection des changements de contenu de la destination
+	CreateCmd.Flags().Bool("watch-content", false, "Surveiller les changements de contenu de la page de destination")
+	CreateCmd.Flags().Bool("interstitial", false, "Afficher une page intermédiaire avant chaque redirection")
+	CreateCmd.Flags().Int("redirect-type", 0, "Code de redirection : 301, 302, 307 ou 308 (défaut : links.redirect_status)")
+	CreateCmd.Flags().Bool("forward-query", false, "Transmettre les paramètres de la requête à la destination")
+	CreateCmd.Flags().Bool("forward-path", false, "Transmettre les segments de chemin après le code court (/code/suite)")
+
+	// Paramètres UTM ajoutés à la destination lors de la redirection
+	CreateCmd.Flags().String("utm-source", "", "Paramètre utm_source (ex: newsletter)")
+	CreateCmd.Flags().String("utm-medium", "", "Paramètre utm_medium (ex: email)")
+	CreateCmd.Flags().String("utm-campaign", "", "Paramètre utm_campaign")
+	CreateCmd.Flags().String("utm-term", "", "Paramètre utm_term")
+	CreateCmd.Flags().String("utm-content", "", "Paramètre utm_content")
+
+	// Règles de ciblage, évaluées dans l'ordre des flags
+	CreateCmd.Flags().StringArray("rule", nil, "Règle de ciblage \"os=ios,device=mobile,lang=fr|de,country=FR|BE,time=08:00-18:00,tz=Europe/Paris=>URL\" (répétable)")
+
+	// Test A/B : destinations pondérées
+	CreateCmd.Flags().StringArray("variant", nil, "Variante A/B \"[nom:]poids=>URL\" (répétable, au moins deux)")
+	CreateCmd.Flags().Bool("sticky-variants", false, "Garder chaque visiteur sur la même variante (cookie)")
+
+	// Suivi des conversions
+	CreateCmd.Flags().Bool("track-conversions", false, "Ajouter un jeton de clic à la destination pour suivre les conversions")
+	CreateCmd.Flags().String("goal", "", "Objectif de conversion suivi (ex: inscription) ; active --track-conversions")
+
+	// Aperçu servi aux réseaux sociaux et messageries (balises Open Graph)
+	CreateCmd.Flags().String("og-title", "", "Titre de l'aperçu (défaut : titre relevé sur la destination)")
+	CreateCmd.Flags().String("og-description", "", "Description de l'aperçu")
+	CreateCmd.Flags().String("og-image", "", "URL absolue de l'image de l'aperçu")
+
+	// Protection par mot de passe (s'applique à chaque lien avec --file)
+	CreateCmd.Flags().String("password", "", "Mot de passe exigé avant la redirection")
+	CreateCmd.Flags().Bool("require-signature", false, "N'accepter que les URL signées (voir la commande sign)")
 
-	// Marquer le flag comme requis
-	CreateCmd.MarkFlagRequired("url")
+	// Planification de la surveillance propre au lien
+	CreateCmd.Flags().Bool("no-monitoring", false, "Exclure ce lien du moniteur d'URLs")
+	CreateCmd.Flags().Int("monitor-interval", 0, "Intervalle de vérification en minutes (0 = intervalle global)")
+	CreateCmd.Flags().Int("monitor-priority", 0, "Priorité de vérification (les plus élevées passent en premier)")
 
 	// Ajouter la commande à RootCmd
 	cmd2.RootCmd.AddCommand(CreateCmd)
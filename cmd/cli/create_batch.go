@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+)
+
+// batchRow est une ligne du fichier d'entrée de 'create --file'.
+type batchRow struct {
+	line     int
+	longURL  string
+	alias    string
+	utm      models.UTMParams // Colonnes utm_* ; complétées par les flags --utm-*
+	metadata map[string]string
+	err      error // Ligne invalide, non transmise au service
+}
+
+// runBatchCreate crée les liens listés dans un fichier CSV et écrit un CSV de résultats.
+func runBatchCreate(path, output string, atomic bool, base services.CreateLinkOptions) {
+	rows, err := readBatchFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERREUR : Lecture de %s impossible : %v\n", path, err)
+		os.Exit(1)
+	}
+
+	// Chargement de la configuration globale
+	cfg := cmd2.Cfg
+	if cfg == nil {
+		log.Fatal("FATAL : La configuration n'a pas été chargée correctement.")
+	}
+
+	out := os.Stdout
+	if output != "" {
+		out, err = os.Create(output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERREUR : Impossible de créer %s : %v\n", output, err)
+			os.Exit(1)
+		}
+		defer out.Close()
+	}
+
+	// Résultats par ligne : statut, code court, erreur
+	statuses := make([]string, len(rows))
+	codes := make([]string, len(rows))
+	messages := make([]string, len(rows))
+
+	items := make([]services.BatchItem, 0, len(rows))
+	positions := make([]int, 0, len(rows))
+	invalid := false
+	for i, row := range rows {
+		if row.err != nil {
+			statuses[i], messages[i] = "failed", row.err.Error()
+			invalid = true
+			continue
+		}
+		opts := base
+		opts.Alias = row.alias
+		opts.Metadata = row.metadata
+		opts.UTM = row.utm.WithDefaults(base.UTM)
+		items = append(items, services.BatchItem{LongURL: row.longURL, Options: opts})
+		positions = append(positions, i)
+	}
+
+	if atomic && invalid {
+		for i := range statuses {
+			if statuses[i] == "" {
+				statuses[i], messages[i] = "aborted", services.ErrBatchAborted.Error()
+			}
+		}
+	} else {
+		// Connexion à la base de données via GORM
+		db, err := database.Open(cfg.Database)
+		if err != nil {
+			log.Fatalf("FATAL : Échec de la connexion à la base de données : %v", err)
+		}
+		defer database.Close(db)
+
+		linkService := services.NewLinkService(repository.NewLinkRepository(db))
+		linkService.SetReuseExisting(cfg.Links.ReuseExisting)
+		linkService.SetURLPolicy(services.NewURLPolicy(cfg.Links))
+		applyThreatLists(linkService, cfg)
+		for j, res := range linkService.CreateLinksBatch(items, atomic) {
+			i := positions[j]
+			switch {
+			case res.Err == nil && res.Reused:
+				statuses[i], codes[i] = "reused", res.Link.ShortCode
+			case res.Err == nil:
+				statuses[i], codes[i] = "created", res.Link.ShortCode
+			case errors.Is(res.Err, services.ErrBatchAborted):
+				statuses[i], messages[i] = "aborted", res.Err.Error()
+			default:
+				statuses[i], messages[i] = "failed", res.Err.Error()
+			}
+		}
+	}
+
+	w := csv.NewWriter(out)
+	w.Write([]string{"line", "long_url", "alias", "short_code", "full_short_url", "status", "error"})
+	created := 0
+	for i, row := range rows {
+		fullShortURL := ""
+		if codes[i] != "" {
+			fullShortURL = cfg.Server.BaseURL + "/" + codes[i]
+			created++
+		}
+		w.Write([]string{strconv.Itoa(row.line), row.longURL, row.alias, codes[i], fullShortURL, statuses[i], messages[i]})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalf("FATAL : Échec de l'écriture des résultats : %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d lien(s) créé(s) ou réutilisé(s) sur %d ligne(s).\n", created, len(rows))
+	if created < len(rows) {
+		os.Exit(2)
+	}
+}
+
+// readBatchFile lit le CSV d'entrée. Les colonnes autres que long_url (ou url), alias et
+// utm_* deviennent des métadonnées ; les valeurs vides sont ignorées.
+func readBatchFile(path string) ([]batchRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty file")
+		}
+		return nil, err
+	}
+
+	urlCol, aliasCol := -1, -1
+	utmCols := map[string]int{}
+	extra := map[int]string{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		switch strings.ToLower(name) {
+		case "long_url", "url":
+			urlCol = i
+		case "alias":
+			aliasCol = i
+		case "utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content":
+			utmCols[strings.ToLower(name)] = i
+		default:
+			if name != "" {
+				extra[i] = name
+			}
+		}
+	}
+	if urlCol < 0 {
+		return nil, errors.New(`header must contain a "long_url" or "url" column`)
+	}
+
+	var rows []batchRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+
+		field := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		column := func(name string) string {
+			if i, ok := utmCols[name]; ok {
+				return field(i)
+			}
+			return ""
+		}
+		row := batchRow{line: line, longURL: field(urlCol), alias: field(aliasCol)}
+		row.utm = models.UTMParams{
+			Source:   column("utm_source"),
+			Medium:   column("utm_medium"),
+			Campaign: column("utm_campaign"),
+			Term:     column("utm_term"),
+			Content:  column("utm_content"),
+		}
+		if row.longURL == "" {
+			row.err = errors.New("missing URL")
+		}
+
+		for i, name := range extra {
+			if v := field(i); v != "" {
+				if row.metadata == nil {
+					row.metadata = make(map[string]string, len(extra))
+				}
+				row.metadata[name] = v
+			}
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("no URL to shorten")
+	}
+	return rows, nil
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/transfer"
+	"github.com/spf13/cobra"
+)
+
+// ExportCmd représente la commande 'export'
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exporte les liens et leur historique de clics (CSV, JSON Lines ou archive JSON).",
+	Long: `Cette commande exporte tous les liens, avec leurs réglages et leurs clics, dans l'un des formats :
+  csv      une ligne par lien ou par clic (colonne "type")
+  jsonl    JSON Lines, un enregistrement par ligne
+  archive  document JSON versionné, chaque lien contenant ses clics
+
+La base est parcourue par lots : l'export reste en mémoire constante quel que soit le volume.
+
+Exemples :
+  url-shortener export --format=archive --output=sauvegarde.json
+  url-shortener export --format=csv --no-clicks > liens.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		noClicks, _ := cmd.Flags().GetBool("no-clicks")
+
+		if !cmd.Flags().Changed("format") && output != "" {
+			if detected, err := transfer.DetectFormat(output); err == nil {
+				format = detected
+			}
+		}
+
+		// Chargement de la configuration globale
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatal("FATAL : La configuration n'a pas été chargée correctement.")
+		}
+
+		var out io.Writer = os.Stdout
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERREUR : Impossible de créer %s : %v\n", output, err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			out = file
+		}
+		buffered := bufio.NewWriter(out)
+
+		writer, err := transfer.NewWriter(buffered, format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERREUR : %v\n", err)
+			os.Exit(1)
+		}
+
+		// Connexion à la base de données via GORM
+		db, err := database.Open(cfg.Database)
+		if err != nil {
+			log.Fatalf("FATAL : Échec de la connexion à la base de données : %v", err)
+		}
+		defer database.Close(db)
+
+		exporter := transfer.NewExporter(repository.NewLinkRepository(db), repository.NewClickRepository(db))
+		report, err := exporter.Export(writer, transfer.ExportOptions{IncludeClicks: !noClicks})
+		if err == nil {
+			err = writer.Close()
+		}
+		if err == nil {
+			err = buffered.Flush()
+		}
+		if err != nil {
+			log.Fatalf("FATAL : Échec de l'export : %v", err)
+		}
+
+		// Le résumé va sur la sortie d'erreur pour ne pas polluer un export vers stdout.
+		fmt.Fprintf(os.Stderr, "Export terminé : %d lien(s), %d clic(s).\n", report.Links, report.Clicks)
+	},
+}
+
+func init() {
+	ExportCmd.Flags().String("format", transfer.FormatArchive, "Format d'export : csv, jsonl ou archive (déduit de l'extension de --output si absent)")
+	ExportCmd.Flags().StringP("output", "o", "", "Fichier de sortie (sortie standard par défaut)")
+	ExportCmd.Flags().Bool("no-clicks", false, "Exporter les liens sans leur historique de clics")
+
+	cmd2.RootCmd.AddCommand(ExportCmd)
+}
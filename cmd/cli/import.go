@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/transfer"
+	"github.com/spf13/cobra"
+)
+
+// ImportCmd représente la commande 'import'
+var ImportCmd = &cobra.Command{
+	Use:   "import <fichier>",
+	Short: "Importe des liens et leurs clics depuis un export (CSV, JSON Lines ou archive JSON).",
+	Long: `Cette commande relit un fichier produit par 'export' et crée les liens et clics correspondants.
+Le format est déduit de l'extension (.csv, .jsonl, .json) sauf si --format est fourni.
+
+Quand un code court existe déjà, --on-conflict décide :
+  skip       conserver le lien existant et ignorer celui du fichier (par défaut)
+  overwrite  remplacer le lien existant et ses clics par ceux du fichier
+  rename     importer le lien sous un nouveau code court
+
+Avec --from, le fichier est l'export d'un autre raccourcisseur (bitly, yourls, kutt ou shlink,
+en CSV ou JSON) : les codes courts sont conservés quand ils sont libres et valides, ainsi que
+les dates de création ; le total de clics de chaque lien est repris dans les statistiques.
+
+Avec --dry-run, rien n'est écrit : le rapport indique ce que l'import ferait.
+
+Exemples :
+  url-shortener import sauvegarde.json --dry-run
+  url-shortener import liens.csv --on-conflict=rename
+  url-shortener import --from=shlink short-urls.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+		format, _ := cmd.Flags().GetString("format")
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		from, _ := cmd.Flags().GetString("from")
+
+		if from != "" && format != "" {
+			fmt.Fprintln(os.Stderr, "ERREUR : --format et --from ne peuvent pas être utilisés ensemble.")
+			os.Exit(1)
+		}
+		if format == "" && from == "" {
+			detected, err := transfer.DetectFormat(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERREUR : %v\n", err)
+				os.Exit(1)
+			}
+			format = detected
+		}
+
+		// Chargement de la configuration globale
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatal("FATAL : La configuration n'a pas été chargée correctement.")
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERREUR : Impossible d'ouvrir %s : %v\n", path, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		var reader transfer.Reader
+		if from != "" {
+			reader, err = transfer.NewForeignReader(file, from)
+		} else {
+			reader, err = transfer.NewReader(file, format)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERREUR : %v\n", err)
+			os.Exit(1)
+		}
+
+		// Connexion à la base de données via GORM
+		db, err := database.Open(cfg.Database)
+		if err != nil {
+			log.Fatalf("FATAL : Échec de la connexion à la base de données : %v", err)
+		}
+		defer database.Close(db)
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+		linkService.SetURLPolicy(services.NewURLPolicy(cfg.Links))
+		applyThreatLists(linkService, cfg)
+		importer := transfer.NewImporter(linkRepo, repository.NewClickRepository(db),
+			linkService.GenerateShortCode, linkService.NormalizeLink)
+
+		report, err := importer.Import(reader, transfer.ImportOptions{
+			OnConflict: onConflict,
+			DryRun:     dryRun,
+			Source:     from,
+		})
+		printImportReport(report)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERREUR : Import interrompu : %v\n", err)
+			os.Exit(1)
+		}
+		if report.Failed > 0 {
+			os.Exit(2)
+		}
+	},
+}
+
+// printImportReport affiche le rapport d'import en français.
+func printImportReport(report transfer.ImportReport) {
+	if report.DryRun {
+		fmt.Println("Simulation (--dry-run) : aucune donnée n'a été écrite.")
+	}
+	fmt.Printf("Liens créés      : %d\n", report.LinksCreated)
+	fmt.Printf("Liens ignorés    : %d\n", report.LinksSkipped)
+	fmt.Printf("Liens remplacés  : %d\n", report.LinksOverwritten)
+	fmt.Printf("Liens renommés   : %d\n", report.LinksRenamed)
+	fmt.Printf("Clics importés   : %d\n", report.ClicksImported)
+	fmt.Printf("Clics ignorés    : %d\n", report.ClicksSkipped)
+	fmt.Printf("Erreurs          : %d\n", report.Failed)
+
+	if len(report.Renamed) > 0 {
+		fmt.Println("\nCodes renommés :")
+		originals := make([]string, 0, len(report.Renamed))
+		for original := range report.Renamed {
+			originals = append(originals, original)
+		}
+		sort.Strings(originals)
+		for _, original := range originals {
+			fmt.Printf("  %s -> %s\n", original, report.Renamed[original])
+		}
+	}
+	if len(report.Errors) > 0 {
+		fmt.Println("\nDétail des erreurs :")
+		for _, msg := range report.Errors {
+			fmt.Printf("  - %s\n", msg)
+		}
+		if report.Failed > len(report.Errors) {
+			fmt.Printf("  ... et %d autre(s)\n", report.Failed-len(report.Errors))
+		}
+	}
+}
+
+func init() {
+	ImportCmd.Flags().String("format", "", "Format du fichier : csv, jsonl ou archive (déduit de l'extension si absent)")
+	ImportCmd.Flags().String("on-conflict", transfer.ConflictSkip, "Politique si le code court existe déjà : skip, overwrite ou rename")
+	ImportCmd.Flags().String("from", "", "Importer l'export d'un autre raccourcisseur : "+strings.Join(transfer.ForeignSources(), ", "))
+	ImportCmd.Flags().Bool("dry-run", false, "Simuler l'import sans rien écrire")
+
+	cmd2.RootCmd.AddCommand(ImportCmd)
+}
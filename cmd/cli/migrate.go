@@ -3,55 +3,166 @@ package cli
 import (
 	"fmt"
 	"log"
+	"os"
+	"text/tabwriter"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
-	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/migrations"
 	"github.com/spf13/cobra"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 // MigrateCmd représente la commande 'migrate'
 var MigrateCmd = &cobra.Command{
 	Use:   "migrate",
-	Short: "Exécute les migrations de la base de données pour créer ou mettre à jour les tables.",
-	Long: `Cette commande se connecte à la base de données configurée (SQLite)
-et exécute les migrations automatiques de GORM pour créer les tables 'links' et 'clicks'
-basées sur les modèles Go.`,
+	Short: "Gère les migrations versionnées du schéma de la base de données.",
+	Long: `Cette commande se connecte à la base de données configurée (SQLite, PostgreSQL ou MySQL)
+et applique les migrations versionnées définies dans internal/migrations.
+Les migrations appliquées sont enregistrées dans la table 'schema_migrations'.
+
+Sans sous-commande, 'migrate' équivaut à 'migrate up'.
+
+Exemples :
+  url-shortener migrate up
+  url-shortener migrate down --steps=1
+  url-shortener migrate status
+  url-shortener migrate create add_link_tags`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrateUp()
+	},
+}
+
+// migrateUpCmd applique toutes les migrations en attente.
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Applique toutes les migrations en attente.",
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrateUp()
+	},
+}
+
+// migrateDownCmd annule les dernières migrations appliquées.
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Annule les dernières migrations appliquées (--steps, 1 par défaut).",
 	Run: func(cmd *cobra.Command, args []string) {
-		// Chargement de la configuration globale
-		cfg := cmd2.Cfg
-		if cfg == nil {
-			log.Fatal("FATAL : La configuration n'a pas été chargée correctement.")
+		steps, _ := cmd.Flags().GetInt("steps")
+		if steps <= 0 {
+			fmt.Fprintln(os.Stderr, "ERREUR : --steps doit être supérieur à 0.")
+			os.Exit(1)
 		}
 
-		// Connexion à la base de données SQLite via GORM
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		db := openMigrationDB()
+		defer database.Close(db)
+
+		reverted, err := migrations.Down(db, steps)
+		for _, m := range reverted {
+			fmt.Printf("Annulée : %s_%s\n", m.Version, m.Name)
+		}
 		if err != nil {
-			log.Fatalf("FATAL : Échec de la connexion à la base de données : %v", err)
+			log.Fatalf("FATAL : Échec de l'annulation des migrations : %v", err)
 		}
+		if len(reverted) == 0 {
+			fmt.Println("Aucune migration à annuler.")
+		}
+	},
+}
 
-		sqlDB, err := db.DB()
+// migrateStatusCmd affiche l'état de chaque migration.
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Affiche les migrations appliquées et en attente.",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openMigrationDB()
+		defer database.Close(db)
+
+		statuses, err := migrations.StatusOf(db)
 		if err != nil {
-			log.Fatalf("FATAL : Échec d'accès au driver SQL natif : %v", err)
+			log.Fatalf("FATAL : Échec de la lecture de l'état des migrations : %v", err)
 		}
-		defer func() {
-			if err := sqlDB.Close(); err != nil {
-				log.Printf("WARN : Échec de la fermeture de la connexion DB : %v", err)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNOM\tÉTAT\tAPPLIQUÉE LE")
+		pending := 0
+		for _, s := range statuses {
+			state, appliedAt := "en attente", "-"
+			switch {
+			case s.Unknown:
+				state, appliedAt = "inconnue (binaire plus ancien ?)", s.AppliedAt.Format(time.RFC3339)
+			case s.Applied:
+				state, appliedAt = "appliquée", s.AppliedAt.Format(time.RFC3339)
+			default:
+				pending++
 			}
-		}()
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+		w.Flush()
+		fmt.Printf("\n%d migration(s) en attente.\n", pending)
+	},
+}
+
+// migrateCreateCmd génère le squelette d'une nouvelle migration.
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create <nom>",
+	Short: "Crée le fichier Go d'une nouvelle migration.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
 
-		// Migration automatique des modèles GORM
-		err = db.AutoMigrate(&models.Link{}, &models.Click{})
+		path, err := migrations.Create(dir, args[0], time.Now())
 		if err != nil {
-			log.Fatalf("FATAL : Échec de l'exécution des migrations : %v", err)
+			fmt.Fprintf(os.Stderr, "ERREUR : Échec de la création de la migration : %v\n", err)
+			os.Exit(1)
 		}
 
-		// Succès
-		fmt.Println("Migrations de la base de données exécutées avec succès.")
+		fmt.Printf("Migration créée : %s\n", path)
+		fmt.Println("Complétez Up et Down puis recompilez le binaire pour qu'elle soit prise en compte.")
 	},
 }
 
+// runMigrateUp applique les migrations en attente et affiche le résultat.
+func runMigrateUp() {
+	db := openMigrationDB()
+	defer database.Close(db)
+
+	applied, err := migrations.Up(db)
+	for _, m := range applied {
+		fmt.Printf("Appliquée : %s_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("FATAL : Échec de l'exécution des migrations : %v", err)
+	}
+
+	// Succès
+	if len(applied) == 0 {
+		fmt.Println("Le schéma de la base de données est à jour.")
+		return
+	}
+	fmt.Println("Migrations de la base de données exécutées avec succès.")
+}
+
+// openMigrationDB ouvre la base configurée ou arrête le programme.
+func openMigrationDB() *gorm.DB {
+	// Chargement de la configuration globale
+	cfg := cmd2.Cfg
+	if cfg == nil {
+		log.Fatal("FATAL : La configuration n'a pas été chargée correctement.")
+	}
+
+	// Connexion à la base de données configurée via GORM
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		log.Fatalf("FATAL : Échec de la connexion à la base de données : %v", err)
+	}
+	return db
+}
+
 func init() {
+	migrateDownCmd.Flags().Int("steps", 1, "Nombre de migrations à annuler")
+	migrateCreateCmd.Flags().String("dir", "internal/migrations", "Dossier où créer le fichier de migration")
+
+	MigrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd, migrateCreateCmd)
 	cmd2.RootCmd.AddCommand(MigrateCmd)
 }
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// ModerateCmd représente la commande 'moderate'
+var ModerateCmd = &cobra.Command{
+	Use:   "moderate",
+	Short: "Affiche la file de modération des liens signalés ou applique une décision.",
+	Long: `Sans --code, cette commande liste les liens signalés, les plus signalés d'abord.
+Avec --code et --action, elle applique une décision de modération au lien :
+  dismiss : classer les signalements sans suite (et retirer la page d'avertissement)
+  disable : désactiver le lien
+  delete  : supprimer le lien
+
+Exemples :
+  url-shortener moderate
+  url-shortener moderate --status=all --json
+  url-shortener moderate --code="xyz123" --action=disable --note="hameçonnage confirmé"`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		// Lecture des flags
+		code, _ := cmd.Flags().GetString("code")
+		action, _ := cmd.Flags().GetString("action")
+		note, _ := cmd.Flags().GetString("note")
+		moderator, _ := cmd.Flags().GetString("moderator")
+		status, _ := cmd.Flags().GetString("status")
+		limit, _ := cmd.Flags().GetInt("limit")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		if (code == "") != (action == "") {
+			fmt.Fprintln(os.Stderr, "ERREUR : --code et --action doivent être utilisés ensemble.")
+			os.Exit(1)
+		}
+
+		// Chargement de la configuration globale
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatal("FATAL : La configuration n'a pas été chargée correctement.")
+		}
+
+		// Connexion à la base de données via GORM
+		db, err := database.Open(cfg.Database)
+		if err != nil {
+			log.Fatalf("FATAL : Échec de la connexion à la base de données : %v", err)
+		}
+		defer database.Close(db)
+
+		moderationService := services.NewModerationService(repository.NewLinkRepository(db),
+			repository.NewReportRepository(db), repository.NewAuditRepository(db), cfg.Moderation.ReportThreshold)
+
+		if code != "" {
+			if moderator == "" {
+				moderator = currentUser()
+			}
+			link, err := moderationService.Moderate(code, action, moderator, note)
+			if err != nil {
+				switch {
+				case errors.Is(err, gorm.ErrRecordNotFound):
+					fmt.Fprintf(os.Stderr, "ERREUR : Aucun lien trouvé pour le code court \"%s\".\n", code)
+				case errors.Is(err, services.ErrInvalidReport):
+					fmt.Fprintf(os.Stderr, "ERREUR : %v\n", err)
+				default:
+					log.Fatalf("FATAL : Échec de la modération : %v", err)
+				}
+				os.Exit(1)
+			}
+			switch action {
+			case services.ModerationDismiss:
+				fmt.Printf("Signalements du lien %s classés sans suite ✔️\n", link.ShortCode)
+			case services.ModerationDisable:
+				fmt.Printf("Lien %s désactivé ✔️\n", link.ShortCode)
+			case services.ModerationDelete:
+				fmt.Printf("Lien %s supprimé ✔️\n", link.ShortCode)
+			}
+			return
+		}
+
+		entries, err := moderationService.Queue(status, limit, 3)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidReport) {
+				fmt.Fprintf(os.Stderr, "ERREUR : %v\n", err)
+				os.Exit(1)
+			}
+			log.Fatalf("FATAL : Échec de la lecture de la file de modération : %v", err)
+		}
+
+		if asJSON {
+			type reportJSON struct {
+				Reason     string `json:"reason"`
+				Status     string `json:"status"`
+				ReportedAt string `json:"reported_at"`
+			}
+			type entryJSON struct {
+				ShortCode string       `json:"short_code"`
+				LongURL   string       `json:"long_url"`
+				Reports   int          `json:"reports"`
+				Flagged   bool         `json:"flagged"`
+				Disabled  bool         `json:"disabled"`
+				Latest    []reportJSON `json:"latest"`
+			}
+			out := make([]entryJSON, 0, len(entries))
+			for _, e := range entries {
+				entry := entryJSON{ShortCode: e.Link.ShortCode, LongURL: e.Link.LongURL, Reports: e.Reports,
+					Flagged: e.Link.Flagged, Disabled: e.Link.Disabled, Latest: []reportJSON{}}
+				for _, r := range e.Latest {
+					entry.Latest = append(entry.Latest, reportJSON{Reason: r.Reason, Status: r.Status,
+						ReportedAt: r.CreatedAt.Format("2006-01-02T15:04:05Z07:00")})
+				}
+				out = append(out, entry)
+			}
+			data, _ := json.MarshalIndent(out, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("Aucun lien signalé.")
+			return
+		}
+		for _, e := range entries {
+			state := ""
+			switch {
+			case e.Link.Disabled:
+				state = " [désactivé]"
+			case e.Link.Flagged:
+				state = " [avertissement]"
+			}
+			fmt.Printf("%s (%d signalement(s))%s\n  %s\n", e.Link.ShortCode, e.Reports, state, e.Link.LongURL)
+			for _, r := range e.Latest {
+				fmt.Printf("  - %s : %s\n", r.CreatedAt.Format("2006-01-02 15:04"), r.Reason)
+			}
+		}
+	},
+}
+
+// currentUser retourne le nom de l'utilisateur système, consigné comme modérateur.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "cli"
+}
+
+func init() {
+	ModerateCmd.Flags().String("code", "", "Le code court du lien à modérer")
+	ModerateCmd.Flags().String("action", "", "Décision : dismiss, disable ou delete")
+	ModerateCmd.Flags().String("note", "", "Motif de la décision, consigné dans le journal d'audit")
+	ModerateCmd.Flags().String("moderator", "", "Nom du modérateur (défaut : utilisateur système)")
+	ModerateCmd.Flags().String("status", "open", "Signalements listés : open, dismissed, actioned ou all")
+	ModerateCmd.Flags().Int("limit", 50, "Nombre maximal de liens listés")
+	ModerateCmd.Flags().Bool("json", false, "Afficher la file au format JSON")
+
+	cmd2.RootCmd.AddCommand(ModerateCmd)
+}
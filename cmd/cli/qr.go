@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/qr"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// QRCmd représente la commande 'qr'
+var QRCmd = &cobra.Command{
+	Use:   "qr",
+	Short: "Génère le QR code d'un lien court (PNG ou SVG).",
+	Long: `Cette commande écrit dans un fichier local le QR code d'un lien court. Le QR code encode
+l'URL courte (server.base_url) marquée ?src=qr : les scans sont comptés à part dans les statistiques.
+
+Le format est déduit de l'extension de --output (.png ou .svg) s'il n'est pas précisé.
+
+Exemple :
+  url-shortener qr --code="xyz123"
+  url-shortener qr --code="xyz123" --output=affiche.svg --size=1024 --level=H
+  url-shortener qr --code="xyz123" --fg="#1a237e" --bg=transparent --margin=2`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		// Lecture des flags
+		code, err := cmd.Flags().GetString("code")
+		if err != nil {
+			log.Fatalf("Erreur lors de la lecture du flag --code : %v", err)
+		}
+		if code == "" {
+			fmt.Fprintln(os.Stderr, "ERREUR : le flag --code est requis.")
+			os.Exit(1)
+		}
+		output, _ := cmd.Flags().GetString("output")
+
+		opts := qr.DefaultOptions()
+		opts.Format, _ = cmd.Flags().GetString("format")
+		if opts.Format == "" {
+			opts.Format = qr.FormatPNG
+			if strings.EqualFold(filepath.Ext(output), ".svg") {
+				opts.Format = qr.FormatSVG
+			}
+		}
+		opts.Size, _ = cmd.Flags().GetInt("size")
+		opts.Level, _ = cmd.Flags().GetString("level")
+		opts.Margin, _ = cmd.Flags().GetInt("margin")
+		fg, _ := cmd.Flags().GetString("fg")
+		if opts.Foreground, err = qr.ParseColor(fg); err != nil {
+			fmt.Fprintf(os.Stderr, "ERREUR : --fg : %v\n", err)
+			os.Exit(1)
+		}
+		bg, _ := cmd.Flags().GetString("bg")
+		if opts.Background, err = qr.ParseColor(bg); err != nil {
+			fmt.Fprintf(os.Stderr, "ERREUR : --bg : %v\n", err)
+			os.Exit(1)
+		}
+		if err := opts.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "ERREUR : %v\n", err)
+			os.Exit(1)
+		}
+		if output == "" {
+			output = code + "." + opts.Format
+		}
+
+		// Chargement de la configuration globale
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatal("FATAL : La configuration n'a pas été chargée correctement.")
+		}
+
+		// Connexion à la base de données via GORM
+		db, err := database.Open(cfg.Database)
+		if err != nil {
+			log.Fatalf("FATAL : Échec de la connexion à la base de données : %v", err)
+		}
+		defer database.Close(db)
+
+		linkService := services.NewLinkService(repository.NewLinkRepository(db))
+
+		// Le lien doit exister : un QR code imprimé vers un code inconnu serait perdu.
+		link, err := linkService.GetLinkByShortCode(code)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fmt.Fprintf(os.Stderr, "ERREUR : Aucun lien trouvé pour le code court \"%s\".\n", code)
+				os.Exit(1)
+			}
+			log.Fatalf("FATAL : Échec de la récupération du lien : %v", err)
+		}
+		if link.Disabled {
+			fmt.Fprintf(os.Stderr, "ERREUR : Le lien \"%s\" est désactivé.\n", code)
+			os.Exit(1)
+		}
+
+		content := services.QRContent(cfg.Server.BaseURL, link.ShortCode)
+		data, err := qr.Encode(content, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERREUR : %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(output, data, 0o644); err != nil {
+			log.Fatalf("FATAL : Impossible d'écrire %s : %v", output, err)
+		}
+
+		fmt.Printf("QR code écrit dans %s ✔️\n", output)
+		fmt.Printf("URL encodée : %s\n", content)
+	},
+}
+
+func init() {
+	QRCmd.Flags().String("code", "", "Le code court du lien")
+	QRCmd.Flags().StringP("output", "o", "", "Fichier à écrire (défaut : <code>.<format>)")
+	QRCmd.Flags().String("format", "", "Format : png ou svg (défaut : d'après l'extension de --output, sinon png)")
+	QRCmd.Flags().Int("size", 256, "Côté de l'image en pixels")
+	QRCmd.Flags().String("level", "M", "Correction d'erreur : L, M, Q ou H")
+	QRCmd.Flags().Int("margin", 4, "Marge autour du code, en modules")
+	QRCmd.Flags().String("fg", "#000000", "Couleur des modules (hexadécimale)")
+	QRCmd.Flags().String("bg", "#ffffff", "Couleur du fond (hexadécimale ou transparent)")
+	QRCmd.MarkFlagRequired("code")
+
+	cmd2.RootCmd.AddCommand(QRCmd)
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// SignCmd représente la commande 'sign'
+var SignCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Génère une URL courte signée et limitée dans le temps.",
+	Long: `Cette commande produit une URL courte signée (HMAC) avec la clé courante de signing.keys,
+valable --ttl (défaut : signing.default_ttl_hours). Les liens créés avec --require-signature
+ne redirigent que les URL signées, non modifiées et non expirées.
+
+Les paramètres --param sont ajoutés à l'URL et couverts par la signature : le destinataire
+ne peut ni les modifier ni les retirer.
+
+Exemple :
+  url-shortener sign --code="xyz123"
+  url-shortener sign --code="xyz123" --ttl=2h --param=user=42 --param=lang=fr`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		// Lecture des flags
+		code, err := cmd.Flags().GetString("code")
+		if err != nil {
+			log.Fatalf("Erreur lors de la lecture du flag --code : %v", err)
+		}
+		if code == "" {
+			fmt.Fprintln(os.Stderr, "ERREUR : le flag --code est requis.")
+			os.Exit(1)
+		}
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		if ttl < 0 {
+			fmt.Fprintln(os.Stderr, "ERREUR : --ttl doit être positif.")
+			os.Exit(1)
+		}
+		paramFlags, _ := cmd.Flags().GetStringArray("param")
+		params := make(map[string]string, len(paramFlags))
+		for _, p := range paramFlags {
+			name, value, ok := strings.Cut(p, "=")
+			if !ok || name == "" {
+				fmt.Fprintf(os.Stderr, "ERREUR : --param \"%s\" : format attendu nom=valeur.\n", p)
+				os.Exit(1)
+			}
+			params[name] = value
+		}
+
+		// Chargement de la configuration globale
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatal("FATAL : La configuration n'a pas été chargée correctement.")
+		}
+
+		signer, err := services.NewURLSignerFromConfig(cfg.Signing)
+		if err != nil {
+			log.Fatalf("FATAL : Clés de signature invalides : %v", err)
+		}
+		if signer == nil {
+			fmt.Fprintln(os.Stderr, "ERREUR : aucune clé de signature configurée (signing.keys).")
+			os.Exit(1)
+		}
+
+		// Connexion à la base de données via GORM
+		db, err := database.Open(cfg.Database)
+		if err != nil {
+			log.Fatalf("FATAL : Échec de la connexion à la base de données : %v", err)
+		}
+		defer database.Close(db)
+
+		linkService := services.NewLinkService(repository.NewLinkRepository(db))
+
+		link, err := linkService.GetLinkByShortCode(code)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fmt.Fprintf(os.Stderr, "ERREUR : Aucun lien trouvé pour le code court \"%s\".\n", code)
+				os.Exit(1)
+			}
+			log.Fatalf("FATAL : Échec de la récupération du lien : %v", err)
+		}
+
+		signed, expires, err := signer.Sign(cfg.Server.BaseURL, link.ShortCode, params, ttl, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERREUR : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(signed)
+		fmt.Printf("Valable jusqu'au %s (clé %s)\n", expires.Format("2006-01-02 15:04:05"), signer.ActiveKey())
+		if !link.RequireSignature {
+			fmt.Println("Remarque : ce lien n'exige pas de signature, son URL non signée reste utilisable.")
+		}
+	},
+}
+
+func init() {
+	SignCmd.Flags().String("code", "", "Le code court du lien")
+	SignCmd.Flags().Duration("ttl", 0, "Durée de validité (ex: 30m, 48h ; défaut : signing.default_ttl_hours)")
+	SignCmd.Flags().StringArray("param", nil, "Paramètre \"nom=valeur\" ajouté et couvert par la signature (répétable)")
+	SignCmd.MarkFlagRequired("code")
+
+	cmd2.RootCmd.AddCommand(SignCmd)
+}
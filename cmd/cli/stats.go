@@ -5,13 +5,17 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
 
-	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
 	"gorm.io/gorm"
 )
 
@@ -22,12 +26,14 @@ var shortCodeFlag = flag.String("code", "", "Le code court de l'URL pour laquell
 // StatsCmd représente la commande 'stats'
 var StatsCmd = &cobra.Command{
 	Use:   "stats",
-	Short: "Affiche les statistiques (nombre de clics) pour un lien court.",
+	Short: "Affiche les statistiques (nombre de clics) pour un lien court ou une campagne.",
 	Long: `Cette commande permet de récupérer et d'afficher le nombre total de clics
-pour une URL courte spécifique en utilisant son code.
+pour une URL courte spécifique en utilisant son code, ou pour tous les liens
+d'une campagne (paramètre utm_campaign).
 
 Exemple:
-  url-shortener stats --code="xyz123"`,
+  url-shortener stats --code="xyz123"
+  url-shortener stats --campaign="rentree"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Valider que le flag --code a été fourni.
 
@@ -36,8 +42,10 @@ Exemple:
 			log.Fatalf("Erreur lors de la lecture du flag --code : %v", err)
 		}
 
-		if shortCodeFlag == "" {
-			fmt.Fprintln(os.Stderr, "ERREUR : le flag --code est requis.")
+		campaign, _ := cmd.Flags().GetString("campaign")
+
+		if (shortCodeFlag == "") == (campaign == "") {
+			fmt.Fprintln(os.Stderr, "ERREUR : l'un des flags --code ou --campaign est requis (mais pas les deux).")
 			os.Exit(1)
 		}
 
@@ -48,25 +56,21 @@ Exemple:
 		//Initialiser la connexion à la BDD.
 		// log.Fatalf si erreur
 
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		db, err := database.Open(cfg.Database)
 		if err != nil {
 			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
 		}
-
-		sqlDB, err := db.DB()
-		if err != nil {
-			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
-		}
-
-		defer func() {
-			if err := sqlDB.Close(); err != nil {
-				log.Printf("WARN: Échec de la fermeture de la connexion à la base de données: %v", err)
-			}
-		}()
+		defer database.Close(db)
 
 		// Initialiser les repositories et services nécessaires NewLinkRepository & NewLinkService
 		linkRepo := repository.NewLinkRepository(db)
 		linkService := services.NewLinkService(linkRepo)
+		linkService.SetUTMDefaults(services.NewUTMParams(cfg.UTM))
+
+		if campaign != "" {
+			printCampaignStats(linkService, campaign)
+			return
+		}
 
 		// Appeler GetLinkStats pour récupérer le lien et ses statistiques.
 
@@ -83,17 +87,158 @@ Exemple:
 		fmt.Printf("Statistiques pour le code court: %s\n", link.ShortCode)
 		fmt.Printf("URL longue: %s\n", link.LongURL)
 		fmt.Printf("Total de clics: %d\n", totalClicks)
+		if utm := linkService.EffectiveUTM(link).Pairs(); len(utm) > 0 {
+			fmt.Printf("Paramètres UTM: %s\n", formatUTM(utm))
+		}
+		if len(link.Rules) > 0 {
+			counts, err := linkService.CountClicksByRule(link)
+			if err != nil {
+				log.Fatalf("FATAL: Échec du comptage des clics par règle: %v", err)
+			}
+			fmt.Println("Règles de ciblage:")
+			for _, rule := range link.Rules {
+				fmt.Printf("  #%d %-40s %6d clic(s)  %s\n", rule.Position+1, formatRule(rule), counts[rule.ID], rule.Destination)
+			}
+		}
+		if len(link.Variants) > 0 {
+			stats, err := linkService.VariantStats(link)
+			if err != nil {
+				log.Fatalf("FATAL: Échec du comptage des clics par variante: %v", err)
+			}
+			sticky := ""
+			if link.StickyVariants {
+				sticky = " (attribution persistante)"
+			}
+			fmt.Printf("Test A/B%s:\n", sticky)
+			for _, s := range stats {
+				fmt.Printf("  %-12s %6d clic(s)  %5.1f %% (attendu %5.1f %%)  %s\n",
+					s.Variant.Name, s.Clicks, s.Share, s.TargetShare, s.Variant.Destination)
+			}
+		}
+		if link.TrackConversions {
+			conversionService := services.NewConversionService(repository.NewConversionRepository(db), linkRepo,
+				time.Duration(cfg.Conversions.WindowDays)*24*time.Hour)
+			conversions, err := conversionService.Stats(link)
+			if err != nil {
+				log.Fatalf("FATAL: Échec du calcul des conversions: %v", err)
+			}
+			fmt.Printf("Conversions (%s): %d sur %d clic(s) suivi(s), taux %.1f %%\n",
+				conversions.Goal, conversions.Conversions, conversions.TrackedClicks, conversions.Rate)
+			for _, g := range conversions.Goals {
+				fmt.Printf("  %-20s %6d  %5.1f %%  délai moyen %s, médian %s\n", g.Goal, g.Conversions, g.Rate,
+					time.Duration(g.AvgSecondsToConvert*float64(time.Second)).Round(time.Second),
+					time.Duration(g.MedianSecondsToConvert)*time.Second)
+			}
+		}
+		countries, err := linkService.ClicksByCountry(link)
+		if err != nil {
+			log.Fatalf("FATAL: Échec du comptage des clics par pays: %v", err)
+		}
+		if _, unknown := countries[""]; len(countries) > 1 || (len(countries) == 1 && !unknown) {
+			fmt.Println("Clics par pays:")
+			for _, country := range sortedCountries(countries) {
+				label := country
+				if label == "" {
+					label = "inconnu"
+				}
+				fmt.Printf("  %-8s %6d clic(s)\n", label, countries[country])
+			}
+		}
+		sources, err := linkService.ClicksBySource(link)
+		if err != nil {
+			log.Fatalf("FATAL: Échec du comptage des clics par origine: %v", err)
+		}
+		if qrClicks := sources[models.ClickSourceQR]; qrClicks > 0 {
+			fmt.Printf("Dont scans de QR code: %d\n", qrClicks)
+		}
+		if link.PasswordHash != "" {
+			fmt.Println("Lien protégé par mot de passe")
+		}
+		if link.RequireSignature {
+			fmt.Println("Lien accessible uniquement par URL signée")
+		}
+		if link.Disabled {
+			fmt.Printf("Lien désactivé le %s : %s\n", link.DisabledAt.Format("2006-01-02 15:04"), link.DisabledReason)
+		}
 	},
 }
 
+// printCampaignStats affiche les clics de chaque lien d'une campagne.
+func printCampaignStats(linkService *services.LinkService, campaign string) {
+	stats, total, err := linkService.CampaignStats(campaign)
+	if err != nil {
+		log.Fatalf("FATAL: Échec de la récupération des statistiques de la campagne: %v", err)
+	}
+	if len(stats) == 0 {
+		fmt.Printf("Aucun lien pour la campagne \"%s\".\n", campaign)
+		return
+	}
+
+	fmt.Printf("Statistiques pour la campagne: %s\n", campaign)
+	for _, s := range stats {
+		fmt.Printf("  %-10s %6d clic(s)  %s\n", s.Link.ShortCode, s.Clicks, s.Link.LongURL)
+	}
+	fmt.Printf("Total de clics: %d (%d lien(s))\n", total, len(stats))
+}
+
+// formatUTM affiche des paramètres UTM sous la forme "utm_source=x, utm_medium=y".
+func formatUTM(pairs [][2]string) string {
+	parts := make([]string, 0, len(pairs))
+	for _, kv := range pairs {
+		parts = append(parts, kv[0]+"="+kv[1])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortedCountries trie les pays par nombre de clics décroissant, le pays inconnu en dernier.
+func sortedCountries(counts map[string]int) []string {
+	countries := make([]string, 0, len(counts))
+	for country := range counts {
+		countries = append(countries, country)
+	}
+	sort.Slice(countries, func(i, j int) bool {
+		a, b := countries[i], countries[j]
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return a < b
+	})
+	return countries
+}
+
+// formatRule résume les conditions d'une règle de ciblage (ex: "os=ios, lang=fr|de").
+func formatRule(rule models.LinkRule) string {
+	var parts []string
+	if rule.OS != "" {
+		parts = append(parts, "os="+rule.OS)
+	}
+	if rule.Device != "" {
+		parts = append(parts, "device="+rule.Device)
+	}
+	if rule.Languages != "" {
+		parts = append(parts, "lang="+strings.ReplaceAll(rule.Languages, ",", "|"))
+	}
+	if rule.Countries != "" {
+		parts = append(parts, "country="+strings.ReplaceAll(rule.Countries, ",", "|"))
+	}
+	if rule.TimeFrom != "" {
+		parts = append(parts, "time="+rule.TimeFrom+"-"+rule.TimeTo)
+		if rule.Timezone != "" {
+			parts = append(parts, "tz="+rule.Timezone)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
 // init() s'exécute automatiquement lors de l'importation du package.
 // Il est utilisé pour définir les flags que cette commande accepte.
 func init() {
 	//Définir le flag --code pour la commande stats.
 	StatsCmd.Flags().String("code", "", "Le code court de l'URL pour laquelle récupérer les statistiques")
-
-	// Marquer le flag comme requis
-	StatsCmd.MarkFlagRequired("code")
+	StatsCmd.Flags().String("campaign", "", "Afficher les statistiques de tous les liens d'une campagne (utm_campaign)")
 
 	// Ajouter la commande à RootCmd
 	cmd2.RootCmd.AddCommand(StatsCmd)
@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"log"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/threats"
+)
+
+// applyThreatLists charge les listes de menaces configurées dans le service de liens,
+// pour que les commandes de création refusent les mêmes destinations que l'API.
+func applyThreatLists(linkService *services.LinkService, cfg *config.Config) {
+	screener, err := threats.NewScreenerFromConfig(cfg.Threats)
+	if err != nil {
+		log.Fatalf("FATAL : Échec du chargement des listes de menaces : %v", err)
+	}
+	linkService.SetThreatScreener(screener)
+}
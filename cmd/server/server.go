@@ -11,15 +11,17 @@ import (
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
 	"github.com/axellelanca/urlshortener/internal/api"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/geoip"
+	"github.com/axellelanca/urlshortener/internal/migrations"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/monitor"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/threats"
 	"github.com/axellelanca/urlshortener/internal/workers"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
-	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
-	"gorm.io/gorm"
 )
 
 // RunServerCmd représente la commande 'run-server' de Cobra.
@@ -40,20 +42,39 @@ puis lance le serveur HTTP.`,
 		}
 
 		//  : Initialiser la connexion à la bBDD
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		db, err := database.Open(cfg.Database)
 		if err != nil {
 			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
 		}
+		defer database.Close(db)
 
-		sqlDB, err := db.DB()
+		// Refuser de démarrer sur un schéma en retard : le code suppose que toutes
+		// les migrations connues de ce binaire sont appliquées.
+		pending, err := migrations.Pending(db)
 		if err != nil {
-			log.Fatalf("FATAL: Échec de l'obtention de l'instance SQL DB: %v", err)
+			log.Fatalf("FATAL: Échec de la vérification des migrations: %v", err)
+		}
+		if len(pending) > 0 {
+			log.Fatalf("FATAL: Le schéma de la base de données est en retard de %d migration(s) (prochaine : %s_%s). Lancez 'url-shortener migrate up'.",
+				len(pending), pending[0].Version, pending[0].Name)
 		}
-		defer sqlDB.Close()
 
 		//  : Initialiser le routeur Gin
 		router := gin.Default()
 
+		// Adresse du client : les en-têtes X-Forwarded-For ne sont crus que venant des proxys déclarés,
+		// sinon n'importe qui pourrait changer d'identité (signalements, essais de mot de passe).
+		if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			log.Fatalf("FATAL: server.trusted_proxies invalide : %v", err)
+		}
+
+		// Pages HTML (aperçu, page intermédiaire, destination indisponible, ...)
+		pages, err := api.LoadPageTemplates(cfg.Server.TemplatesDir)
+		if err != nil {
+			log.Fatalf("FATAL: Impossible de charger les templates HTML : %v", err)
+		}
+		router.SetHTMLTemplate(pages)
+
 		//  : Initialiser les repositories.
 		// Créez des instances de GormLinkRepository et GormClickRepository.
 		log.Println("Initialisation des repositories...")
@@ -65,11 +86,56 @@ puis lance le serveur HTTP.`,
 
 		clickRepo := repository.NewClickRepository(db)
 
-		linkRepo := repository.NewLinkRepository(db)
+		var linkRepo repository.LinkRepository = repository.NewLinkRepository(db)
+		linkCheckRepo := repository.NewLinkCheckRepository(db)
+
+		// Cache LRU devant les lectures de liens (redirections), invalidé à chaque écriture.
+		var linkCache *repository.CachedLinkRepository
+		if cfg.Cache.Enabled {
+			linkCache = repository.NewCachedLinkRepository(linkRepo, cfg.Cache.MaxEntries,
+				time.Duration(cfg.Cache.TTLSeconds)*time.Second, time.Duration(cfg.Cache.NegativeTTLSeconds)*time.Second)
+			linkRepo = linkCache
+			log.Printf("Cache des liens activé (%d entrées max).", cfg.Cache.MaxEntries)
+		}
 		log.Println("Repositories initialisés.")
 
 		// Créez le service de liens
 		linkService := services.NewLinkService(linkRepo)
+		linkService.SetReuseExisting(cfg.Links.ReuseExisting)
+		linkService.SetURLPolicy(services.NewURLPolicy(cfg.Links))
+		redirectPolicy, err := services.NewRedirectPolicy(cfg.Links)
+		if err != nil {
+			log.Fatalf("FATAL: Configuration de redirection invalide : %v", err)
+		}
+		linkService.SetRedirectPolicy(redirectPolicy)
+		linkService.SetUTMDefaults(services.NewUTMParams(cfg.UTM))
+		linkService.SetClickTokenParam(cfg.Conversions.TokenParam)
+
+		// Base GeoIP locale : pays et ville des clics, règles de ciblage par pays.
+		var locator *geoip.Locator
+		if cfg.GeoIP.Database != "" {
+			locator, err = geoip.Open(cfg.GeoIP.Database)
+			if err != nil {
+				log.Fatalf("FATAL: Échec du chargement de la base GeoIP: %v", err)
+			}
+			defer locator.Close()
+			linkService.SetGeoLocator(locator)
+			log.Printf("Base GeoIP chargée (%s).", locator.DatabaseType())
+		}
+
+		// Listes de menaces locales : rechargées quand les fichiers changent ou sur SIGHUP.
+		screener, err := threats.NewScreenerFromConfig(cfg.Threats)
+		if err != nil {
+			log.Fatalf("FATAL: Échec du chargement des listes de menaces: %v", err)
+		}
+		if screener != nil {
+			linkService.SetThreatScreener(screener)
+			log.Printf("Listes de menaces chargées (%d règle(s)).", screener.Size())
+			if cfg.Threats.ReloadIntervalSeconds > 0 {
+				go screener.Watch(time.Duration(cfg.Threats.ReloadIntervalSeconds) * time.Second)
+			}
+			go reloadThreatsOnSignal(screener)
+		}
 
 		// Laissez le log
 		log.Println("Services métiers initialisés.")
@@ -82,7 +148,7 @@ puis lance le serveur HTTP.`,
 		api.ClickEventsChannel = make(chan models.ClickEvent, bufferSize)
 
 		numWorkers := cfg.Analytics.WorkerCount
-		workers.StartClickWorkers(numWorkers, api.ClickEventsChannel, clickRepo)
+		workers.StartClickWorkers(numWorkers, api.ClickEventsChannel, clickRepo, locator)
 
 		//  : Remplacer les XXX par les bonnes variables
 		log.Printf("Channel d'événements de clic initialisé avec un buffer de %d. %d worker(s) de clics démarré(s).",
@@ -91,7 +157,10 @@ puis lance le serveur HTTP.`,
 		//  : Initialiser et lancer le moniteur d'URLs.
 		// Utilisez l'intervalle configuré
 		monitorInterval := time.Duration(cfg.Monitor.IntervalMinutes) * time.Minute
-		urlMonitor := monitor.NewUrlMonitor(linkRepo, monitorInterval) // Le moniteur a besoin du linkRepo et de l'interval
+		urlMonitor := monitor.NewUrlMonitorFromConfig(linkRepo, linkCheckRepo, cfg.Monitor) // Le moniteur a besoin du linkRepo et de l'interval
+		if screener != nil {
+			urlMonitor.SetThreatScreener(screener, repository.NewAuditRepository(db))
+		}
 
 		//  Lancez le moniteur dans sa propre goroutine.
 
@@ -102,7 +171,46 @@ puis lance le serveur HTTP.`,
 		//  : Configurer le routeur Gin et les handlers API.
 		// Passez les services nécessaires aux fonctions de configuration des routes.
 
-		api.SetupRoutes(router, linkService)
+		// Rejeu des créations portant un en-tête Idempotency-Key, avec purge horaire des clés expirées.
+		idempotencyRepo := repository.NewIdempotencyRepository(db)
+		idempotencyWindow := time.Duration(cfg.Server.IdempotencyWindowMinutes) * time.Minute
+		if idempotencyWindow > 0 {
+			go purgeIdempotencyKeys(idempotencyRepo, time.Hour)
+		}
+
+		// Signalements d'abus et file de modération (routes protégées par server.admin_token).
+		moderationService := services.NewModerationService(linkRepo, repository.NewReportRepository(db),
+			repository.NewAuditRepository(db), cfg.Moderation.ReportThreshold)
+		if cfg.Server.AdminToken == "" {
+			log.Println("WARN: server.admin_token n'est pas défini : les routes d'administration sont désactivées.")
+		}
+
+		// Conversions signalées par les sites de destination (jeton de clic).
+		conversionService := services.NewConversionService(repository.NewConversionRepository(db), linkRepo,
+			time.Duration(cfg.Conversions.WindowDays)*24*time.Hour)
+
+		// Liens protégés par mot de passe : cookies de déverrouillage signés, essais limités par IP.
+		passwordGuard, err := services.NewPasswordGuardFromConfig(cfg.Passwords)
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'initialisation des liens protégés: %v", err)
+		}
+		if cfg.Passwords.CookieSecret == "" {
+			log.Println("WARN: passwords.cookie_secret n'est pas défini : les liens déverrouillés devront l'être de nouveau après un redémarrage.")
+		}
+
+		// URL signées et limitées dans le temps (liens exigeant une signature).
+		urlSigner, err := services.NewURLSignerFromConfig(cfg.Signing)
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'initialisation des clés de signature: %v", err)
+		}
+		if urlSigner == nil {
+			log.Println("WARN: signing.keys est vide : les liens qui exigent une URL signée refuseront toutes les visites.")
+		}
+
+		api.SetupRoutes(router, cfg, linkService, urlMonitor, linkCache,
+			api.IdempotencyMiddleware(idempotencyRepo, idempotencyWindow),
+			moderationService, api.AdminAuthMiddleware(cfg.Server.AdminToken), conversionService, passwordGuard,
+			urlSigner)
 
 		// Pas toucher au log
 		log.Println("Routes API configurées.")
@@ -141,6 +249,32 @@ puis lance le serveur HTTP.`,
 	},
 }
 
+// purgeIdempotencyKeys supprime périodiquement les clés d'idempotence expirées.
+func purgeIdempotencyKeys(repo repository.IdempotencyRepository, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for range ticker.C {
+		if n, err := repo.DeleteExpired(time.Now()); err != nil {
+			log.Printf("WARN: Échec de la purge des clés d'idempotence : %v", err)
+		} else if n > 0 {
+			log.Printf("%d clé(s) d'idempotence expirée(s) supprimée(s).", n)
+		}
+	}
+}
+
+// reloadThreatsOnSignal recharge les listes de menaces à chaque SIGHUP.
+func reloadThreatsOnSignal(screener *threats.Screener) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := screener.Reload(); err != nil {
+			log.Printf("WARN: Échec du rechargement des listes de menaces, règles précédentes conservées : %v", err)
+			continue
+		}
+		log.Printf("Listes de menaces rechargées (%d règle(s)).", screener.Size())
+	}
+}
+
 func init() {
 	//  : ajouter la commande
 	cmd2.RootCmd.AddCommand(RunServerCmd)
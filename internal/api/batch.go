@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Modes de création par lot.
+const (
+	BatchModeAtomic  = "atomic"  // Tous les liens sont créés, ou aucun
+	BatchModePartial = "partial" // Chaque lien est créé indépendamment
+)
+
+// CreateLinksBatchRequest est le corps de POST /api/v1/links/batch.
+type CreateLinksBatchRequest struct {
+	Mode  string              `json:"mode" binding:"omitempty,oneof=atomic partial"`
+	Items []CreateLinkRequest `json:"items" binding:"required,min=1"`
+}
+
+// BatchItemResult est le résultat d'un élément du lot, dans l'ordre de la requête.
+type BatchItemResult struct {
+	Index        int               `json:"index"`
+	Status       string            `json:"status"` // created, reused, failed ou aborted
+	ShortCode    string            `json:"short_code,omitempty"`
+	LongURL      string            `json:"long_url"`
+	FullShortURL string            `json:"full_short_url,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Code         string            `json:"code,omitempty"` // Code de l'erreur de validation ou de filtrage d'URL
+}
+
+// Handler création de liens par lot
+func CreateLinksBatchHandler(linkService *services.LinkService, baseURL string, maxItems int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateLinksBatchRequest
+
+		// Validation JSON de l'enveloppe ; les éléments sont validés un par un.
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(req.Items) > maxItems {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": fmt.Sprintf("too many items: %d (maximum %d)", len(req.Items), maxItems),
+			})
+			return
+		}
+		atomic := req.Mode != BatchModePartial
+
+		results := make([]BatchItemResult, len(req.Items))
+		items := make([]services.BatchItem, 0, len(req.Items))
+		positions := make([]int, 0, len(req.Items)) // Index dans la requête de chaque élément transmis au service
+		invalid := false
+		for i, item := range req.Items {
+			results[i] = BatchItemResult{Index: i, LongURL: item.LongURL}
+			if err := binding.Validator.ValidateStruct(&item); err != nil {
+				results[i].Status, results[i].Error = "failed", err.Error()
+				invalid = true
+				continue
+			}
+			items = append(items, services.BatchItem{LongURL: item.LongURL, Options: item.toOptions()})
+			positions = append(positions, i)
+		}
+
+		if atomic && invalid {
+			// Rien n'est créé : les éléments valides sont signalés comme annulés.
+			for i := range results {
+				if results[i].Status == "" {
+					results[i].Status, results[i].Error = "aborted", services.ErrBatchAborted.Error()
+				}
+			}
+			c.JSON(http.StatusUnprocessableEntity, batchResponse(req.Mode, results))
+			return
+		}
+
+		for j, res := range linkService.CreateLinksBatch(items, atomic) {
+			r := &results[positions[j]]
+			var urlErr *services.URLError
+			switch {
+			case res.Err == nil:
+				r.Status = "created"
+				if res.Reused {
+					r.Status = "reused"
+				}
+				r.ShortCode = res.Link.ShortCode
+				r.FullShortURL = baseURL + "/" + res.Link.ShortCode
+				r.Metadata = res.Link.Metadata
+			case errors.Is(res.Err, services.ErrBatchAborted):
+				r.Status, r.Error = "aborted", res.Err.Error()
+			case errors.As(res.Err, &urlErr):
+				r.Status, r.Error, r.Code = "failed", urlErr.Error(), urlErr.Code
+			case errors.Is(res.Err, services.ErrDestinationBlocked):
+				r.Status, r.Error, r.Code = "failed", res.Err.Error(), services.URLErrBlocked
+			case errors.Is(res.Err, services.ErrInvalidLinkOptions), errors.Is(res.Err, services.ErrAliasTaken):
+				r.Status, r.Error = "failed", res.Err.Error()
+			default:
+				log.Printf("Error creating short link for %s in batch: %v", r.LongURL, res.Err)
+				r.Status, r.Error = "failed", "Internal server error"
+			}
+		}
+
+		resp := batchResponse(req.Mode, results)
+		switch succeeded := resp["created"].(int) + resp["reused"].(int); {
+		case succeeded == len(results):
+			c.JSON(http.StatusCreated, resp)
+		case succeeded > 0:
+			c.JSON(http.StatusMultiStatus, resp)
+		default:
+			c.JSON(http.StatusUnprocessableEntity, resp)
+		}
+	}
+}
+
+// batchResponse construit la réponse d'un lot avec ses compteurs.
+func batchResponse(mode string, results []BatchItemResult) gin.H {
+	if mode == "" {
+		mode = BatchModeAtomic
+	}
+	created, reused := 0, 0
+	for _, r := range results {
+		switch r.Status {
+		case "created":
+			created++
+		case "reused":
+			reused++
+		}
+	}
+	return gin.H{
+		"mode":    mode,
+		"created": created,
+		"reused":  reused,
+		"failed":  len(results) - created - reused,
+		"results": results,
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// trackingPixel est un GIF transparent de 1x1 pixel.
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// ConversionRequest est le corps de POST /api/v1/conversions.
+type ConversionRequest struct {
+	Token string `json:"token" binding:"required"` // Jeton reçu par la destination (paramètre conversions.token_param)
+	Goal  string `json:"goal"`                     // Objectif atteint, celui du lien par défaut
+}
+
+// conversionStatus traduit une erreur d'enregistrement de conversion en code HTTP.
+func conversionStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidConversion):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrUnknownClickToken):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrConversionExpired):
+		return http.StatusGone
+	}
+	return http.StatusInternalServerError
+}
+
+// Handler signalement d'une conversion par le site de destination
+func RecordConversionHandler(conversionService *services.ConversionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ConversionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		conversion, created, err := conversionService.RecordConversion(req.Token, req.Goal, time.Now())
+		if err != nil {
+			status := conversionStatus(err)
+			if status == http.StatusInternalServerError {
+				log.Printf("Error recording conversion: %v", err)
+				c.JSON(status, gin.H{"error": "Internal server error"})
+				return
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		status := http.StatusCreated
+		if !created {
+			status = http.StatusOK // Conversion déjà enregistrée pour ce clic et cet objectif
+		}
+		c.JSON(status, conversionSummary(conversion, created))
+	}
+}
+
+// Handler pixel de conversion : GET /api/v1/conversions/pixel.gif?token=...&goal=...
+// Le GIF est toujours renvoyé (une image cassée n'aide personne) ; le code HTTP porte le résultat.
+func ConversionPixelHandler(conversionService *services.ConversionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := http.StatusOK
+		if _, _, err := conversionService.RecordConversion(c.Query("token"), c.Query("goal"), time.Now()); err != nil {
+			status = conversionStatus(err)
+			if status == http.StatusInternalServerError {
+				log.Printf("Error recording conversion: %v", err)
+			}
+		}
+		c.Header("Cache-Control", "no-store")
+		c.Data(status, "image/gif", trackingPixel)
+	}
+}
+
+func conversionSummary(conversion *models.Conversion, created bool) gin.H {
+	return gin.H{
+		"id":                 conversion.ID,
+		"goal":               conversion.Goal,
+		"clicked_at":         conversion.ClickedAt,
+		"converted_at":       conversion.CreatedAt,
+		"seconds_to_convert": conversion.SecondsToConvert,
+		"created":            created,
+	}
+}
+
+// conversionStatsSummary présente les taux de conversion d'un lien.
+func conversionStatsSummary(stats *services.ConversionStats) gin.H {
+	goals := make([]gin.H, 0, len(stats.Goals))
+	for _, g := range stats.Goals {
+		goals = append(goals, goalSummary(g))
+	}
+	summary := goalSummary(stats.GoalConversions)
+	summary["tracked_clicks"] = stats.TrackedClicks
+	summary["goals"] = goals
+	return summary
+}
+
+func goalSummary(g services.GoalConversions) gin.H {
+	return gin.H{
+		"goal":                      g.Goal,
+		"conversions":               g.Conversions,
+		"rate":                      g.Rate,
+		"avg_seconds_to_convert":    g.AvgSecondsToConvert,
+		"median_seconds_to_convert": g.MedianSecondsToConvert,
+	}
+}
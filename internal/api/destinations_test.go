@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/database/dbtest"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/monitor"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// hiddenMarker figure dans toutes les destinations (et l'aperçu relevé) des liens de test.
+const hiddenMarker = "hidden-target"
+
+// publicRoutes monte les routes publiques qui décrivent un lien.
+func publicRoutes(linkService *services.LinkService, urlMonitor *monitor.UrlMonitor) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/links/:shortCode/stats", GetLinkStatsHandler(linkService, nil))
+	router.GET("/links/:shortCode/health", GetLinkHealthHandler(linkService, urlMonitor))
+	router.GET("/links/:shortCode/rules", GetRulesHandler(linkService))
+	router.GET("/links/:shortCode/variants", GetVariantsHandler(linkService))
+	router.GET("/links/:shortCode/preview", GetPreviewHandler(linkService))
+	router.GET("/campaigns/:campaign/stats", GetCampaignStatsHandler(linkService))
+	return router
+}
+
+// createTestLink crée un lien dont toutes les destinations portent hiddenMarker, avec un
+// aperçu relevé et un état de santé (erreur citant l'URL) connus.
+func createTestLink(t *testing.T, linkService *services.LinkService, linkRepo repository.LinkRepository,
+	urlMonitor *monitor.UrlMonitor, campaign string, opts services.CreateLinkOptions) *models.Link {
+	t.Helper()
+	opts.FailurePolicy = models.FailurePolicyFallback
+	opts.FallbackURL = "https://example.com/" + hiddenMarker + "/fallback"
+	opts.UTM = models.UTMParams{Campaign: campaign}
+	opts.Rules = []models.LinkRule{{Countries: "FR", Destination: "https://example.com/" + hiddenMarker + "/fr"}}
+	opts.Variants = []models.LinkVariant{
+		{Name: "A", Weight: 1, Destination: "https://example.com/" + hiddenMarker + "/a"},
+		{Name: "B", Weight: 1, Destination: "https://example.com/" + hiddenMarker + "/b"},
+	}
+	// Destination en boucle locale : le moniteur la refuse sans requête, avec une erreur qui cite l'URL.
+	link, err := linkService.CreateLinkWithOptions("http://127.0.0.1:9/"+hiddenMarker, opts)
+	if err != nil {
+		t.Fatalf("CreateLinkWithOptions: %v", err)
+	}
+	fetchedAt := time.Now()
+	link.FetchedPreview = models.LinkPreview{Title: hiddenMarker + " page", Description: "about " + hiddenMarker}
+	link.PreviewFetchedAt = &fetchedAt
+	if err := linkRepo.SaveFetchedPreview(link); err != nil {
+		t.Fatalf("SaveFetchedPreview: %v", err)
+	}
+	if health := urlMonitor.CheckNow(link); !strings.Contains(health.LastError, hiddenMarker) {
+		t.Fatalf("test setup: health error %q does not cite the destination", health.LastError)
+	}
+	return link
+}
+
+func TestPublicRoutesHideDestinationOfProtectedLinks(t *testing.T) {
+	// Liens protégés par mot de passe ou à URL signées : leur destination ne doit pas être lisible
+	// sans le mot de passe ou une signature valide.
+	db := dbtest.Migrated(t, dbtest.Targets(t)[0])
+	linkRepo := repository.NewLinkRepository(db)
+	linkService := services.NewLinkService(linkRepo)
+	urlMonitor := monitor.NewUrlMonitor(linkRepo, nil, time.Minute, monitor.Options{})
+	router := publicRoutes(linkService, urlMonitor)
+
+	tests := []struct {
+		name   string
+		opts   services.CreateLinkOptions
+		hidden bool
+	}{
+		{"public", services.CreateLinkOptions{}, false},
+		{"password", services.CreateLinkOptions{Password: "s3cret-pass"}, true},
+		{"signature", services.CreateLinkOptions{RequireSignature: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaign := "campaign-" + tt.name
+			link := createTestLink(t, linkService, linkRepo, urlMonitor, campaign, tt.opts)
+
+			for _, path := range []string{
+				"/links/" + link.ShortCode + "/stats",
+				"/links/" + link.ShortCode + "/health",
+				"/links/" + link.ShortCode + "/rules",
+				"/links/" + link.ShortCode + "/variants",
+				"/links/" + link.ShortCode + "/preview",
+				"/campaigns/" + campaign + "/stats",
+			} {
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("GET %s: status %d, body %s", path, rec.Code, rec.Body)
+				}
+				if exposed := strings.Contains(rec.Body.String(), hiddenMarker); exposed != !tt.hidden {
+					t.Errorf("GET %s: destination exposed = %v, want %v; body %s", path, exposed, !tt.hidden, rec.Body)
+				}
+			}
+		})
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/axellelanca/urlshortener/internal/config"
 	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/monitor"
+	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -19,7 +22,11 @@ var ClickEventsChannel chan models.ClickEvent
 // ----------------------------
 // ROUTES
 // ----------------------------
-func SetupRoutes(router *gin.Engine, linkService *services.LinkService) {
+func SetupRoutes(router *gin.Engine, cfg *config.Config, linkService *services.LinkService, urlMonitor *monitor.UrlMonitor,
+	linkCache *repository.CachedLinkRepository, idempotency gin.HandlerFunc,
+	moderationService *services.ModerationService, adminAuth gin.HandlerFunc,
+	conversionService *services.ConversionService, passwordGuard *services.PasswordGuard,
+	urlSigner *services.URLSigner) {
 
 	// Health check
 	router.GET("/health", HealthCheckHandler)
@@ -27,12 +34,42 @@ func SetupRoutes(router *gin.Engine, linkService *services.LinkService) {
 	// Routes API versionnées
 	api := router.Group("/api/v1")
 	{
-		api.POST("/links", CreateShortLinkHandler(linkService))
-		api.GET("/links/:shortCode/stats", GetLinkStatsHandler(linkService))
+		api.POST("/links", idempotency, CreateShortLinkHandler(linkService, cfg.Server.BaseURL))
+		api.POST("/links/batch", idempotency, CreateLinksBatchHandler(linkService, cfg.Server.BaseURL, cfg.Server.BatchMaxItems))
+		api.DELETE("/links/:shortCode", adminAuth, DeleteLinkHandler(linkService))
+		api.GET("/links/:shortCode/stats", GetLinkStatsHandler(linkService, conversionService))
+		api.GET("/links/:shortCode/health", GetLinkHealthHandler(linkService, urlMonitor))
+		api.POST("/links/:shortCode/check", adminAuth, CheckLinkHandler(linkService, urlMonitor))
+		api.PATCH("/links/:shortCode/monitoring", adminAuth, UpdateMonitoringHandler(linkService))
+		api.POST("/links/:shortCode/report", ReportLinkHandler(moderationService))
+		api.GET("/links/:shortCode/rules", GetRulesHandler(linkService))
+		api.PUT("/links/:shortCode/rules", adminAuth, SetRulesHandler(linkService))
+		api.GET("/links/:shortCode/qr", GetQRCodeHandler(linkService, cfg.Server.BaseURL))
+		api.GET("/links/:shortCode/preview", GetPreviewHandler(linkService))
+		api.PUT("/links/:shortCode/preview", adminAuth, SetPreviewHandler(linkService))
+		api.PUT("/links/:shortCode/password", adminAuth, SetPasswordHandler(linkService))
+		api.POST("/links/:shortCode/sign", adminAuth, SignLinkHandler(linkService, urlSigner, cfg.Server.BaseURL))
+		api.PUT("/links/:shortCode/signature", adminAuth, SetSignatureHandler(linkService))
+		api.GET("/links/:shortCode/variants", GetVariantsHandler(linkService))
+		api.PUT("/links/:shortCode/variants", adminAuth, SetVariantsHandler(linkService))
+		api.GET("/campaigns/:campaign/stats", GetCampaignStatsHandler(linkService))
+		api.POST("/conversions", RecordConversionHandler(conversionService))
+		api.GET("/conversions/pixel.gif", ConversionPixelHandler(conversionService))
+
+		api.GET("/admin/cache", adminAuth, CacheStatsHandler(linkCache))
+		api.GET("/admin/reports", adminAuth, ListReportsHandler(moderationService))
+		api.POST("/admin/reports/:shortCode", adminAuth, ModerateLinkHandler(moderationService))
 	}
 
 	// Redirection short URL
-	router.GET("/:shortCode", RedirectHandler(linkService))
+	redirect := RedirectHandler(linkService, urlMonitor, passwordGuard, urlSigner, cfg.Server.BaseURL)
+	router.GET("/:shortCode", redirect)
+	router.GET("/:shortCode/*path", redirect) // Liens qui transmettent les segments de chemin supplémentaires, et /:shortCode/qr
+
+	// Formulaire de déverrouillage des liens protégés par mot de passe
+	unlock := UnlockHandler(linkService, passwordGuard, urlSigner)
+	router.POST("/:shortCode", unlock)
+	router.POST("/:shortCode/*path", unlock)
 }
 
 // Healthcheck simple
@@ -42,14 +79,109 @@ func HealthCheckHandler(c *gin.Context) {
 
 // DTO
 type CreateLinkRequest struct {
-	LongURL string `json:"long_url" binding:"required,url"`
+	LongURL          string `json:"long_url" binding:"required"` // Validée par le service (schémas, longueur, ...)
+	FailurePolicy    string `json:"failure_policy" binding:"omitempty,oneof=keep unavailable fallback"`
+	FailureThreshold int    `json:"failure_threshold" binding:"omitempty,min=1"`
+	FallbackURL      string `json:"fallback_url"`
+
+	ContentMonitoring bool `json:"content_monitoring"`
+	Interstitial      bool `json:"interstitial"` // Page intermédiaire avant chaque redirection
+
+	RedirectType int  `json:"redirect_type" binding:"omitempty,oneof=301 302 307 308"`
+	ForwardQuery bool `json:"forward_query"` // Transmettre les paramètres de la requête à la destination
+	ForwardPath  bool `json:"forward_path"`  // Transmettre les segments après le code court (/code/suite)
+
+	// Paramètres UTM ajoutés à la destination lors de la redirection
+	UTMSource   string `json:"utm_source"`
+	UTMMedium   string `json:"utm_medium"`
+	UTMCampaign string `json:"utm_campaign"`
+	UTMTerm     string `json:"utm_term"`
+	UTMContent  string `json:"utm_content"`
+
+	// Règles de ciblage évaluées dans l'ordre ; la première qui correspond remplace la destination
+	Rules []RuleRequest `json:"rules" binding:"omitempty,dive"`
+
+	// Test A/B : destinations pondérées, utilisées quand aucune règle ne correspond
+	Variants       []VariantRequest `json:"variants" binding:"omitempty,dive"`
+	StickyVariants bool             `json:"sticky_variants"` // Garder chaque visiteur sur sa variante (cookie)
+
+	// Suivi des conversions : jeton de clic ajouté à la destination ; nommer un objectif active le suivi
+	TrackConversions bool   `json:"track_conversions"`
+	ConversionGoal   string `json:"conversion_goal"`
+
+	MonitoringSettingsRequest
+
+	// Aperçu Open Graph servi aux réseaux sociaux et messageries
+	PreviewRequest
+
+	Password         string `json:"password"`          // Mot de passe exigé avant la redirection (enregistré haché)
+	RequireSignature bool   `json:"require_signature"` // N'accepter que les URL signées (POST /links/:shortCode/sign)
+
+	Alias    string            `json:"alias"`    // Code court souhaité (facultatif)
+	Metadata map[string]string `json:"metadata"` // Métadonnées libres (campagne, canal, ...)
+
+	ReuseExisting *bool `json:"reuse_existing"` // Réutiliser le lien existant vers la même destination
 }
 
-// Handler création d'un lien court
-func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+func (r CreateLinkRequest) toOptions() services.CreateLinkOptions {
+	return services.CreateLinkOptions{
+		FailurePolicy:    r.FailurePolicy,
+		FailureThreshold: r.FailureThreshold,
+		FallbackURL:      r.FallbackURL,
+
+		ContentMonitoring: r.ContentMonitoring,
+		Interstitial:      r.Interstitial,
+		Monitoring:        r.toSettings(),
+
+		RedirectType: r.RedirectType,
+		ForwardQuery: r.ForwardQuery,
+		ForwardPath:  r.ForwardPath,
 
-	cfg, _ := config.LoadConfig()
+		UTM: models.UTMParams{
+			Source:   r.UTMSource,
+			Medium:   r.UTMMedium,
+			Campaign: r.UTMCampaign,
+			Term:     r.UTMTerm,
+			Content:  r.UTMContent,
+		},
+		Rules: toRuleModels(r.Rules),
 
+		Variants:       toVariantModels(r.Variants),
+		StickyVariants: r.StickyVariants,
+
+		TrackConversions: r.TrackConversions,
+		ConversionGoal:   r.ConversionGoal,
+
+		Preview:  r.PreviewRequest.toModel(),
+		Password: r.Password,
+
+		RequireSignature: r.RequireSignature,
+
+		Alias:    r.Alias,
+		Metadata: r.Metadata,
+
+		ReuseExisting: r.ReuseExisting,
+	}
+}
+
+// MonitoringSettingsRequest regroupe les réglages de surveillance d'un lien.
+// Les champs absents ne sont pas modifiés.
+type MonitoringSettingsRequest struct {
+	MonitoringEnabled      *bool `json:"monitoring_enabled"`
+	MonitorIntervalMinutes *int  `json:"monitor_interval_minutes" binding:"omitempty,min=0"`
+	MonitorPriority        *int  `json:"monitor_priority"`
+}
+
+func (r MonitoringSettingsRequest) toSettings() services.MonitoringSettings {
+	return services.MonitoringSettings{
+		Enabled:         r.MonitoringEnabled,
+		IntervalMinutes: r.MonitorIntervalMinutes,
+		Priority:        r.MonitorPriority,
+	}
+}
+
+// Handler création d'un lien court
+func CreateShortLinkHandler(linkService *services.LinkService, baseURL string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req CreateLinkRequest
 
@@ -60,26 +192,88 @@ func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
 		}
 
 		// Appel du service
-		link, err := linkService.CreateLink(req.LongURL)
+		link, reused, err := linkService.CreateOrReuseLink(req.LongURL, req.toOptions())
 		if err != nil {
+			var urlErr *services.URLError
+			if errors.As(err, &urlErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": urlErr.Error(), "code": urlErr.Code})
+				return
+			}
+			if errors.Is(err, services.ErrDestinationBlocked) {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": services.URLErrBlocked})
+				return
+			}
+			if errors.Is(err, services.ErrInvalidLinkOptions) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, services.ErrAliasTaken) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
 			log.Printf("Error creating short link for %s: %v", req.LongURL, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
 		}
 
-		c.JSON(http.StatusCreated, gin.H{
+		// Lien existant réutilisé : rien n'a été créé.
+		status := http.StatusCreated
+		if reused {
+			status = http.StatusOK
+		}
+		c.JSON(status, gin.H{
 			"short_code":     link.ShortCode,
 			"long_url":       link.LongURL,
-			"full_short_url": cfg.Server.BaseURL + "/" + link.ShortCode,
+			"full_short_url": baseURL + "/" + link.ShortCode,
+			"reused":         reused,
 		})
 	}
 }
 
+// Handler suppression d'un lien
+func DeleteLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		shortCode := c.Param("shortCode")
+
+		if err := linkService.DeleteLink(shortCode); err != nil {
+
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+				return
+			}
+
+			log.Printf("Error deleting link %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
 // Handler redirection
-func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
+func RedirectHandler(linkService *services.LinkService, urlMonitor *monitor.UrlMonitor, guard *services.PasswordGuard,
+	signer *services.URLSigner, baseURL string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		shortCode := c.Param("shortCode")
+		extraPath := c.Param("path") // Segments après le code court (route /:shortCode/*path)
+		if extraPath == "/" {
+			extraPath = ""
+		}
+
+		// "/abc123/qr" : QR code du lien (le chemin n'est jamais transmis à la destination).
+		if extraPath == "/qr" {
+			serveQR(c, linkService, baseURL, shortCode)
+			return
+		}
+
+		// "/abc123+" : page d'aperçu au lieu de la redirection.
+		if code, ok := strings.CutSuffix(shortCode, "+"); ok && extraPath == "" {
+			previewPage(c, linkService, urlMonitor, code)
+			return
+		}
 
 		link, err := linkService.GetLinkByShortCode(shortCode)
 		if err != nil {
@@ -94,28 +288,159 @@ func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
 			return
 		}
 
+		// Un chemin supplémentaire n'est accepté que par les liens qui le transmettent.
+		if extraPath != "" && !link.ForwardPath {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			return
+		}
+
+		// Lien désactivé (destination listée comme malveillante) : aucune redirection.
+		if link.Disabled {
+			c.HTML(http.StatusGone, "disabled.html", gin.H{"ShortCode": link.ShortCode})
+			return
+		}
+
+		// Lien à URL signées : signature valide et non expirée exigée, y compris des robots d'aperçu.
+		rawQuery := c.Request.URL.RawQuery
+		if link.RequireSignature {
+			if err := signer.Verify(link.ShortCode, c.Request.URL.Query(), time.Now()); err != nil {
+				signatureRejected(c, link, err)
+				return
+			}
+			rawQuery = services.WithoutQueryParam(rawQuery, signatureParams...)
+		}
+
+		// Lien protégé par mot de passe : formulaire de déverrouillage tant que le visiteur n'a pas
+		// de cookie valide. Les robots d'aperçu le reçoivent aussi : rien du lien n'est révélé.
+		if link.PasswordHash != "" && !passwordUnlocked(c, guard, link) {
+			unlockPage(c, link, http.StatusUnauthorized, "")
+			return
+		}
+
+		now := time.Now()
+		userAgent := c.GetHeader("User-Agent")
+
+		// Robot d'aperçu (réseau social, messagerie) : balises Open Graph au lieu de la redirection,
+		// sans compter de clic. Un lien signalé garde sa page d'avertissement.
+		if services.IsPreviewCrawler(userAgent) && !link.Flagged {
+			socialPage(c, linkService, baseURL, link)
+			return
+		}
+
+		// Règles de ciblage (système, appareil, langue, pays, heure) : la première qui correspond
+		// choisit la destination. Sa destination n'est pas celle surveillée par le moniteur.
+		var rule *models.LinkRule
+		if len(link.Rules) > 0 {
+			visitor := services.NewVisitor(userAgent, c.GetHeader("Accept-Language"), now)
+			visitor.Country = linkService.VisitorCountry(c.ClientIP())
+			rule = services.MatchRule(link.Rules, visitor)
+		}
+
+		// Test A/B : sans règle applicable, une variante est tirée selon les poids.
+		var variant *models.LinkVariant
+		if rule == nil && len(link.Variants) > 0 {
+			variant = chooseVariant(c, link)
+		}
+
 		// Construire l’événement de clic
 		clickEvent := models.ClickEvent{
 			LinkID:    link.ID,
-			Timestamp: time.Now(),
-			UserAgent: c.GetHeader("User-Agent"),
+			Timestamp: now,
+			UserAgent: userAgent,
 			IPAddress: c.ClientIP(),
 		}
+		// Scan d'un QR code (?src=qr) : attribué au clic, retiré des paramètres transmis.
+		if c.Query("src") == models.ClickSourceQR {
+			clickEvent.Source = models.ClickSourceQR
+			rawQuery = services.WithoutQueryParam(rawQuery, "src")
+		}
+		// Suivi des conversions : le jeton identifie ce clic auprès du site de destination.
+		// Les pages d'avertissement et intermédiaires ne comptent pas de clic : pas de jeton non plus.
+		if link.TrackConversions && !link.Flagged && !link.Interstitial {
+			token, err := services.NewClickToken()
+			if err != nil {
+				log.Printf("Error generating click token for %s: %v", shortCode, err)
+			} else {
+				clickEvent.Token = token
+			}
+		}
+		if rule != nil {
+			clickEvent.RuleID = &rule.ID
+		}
+		if variant != nil {
+			clickEvent.VariantID = &variant.ID
+		}
+
+		// Destination durablement cassée : appliquer la politique du lien.
+		// L'état provient du cache du moniteur, sans requête supplémentaire en base.
+		target := link.LongURL
+		status := linkService.RedirectStatus(link)
+		// Destination choisie par visiteur (règle, variante) : une redirection permanente
+		// serait mise en cache par le navigateur et figerait ce choix.
+		if rule != nil {
+			target, status = rule.Destination, temporaryStatus(status)
+		} else if variant != nil {
+			target, status = variant.Destination, temporaryStatus(status)
+		} else if urlMonitor != nil && urlMonitor.IsBroken(link) {
+			switch link.FailurePolicy {
+			case models.FailurePolicyUnavailable:
+				c.HTML(http.StatusServiceUnavailable, "unavailable.html", gin.H{"ShortCode": link.ShortCode})
+				return
+			case models.FailurePolicyFallback:
+				if link.FallbackURL != "" {
+					// Une redirection de repli est temporaire : jamais de 301/308 mis en cache par le navigateur.
+					target = link.FallbackURL
+					status = http.StatusFound
+				}
+			}
+		}
+
+		// Chemin et paramètres transmis à la destination, selon les réglages du lien.
+		if dest, err := linkService.ResolveDestination(link, target, extraPath, rawQuery, clickEvent.Token); err != nil {
+			log.Printf("Error building destination for %s: %v", shortCode, err)
+		} else {
+			target = dest
+		}
 
-		// Envoi non bloquant dans le channel
+		// Lien signalé par plusieurs utilisateurs : page d'avertissement avant de continuer.
+		if link.Flagged {
+			c.HTML(http.StatusOK, "warning.html", gin.H{"ShortCode": link.ShortCode, "LongURL": target})
+			return
+		}
+
+		// Page intermédiaire demandée pour ce lien (site partenaire, ...).
+		if link.Interstitial {
+			c.HTML(http.StatusOK, "interstitial.html", gin.H{"ShortCode": link.ShortCode, "LongURL": target})
+			return
+		}
+
+		// Seule une redirection effective compte comme un clic (pas les pages 503,
+		// d'avertissement ou intermédiaire). Envoi non bloquant dans le channel.
 		select {
 		case ClickEventsChannel <- clickEvent:
 		default:
 			log.Printf("Warning: ClickEventsChannel is full, dropping event for %s", shortCode)
 		}
 
-		// Redirection 302 vers l'URL longue
-		c.Redirect(http.StatusFound, link.LongURL)
+		// Redirection vers l'URL longue (ou de repli), avec le code propre au lien
+		c.Redirect(status, target)
+	}
+}
+
+// temporaryStatus retourne l'équivalent temporaire d'un code de redirection permanent
+// (301 -> 302, 308 -> 307, qui conserve la méthode).
+func temporaryStatus(status int) int {
+	switch status {
+	case http.StatusMovedPermanently:
+		return http.StatusFound
+	case http.StatusPermanentRedirect:
+		return http.StatusTemporaryRedirect
 	}
+	return status
 }
 
 // Handler stats
-func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
+func GetLinkStatsHandler(linkService *services.LinkService, conversionService *services.ConversionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		shortCode := c.Param("shortCode")
@@ -133,10 +458,325 @@ func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
 			return
 		}
 
+		hidden := destinationHidden(link)
+		resp := gin.H{
+			"short_code":         link.ShortCode,
+			"total_clicks":       totalClicks,
+			"disabled":           link.Disabled,
+			"flagged":            link.Flagged,
+			"interstitial":       link.Interstitial,
+			"password_protected": link.PasswordHash != "",
+			"signature_required": link.RequireSignature,
+			"redirect":           redirectSummary(link, linkService),
+			"utm":                utmSummary(linkService.EffectiveUTM(link)),
+		}
+		if !hidden {
+			resp["long_url"] = link.LongURL
+		}
+		if len(link.Rules) > 0 {
+			counts, err := linkService.CountClicksByRule(link)
+			if err != nil {
+				log.Printf("Error counting rule clicks for %s: %v", shortCode, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+			resp["rules"] = rulesSummary(link.Rules, counts, hidden)
+		}
+		if len(link.Variants) > 0 {
+			stats, err := linkService.VariantStats(link)
+			if err != nil {
+				log.Printf("Error counting variant clicks for %s: %v", shortCode, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+			resp["ab_test"] = variantsSummary(link, stats, hidden)
+		}
+		if link.TrackConversions {
+			conversions, err := conversionService.Stats(link)
+			if err != nil {
+				log.Printf("Error computing conversions for %s: %v", shortCode, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+			resp["conversions"] = conversionStatsSummary(conversions)
+		}
+		countries, err := linkService.ClicksByCountry(link)
+		if err != nil {
+			log.Printf("Error counting country clicks for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		resp["countries"] = countriesSummary(countries)
+		sources, err := linkService.ClicksBySource(link)
+		if err != nil {
+			log.Printf("Error counting click sources for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		resp["sources"] = sourcesSummary(sources)
+		if link.Disabled {
+			// Le motif d'un blocage par les listes de menaces cite le domaine de la destination.
+			if !hidden {
+				resp["disabled_reason"] = link.DisabledReason
+			}
+			resp["disabled_at"] = link.DisabledAt
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// Handler état de santé d'un lien (dernière vérification du moniteur)
+func GetLinkHealthHandler(linkService *services.LinkService, urlMonitor *monitor.UrlMonitor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		shortCode := c.Param("shortCode")
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+				return
+			}
+
+			log.Printf("Error retrieving health for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		health := monitor.LinkHealth{State: monitor.StateUnknown}
+		threshold := 0
+		broken := false
+		if urlMonitor != nil {
+			if h, ok := urlMonitor.Health(link.ID); ok {
+				health = h
+			}
+			threshold = urlMonitor.FailureThreshold(link)
+			broken = urlMonitor.IsBroken(link)
+		}
+
+		resp := gin.H{
+			"short_code":        link.ShortCode,
+			"failure_policy":    link.FailurePolicy,
+			"failure_threshold": threshold,
+			"policy_active":     broken && link.FailurePolicy != models.FailurePolicyKeep,
+			"content_monitored": link.ContentMonitoring,
+			"monitoring":        monitoringSummary(link, urlMonitor),
+		}
+		if destinationHidden(link) {
+			resp["health"] = withoutDestinationDetails(health)
+		} else {
+			resp["long_url"] = link.LongURL
+			resp["fallback_url"] = link.FallbackURL
+			resp["health"] = health
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// countriesSummary présente les clics par pays ; les clics sans pays connu sont regroupés sous "unknown".
+func countriesSummary(counts map[string]int) map[string]int {
+	out := make(map[string]int, len(counts))
+	for country, clicks := range counts {
+		if country == "" {
+			country = "unknown"
+		}
+		out[country] += clicks
+	}
+	return out
+}
+
+// sourcesSummary présente les clics par origine ; les accès directs sont regroupés sous "direct".
+func sourcesSummary(counts map[string]int) map[string]int {
+	out := make(map[string]int, len(counts))
+	for source, clicks := range counts {
+		if source == "" {
+			source = "direct"
+		}
+		out[source] += clicks
+	}
+	return out
+}
+
+// utmSummary liste les paramètres UTM renseignés.
+func utmSummary(utm models.UTMParams) gin.H {
+	summary := gin.H{}
+	for _, kv := range utm.Pairs() {
+		summary[kv[0]] = kv[1]
+	}
+	return summary
+}
+
+// destinationHidden indique si les routes publiques (statistiques, état, règles, variantes,
+// aperçu) doivent taire les destinations du lien : un lien protégé par mot de passe ne
+// révèle sa cible qu'après déverrouillage, un lien à URL signées qu'avec une signature valide.
+func destinationHidden(link *models.Link) bool {
+	return link.PasswordHash != "" || link.RequireSignature
+}
+
+// withoutDestinationDetails ne garde de l'état d'un lien que ce qui ne décrit pas sa destination :
+// certificats, domaine, titres de page et messages d'erreur (qui citent l'URL) sont retirés.
+func withoutDestinationDetails(health monitor.LinkHealth) monitor.LinkHealth {
+	return monitor.LinkHealth{
+		State:               health.State,
+		ConsecutiveFailures: health.ConsecutiveFailures,
+		StatusCode:          health.StatusCode,
+		LastCheckedAt:       health.LastCheckedAt,
+	}
+}
+
+// Handler stats d'une campagne (utm_campaign)
+func GetCampaignStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		campaign := c.Param("campaign")
+
+		stats, total, err := linkService.CampaignStats(campaign)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidLinkOptions) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			log.Printf("Error retrieving stats for campaign %s: %v", campaign, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		links := make([]gin.H, 0, len(stats))
+		for _, s := range stats {
+			summary := gin.H{
+				"short_code":   s.Link.ShortCode,
+				"total_clicks": s.Clicks,
+				"utm":          utmSummary(linkService.EffectiveUTM(&s.Link)),
+			}
+			if !destinationHidden(&s.Link) {
+				summary["long_url"] = s.Link.LongURL
+			}
+			links = append(links, summary)
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"campaign":     campaign,
+			"total_clicks": total,
+			"links":        links,
+		})
+	}
+}
+
+// redirectSummary décrit les réglages de redirection effectifs d'un lien.
+func redirectSummary(link *models.Link, linkService *services.LinkService) gin.H {
+	return gin.H{
+		"status":        linkService.RedirectStatus(link),
+		"forward_query": link.ForwardQuery,
+		"forward_path":  link.ForwardPath,
+	}
+}
+
+// monitoringSummary décrit les réglages de surveillance effectifs d'un lien.
+func monitoringSummary(link *models.Link, urlMonitor *monitor.UrlMonitor) gin.H {
+	summary := gin.H{
+		"enabled":  !link.MonitorDisabled,
+		"priority": link.MonitorPriority,
+	}
+	if urlMonitor != nil {
+		summary["interval"] = urlMonitor.LinkInterval(link).String()
+		if next, ok := urlMonitor.NextCheck(link.ID); ok && !link.MonitorDisabled {
+			summary["next_check_at"] = next
+		}
+	}
+	return summary
+}
+
+// Handler vérification immédiate d'un lien par le moniteur
+func CheckLinkHandler(linkService *services.LinkService, urlMonitor *monitor.UrlMonitor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		shortCode := c.Param("shortCode")
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+				return
+			}
+
+			log.Printf("Error retrieving link for check %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if urlMonitor == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Monitor not running"})
+			return
+		}
+
+		health := urlMonitor.CheckNow(link)
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"long_url":   link.LongURL,
+			"health":     health,
+			"monitoring": monitoringSummary(link, urlMonitor),
+		})
+	}
+}
+
+// Handler mise à jour des réglages de surveillance d'un lien
+func UpdateMonitoringHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		shortCode := c.Param("shortCode")
+
+		var req MonitoringSettingsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.UpdateMonitoring(shortCode, req.toSettings())
+		if err != nil {
+
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+				return
+			}
+			if errors.Is(err, services.ErrInvalidLinkOptions) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			log.Printf("Error updating monitoring for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code":               link.ShortCode,
+			"monitoring_enabled":       !link.MonitorDisabled,
+			"monitor_interval_minutes": link.MonitorIntervalMinutes,
+			"monitor_priority":         link.MonitorPriority,
+		})
+	}
+}
+
+// Handler compteurs du cache de redirection
+func CacheStatsHandler(linkCache *repository.CachedLinkRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if linkCache == nil {
+			c.JSON(http.StatusOK, gin.H{"enabled": false})
+			return
+		}
+
+		stats := linkCache.Stats()
+		hitRatio := 0.0
+		if total := stats.Hits + stats.NegativeHits + stats.Misses; total > 0 {
+			hitRatio = float64(stats.Hits+stats.NegativeHits) / float64(total)
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"short_code":   link.ShortCode,
-			"long_url":     link.LongURL,
-			"total_clicks": totalClicks,
+			"enabled":   true,
+			"stats":     stats,
+			"hit_ratio": hitRatio,
 		})
 	}
 }
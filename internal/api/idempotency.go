@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader est l'en-tête par lequel un client rend une création rejouable sans effet.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength correspond à la taille de la colonne idempotency_key.
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentResponse borne la réponse mémorisée, sous la taille de la colonne
+// response_body (MEDIUMTEXT sous MySQL). Au-delà, la clé est libérée sans mémoriser la réponse.
+const maxIdempotentResponse = 8 << 20
+
+// IdempotencyMiddleware rejoue la réponse d'origine quand une requête est renvoyée avec le
+// même en-tête Idempotency-Key pendant window. La même clé avec un corps différent est
+// refusée (422), et une requête encore en cours de traitement donne un 409.
+// Les réponses 5xx ne sont pas mémorisées : le client peut réessayer avec la même clé.
+// Sans dépôt ou avec une fenêtre nulle, le middleware ne fait rien.
+func IdempotencyMiddleware(repo repository.IdempotencyRepository, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if repo == nil || window <= 0 || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unable to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(sum[:])
+		scope := c.Request.Method + " " + c.FullPath()
+
+		now := time.Now()
+		record := &models.IdempotencyKey{
+			Key:         key,
+			Scope:       scope,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(window),
+		}
+		reserved, err := repo.Reserve(record)
+		if err == nil && !reserved {
+			var existing *models.IdempotencyKey
+			existing, err = repo.Find(key, scope)
+
+			// Clé expirée pas encore purgée : elle est libérée puis réservée à nouveau.
+			if err == nil && existing != nil && !existing.ExpiresAt.After(now) {
+				if err = repo.Release(existing); err == nil {
+					record.ID = 0
+					reserved, err = repo.Reserve(record)
+					existing = nil
+				}
+			}
+
+			if err == nil && !reserved {
+				replayIdempotent(c, existing, requestHash)
+				return
+			}
+		}
+		if err != nil {
+			log.Printf("Error handling idempotency key for %s: %v", scope, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// Traitement de la requête d'origine en capturant la réponse.
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			releaseIdempotencyKey(repo, record)
+			return
+		}
+		if writer.body.Len() > maxIdempotentResponse {
+			log.Printf("Idempotent response for %s is too large to be stored (%d bytes)", scope, writer.body.Len())
+			releaseIdempotencyKey(repo, record)
+			return
+		}
+		record.StatusCode = status
+		record.ContentType = writer.Header().Get("Content-Type")
+		record.ResponseBody = writer.body.String()
+		if err := repo.Complete(record); err != nil {
+			// Sans réponse mémorisée, la clé resterait "en cours" (409) jusqu'à son expiration.
+			log.Printf("Error storing idempotent response for %s: %v", scope, err)
+			releaseIdempotencyKey(repo, record)
+		}
+	}
+}
+
+// releaseIdempotencyKey libère une clé réservée : le client peut réessayer avec la même clé.
+func releaseIdempotencyKey(repo repository.IdempotencyRepository, record *models.IdempotencyKey) {
+	if err := repo.Release(record); err != nil {
+		log.Printf("Error releasing idempotency key for %s: %v", record.Scope, err)
+	}
+}
+
+// replayIdempotent répond à une requête répétée à partir de la réponse mémorisée.
+// existing vaut nil si la clé vient d'être reprise par une requête concurrente.
+func replayIdempotent(c *gin.Context, existing *models.IdempotencyKey, requestHash string) {
+	switch {
+	case existing == nil:
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is being processed"})
+	case existing.RequestHash != requestHash:
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+	case existing.StatusCode == 0:
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is being processed"})
+	default:
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(existing.StatusCode, existing.ContentType, []byte(existing.ResponseBody))
+		c.Abort()
+	}
+}
+
+// capturingWriter conserve une copie du corps de la réponse écrite par le handler.
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/database/dbtest"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// failingCompleteRepository échoue au premier enregistrement de réponse (ex: corps trop long).
+type failingCompleteRepository struct {
+	*repository.GormIdempotencyRepository
+	failures int
+}
+
+func (r *failingCompleteRepository) Complete(record *models.IdempotencyKey) error {
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("data too long for column 'response_body'")
+	}
+	return r.GormIdempotencyRepository.Complete(record)
+}
+
+func TestIdempotencyKeyReleasedWhenResponseIsNotStored(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := dbtest.Migrated(t, dbtest.Targets(t)[0])
+	repo := &failingCompleteRepository{GormIdempotencyRepository: repository.NewIdempotencyRepository(db), failures: 1}
+
+	calls := 0
+	router := gin.New()
+	router.POST("/links", IdempotencyMiddleware(repo, time.Hour), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/links", strings.NewReader(`{"long_url":"https://example.com/"}`))
+		req.Header.Set(IdempotencyKeyHeader, "retry-me")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send(); rec.Code != http.StatusCreated {
+		t.Fatalf("first request: status %d, want %d", rec.Code, http.StatusCreated)
+	}
+	// La réponse n'a pas pu être mémorisée : la clé doit être libérée, pas rester "en cours".
+	if rec := send(); rec.Code != http.StatusCreated || calls != 2 {
+		t.Fatalf("retry after failed Complete: status %d after %d calls, want %d after 2 calls", rec.Code, calls, http.StatusCreated)
+	}
+	rec := send()
+	if rec.Code != http.StatusCreated || calls != 2 || rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry after stored response: status %d, %d calls, replayed %q; want a replay of the second response",
+			rec.Code, calls, rec.Header().Get("Idempotent-Replayed"))
+	}
+}
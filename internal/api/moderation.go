@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ReportLinkRequest est le corps de POST /api/v1/links/:shortCode/report.
+type ReportLinkRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ModerateLinkRequest est le corps de POST /api/v1/admin/reports/:shortCode.
+type ModerateLinkRequest struct {
+	Action    string `json:"action" binding:"required,oneof=dismiss disable delete"`
+	Note      string `json:"note"`
+	Moderator string `json:"moderator"` // Nom consigné dans le journal d'audit (défaut : admin)
+}
+
+// Handler signalement d'un lien
+func ReportLinkHandler(moderationService *services.ModerationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req ReportLinkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if _, err := moderationService.ReportLink(shortCode, req.Reason, c.ClientIP()); err != nil {
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			case errors.Is(err, services.ErrInvalidReport):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				log.Printf("Error reporting link %s: %v", shortCode, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			}
+			return
+		}
+
+		// Même réponse pour un signalement répété : rien n'indique au signaleur s'il a été compté.
+		c.JSON(http.StatusAccepted, gin.H{"status": "received"})
+	}
+}
+
+// Handler file de modération
+func ListReportsHandler(moderationService *services.ModerationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if err != nil || limit < 1 || limit > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+
+		entries, err := moderationService.Queue(c.Query("status"), limit, 5)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidReport) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			log.Printf("Error listing reports: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		queue := make([]gin.H, 0, len(entries))
+		for _, e := range entries {
+			reports := make([]gin.H, 0, len(e.Latest))
+			for _, r := range e.Latest {
+				reports = append(reports, gin.H{
+					"reason":      r.Reason,
+					"status":      r.Status,
+					"reported_at": r.CreatedAt,
+				})
+			}
+			queue = append(queue, gin.H{
+				"short_code": e.Link.ShortCode,
+				"long_url":   e.Link.LongURL,
+				"reports":    e.Reports,
+				"flagged":    e.Link.Flagged,
+				"disabled":   e.Link.Disabled,
+				"latest":     reports,
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{"links": queue})
+	}
+}
+
+// Handler décision de modération
+func ModerateLinkHandler(moderationService *services.ModerationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req ModerateLinkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := moderationService.Moderate(shortCode, req.Action, req.Moderator, req.Note)
+		if err != nil {
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			case errors.Is(err, services.ErrInvalidReport):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				log.Printf("Error moderating link %s: %v", shortCode, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"action":     req.Action,
+			"flagged":    link.Flagged,
+			"disabled":   link.Disabled,
+		})
+	}
+}
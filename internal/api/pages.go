@@ -0,0 +1,91 @@
+package api
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/axellelanca/urlshortener/internal/monitor"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// templatesFS embarque les pages HTML servies par le service (ex: destination indisponible).
+//
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+// embeddedTemplates est l'ensemble des templates HTML embarqués, chargé une fois au démarrage.
+var embeddedTemplates = template.Must(template.ParseFS(templatesFS, "templates/*.html"))
+
+// LoadPageTemplates retourne les templates des pages HTML. Si dir n'est pas vide, chaque
+// fichier dir/*.html remplace le template embarqué de même nom (ex: preview.html), ce qui
+// permet d'adapter les pages à sa charte sans recompiler.
+func LoadPageTemplates(dir string) (*template.Template, error) {
+	if dir == "" {
+		return embeddedTemplates, nil
+	}
+	pages, err := embeddedTemplates.Clone()
+	if err != nil {
+		return nil, err
+	}
+	pages, err = pages.ParseGlob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load page templates from %s: %w", dir, err)
+	}
+	return pages, nil
+}
+
+// previewPage affiche la page d'aperçu d'un lien (destination, date de création, clics,
+// état vu par le moniteur) sans rediriger ni compter de clic.
+func previewPage(c *gin.Context, linkService *services.LinkService, urlMonitor *monitor.UrlMonitor, shortCode string) {
+	link, clicks, err := linkService.GetLinkStats(shortCode)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			return
+		}
+		log.Printf("Error retrieving preview for %s: %v", shortCode, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	health := monitor.LinkHealth{State: monitor.StateUnknown}
+	if urlMonitor != nil {
+		if h, ok := urlMonitor.Health(link.ID); ok {
+			health = h
+		}
+	}
+
+	c.HTML(http.StatusOK, "preview.html", gin.H{
+		"ShortCode": link.ShortCode,
+		"LongURL":   link.LongURL,
+		"CreatedAt": link.CreatedAt,
+		"Clicks":    clicks,
+		"Health":    healthLabel(health.State),
+		"CheckedAt": health.LastCheckedAt,
+		"Disabled":  link.Disabled,
+		"Flagged":   link.Flagged,
+		"Protected": link.PasswordHash != "",
+		"Signed":    link.RequireSignature,
+	})
+}
+
+// healthLabel traduit l'état d'un lien pour les pages HTML.
+func healthLabel(state monitor.HealthState) string {
+	switch state {
+	case monitor.StateAccessible:
+		return "Destination accessible"
+	case monitor.StateInaccessible:
+		return "Destination inaccessible"
+	case monitor.StateCertInvalid:
+		return "Certificat TLS invalide"
+	default:
+		return "Pas encore vérifiée"
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SetPasswordRequest est le corps de PUT /api/v1/links/:shortCode/password.
+type SetPasswordRequest struct {
+	Password string `json:"password"` // Vide = retirer la protection
+}
+
+// unlockCookieName est le cookie qui porte le jeton de déverrouillage d'un lien protégé.
+func unlockCookieName(link *models.Link) string {
+	return "unlock_" + link.ShortCode
+}
+
+// passwordUnlocked indique si la requête porte un jeton de déverrouillage valide pour le lien.
+func passwordUnlocked(c *gin.Context, guard *services.PasswordGuard, link *models.Link) bool {
+	token, err := c.Cookie(unlockCookieName(link))
+	return err == nil && guard.Verify(link, token, time.Now())
+}
+
+// unlockPage affiche le formulaire de mot de passe ; il est renvoyé à l'adresse demandée,
+// chemin et paramètres compris.
+func unlockPage(c *gin.Context, link *models.Link, status int, message string) {
+	c.Header("Cache-Control", "no-store")
+	c.HTML(status, "unlock.html", gin.H{
+		"ShortCode": link.ShortCode,
+		"Action":    c.Request.URL.RequestURI(),
+		"Error":     message,
+	})
+}
+
+// Handler formulaire de déverrouillage d'un lien protégé (POST /:shortCode)
+func UnlockHandler(linkService *services.LinkService, guard *services.PasswordGuard, signer *services.URLSigner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+				return
+			}
+			log.Printf("Error retrieving link for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		if link.Disabled {
+			c.HTML(http.StatusGone, "disabled.html", gin.H{"ShortCode": link.ShortCode})
+			return
+		}
+		// Sans URL signée valide, pas d'essai de mot de passe possible.
+		if link.RequireSignature {
+			if err := signer.Verify(link.ShortCode, c.Request.URL.Query(), time.Now()); err != nil {
+				signatureRejected(c, link, err)
+				return
+			}
+		}
+		if link.PasswordHash == "" {
+			c.Redirect(http.StatusSeeOther, c.Request.URL.RequestURI())
+			return
+		}
+
+		now := time.Now()
+		token, err := guard.Unlock(link, c.ClientIP(), c.PostForm("password"), now)
+		switch {
+		case errors.Is(err, services.ErrTooManyAttempts):
+			retry := guard.RetryAfter(link, c.ClientIP(), now)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			unlockPage(c, link, http.StatusTooManyRequests,
+				fmt.Sprintf("Trop d'essais infructueux : réessayez dans %d minute(s).", int(math.Ceil(retry.Minutes()))))
+			return
+		case errors.Is(err, services.ErrWrongPassword):
+			unlockPage(c, link, http.StatusUnauthorized, "Mot de passe incorrect.")
+			return
+		case err != nil:
+			log.Printf("Error unlocking %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// Cookie limité au lien, puis retour à l'adresse demandée : la redirection (et le clic)
+		// se fait par un GET ordinaire.
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(unlockCookieName(link), token, int(guard.UnlockTTL().Seconds()),
+			"/"+link.ShortCode, "", c.Request.TLS != nil, true)
+		c.Redirect(http.StatusSeeOther, c.Request.URL.RequestURI())
+	}
+}
+
+// Handler définition ou retrait du mot de passe d'un lien
+func SetPasswordHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req SetPasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.SetPassword(shortCode, req.Password)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrInvalidLinkOptions):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			default:
+				log.Printf("Error updating password for %s: %v", shortCode, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"short_code": link.ShortCode, "password_protected": link.PasswordHash != ""})
+	}
+}
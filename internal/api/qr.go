@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/axellelanca/urlshortener/internal/qr"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// qrOptions lit les paramètres du QR code demandé : format (png, svg), size, level (L, M, Q, H),
+// margin (en modules), fg et bg (couleurs hexadécimales, bg=transparent accepté).
+func qrOptions(c *gin.Context) (qr.Options, error) {
+	opts := qr.DefaultOptions()
+	if v := c.Query("format"); v != "" {
+		opts.Format = v
+	}
+	if v := c.Query("level"); v != "" {
+		opts.Level = v
+	}
+	for name, target := range map[string]*int{"size": &opts.Size, "margin": &opts.Margin} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return opts, errors.New("invalid " + name + ": must be an integer")
+			}
+			*target = n
+		}
+	}
+	if v := c.Query("fg"); v != "" {
+		fg, err := qr.ParseColor(v)
+		if err != nil {
+			return opts, err
+		}
+		opts.Foreground = fg
+	}
+	if v := c.Query("bg"); v != "" {
+		bg, err := qr.ParseColor(v)
+		if err != nil {
+			return opts, err
+		}
+		opts.Background = bg
+	}
+	return opts, opts.Validate()
+}
+
+// serveQR répond avec le QR code d'un lien, qui encode son URL courte marquée ?src=qr.
+func serveQR(c *gin.Context, linkService *services.LinkService, baseURL, shortCode string) {
+	opts, err := qrOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := linkService.GetLinkByShortCode(shortCode)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			return
+		}
+		log.Printf("Error retrieving link for QR code %s: %v", shortCode, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	if link.Disabled {
+		c.JSON(http.StatusGone, gin.H{"error": "Link disabled"})
+		return
+	}
+
+	data, err := qr.Encode(services.QRContent(baseURL, link.ShortCode), opts)
+	if err != nil {
+		if errors.Is(err, qr.ErrInvalidOptions) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error generating QR code for %s: %v", shortCode, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, opts.ContentType(), data)
+}
+
+// Handler QR code d'un lien (API)
+func GetQRCodeHandler(linkService *services.LinkService, baseURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serveQR(c, linkService, baseURL, c.Param("shortCode"))
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RuleRequest est une règle de ciblage : toutes les conditions renseignées doivent correspondre.
+type RuleRequest struct {
+	OS          string   `json:"os"`        // ios, android, windows, macos, linux ou chromeos
+	Device      string   `json:"device"`    // mobile, tablet ou desktop
+	Languages   []string `json:"languages"` // Langue préférée du visiteur (Accept-Language), ex: ["fr", "de-ch"]
+	Countries   []string `json:"countries"` // Pays du visiteur (base GeoIP), ex: ["FR", "BE"]
+	TimeFrom    string   `json:"time_from"` // Plage horaire "HH:MM" (début inclus)
+	TimeTo      string   `json:"time_to"`   // Plage horaire "HH:MM" (fin exclue)
+	Timezone    string   `json:"timezone"`  // Fuseau de la plage horaire, UTC par défaut
+	Destination string   `json:"destination" binding:"required"`
+}
+
+// SetRulesRequest est le corps de PUT /api/v1/links/:shortCode/rules.
+type SetRulesRequest struct {
+	Rules []RuleRequest `json:"rules" binding:"dive"`
+}
+
+func (r RuleRequest) toModel() models.LinkRule {
+	return models.LinkRule{
+		OS:          r.OS,
+		Device:      r.Device,
+		Languages:   strings.Join(r.Languages, ","),
+		Countries:   strings.Join(r.Countries, ","),
+		TimeFrom:    r.TimeFrom,
+		TimeTo:      r.TimeTo,
+		Timezone:    r.Timezone,
+		Destination: r.Destination,
+	}
+}
+
+func toRuleModels(rules []RuleRequest) []models.LinkRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	out := make([]models.LinkRule, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, r.toModel())
+	}
+	return out
+}
+
+// ruleSummary décrit une règle de ciblage et, si counts n'est pas nil, son nombre de clics ;
+// hideDestination tait sa destination (voir destinationHidden).
+func ruleSummary(rule models.LinkRule, counts map[uint]int, hideDestination bool) gin.H {
+	summary := gin.H{"id": rule.ID, "position": rule.Position}
+	if !hideDestination {
+		summary["destination"] = rule.Destination
+	}
+	if rule.OS != "" {
+		summary["os"] = rule.OS
+	}
+	if rule.Device != "" {
+		summary["device"] = rule.Device
+	}
+	if rule.Languages != "" {
+		summary["languages"] = strings.Split(rule.Languages, ",")
+	}
+	if rule.Countries != "" {
+		summary["countries"] = strings.Split(rule.Countries, ",")
+	}
+	if rule.TimeFrom != "" {
+		summary["time_from"] = rule.TimeFrom
+		summary["time_to"] = rule.TimeTo
+		summary["timezone"] = rule.Timezone
+	}
+	if counts != nil {
+		summary["clicks"] = counts[rule.ID]
+	}
+	return summary
+}
+
+func rulesSummary(rules []models.LinkRule, counts map[uint]int, hideDestinations bool) []gin.H {
+	out := make([]gin.H, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, ruleSummary(rule, counts, hideDestinations))
+	}
+	return out
+}
+
+// Handler règles de ciblage d'un lien
+func GetRulesHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+				return
+			}
+			log.Printf("Error retrieving rules for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"short_code": link.ShortCode, "rules": rulesSummary(link.Rules, nil, destinationHidden(link))})
+	}
+}
+
+// Handler remplacement des règles de ciblage d'un lien
+func SetRulesHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req SetRulesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.SetRules(shortCode, toRuleModels(req.Rules))
+		if err != nil {
+			var urlErr *services.URLError
+			switch {
+			case errors.As(err, &urlErr):
+				c.JSON(http.StatusBadRequest, gin.H{"error": urlErr.Error(), "code": urlErr.Code})
+			case errors.Is(err, services.ErrDestinationBlocked):
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": services.URLErrBlocked})
+			case errors.Is(err, services.ErrInvalidLinkOptions):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			default:
+				log.Printf("Error updating rules for %s: %v", shortCode, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"short_code": link.ShortCode, "rules": rulesSummary(link.Rules, nil, false)})
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SignLinkRequest est le corps de POST /api/v1/links/:shortCode/sign.
+type SignLinkRequest struct {
+	ExpiresIn int               `json:"expires_in" binding:"omitempty,min=1"` // Validité en secondes, 0 = durée par défaut
+	Params    map[string]string `json:"params"`                               // Paramètres ajoutés à l'URL et couverts par la signature
+}
+
+// SetSignatureRequest est le corps de PUT /api/v1/links/:shortCode/signature.
+type SetSignatureRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+// signatureParams sont les paramètres propres à la signature, jamais transmis à la destination.
+var signatureParams = []string{services.SignatureExpiresParam, services.SignatureKeyParam,
+	services.SignatureBindParam, services.SignatureParam}
+
+// signatureRejected répond à une URL non signée, altérée ou expirée d'un lien qui exige une signature.
+func signatureRejected(c *gin.Context, link *models.Link, err error) {
+	status := http.StatusForbidden
+	if errors.Is(err, services.ErrSignatureExpired) {
+		status = http.StatusGone
+	}
+	c.Header("Cache-Control", "no-store")
+	c.HTML(status, "signature.html", gin.H{
+		"ShortCode": link.ShortCode,
+		"Expired":   status == http.StatusGone,
+	})
+}
+
+// Handler création d'une URL signée et limitée dans le temps
+func SignLinkHandler(linkService *services.LinkService, signer *services.URLSigner, baseURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req SignLinkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+				return
+			}
+			log.Printf("Error retrieving link for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		signed, expires, err := signer.Sign(baseURL, link.ShortCode, req.Params,
+			time.Duration(req.ExpiresIn)*time.Second, time.Now())
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidSigningRequest) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			log.Printf("Error signing URL for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code":         link.ShortCode,
+			"url":                signed,
+			"expires_at":         expires,
+			"key_id":             signer.ActiveKey(),
+			"signature_required": link.RequireSignature,
+		})
+	}
+}
+
+// Handler activation ou désactivation de l'exigence d'URL signées pour un lien
+func SetSignatureHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req SetSignatureRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.SetRequireSignature(shortCode, *req.Required)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+				return
+			}
+			log.Printf("Error updating signature setting for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"short_code": link.ShortCode, "signature_required": link.RequireSignature})
+	}
+}
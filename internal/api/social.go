@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PreviewRequest regroupe l'aperçu Open Graph d'un lien (création et PUT /links/:shortCode/preview).
+// Les champs vides reprennent les valeurs relevées sur la destination par le moniteur.
+type PreviewRequest struct {
+	OGTitle       string `json:"og_title"`
+	OGDescription string `json:"og_description"`
+	OGImage       string `json:"og_image"` // URL absolue de l'image
+}
+
+func (r PreviewRequest) toModel() models.LinkPreview {
+	return models.LinkPreview{Title: r.OGTitle, Description: r.OGDescription, Image: r.OGImage}
+}
+
+func previewSummary(preview models.LinkPreview) gin.H {
+	return gin.H{"title": preview.Title, "description": preview.Description, "image": preview.Image}
+}
+
+// socialPage répond aux robots des réseaux sociaux et messageries par une page portant
+// les balises Open Graph et Twitter Card du lien, au lieu de la redirection.
+func socialPage(c *gin.Context, linkService *services.LinkService, baseURL string, link *models.Link) {
+	preview := linkService.SocialPreview(link)
+	if preview.Title == "" {
+		// Sans titre connu, le domaine de la destination reste plus parlant que le code court.
+		if u, err := url.Parse(link.LongURL); err == nil && u.Host != "" {
+			preview.Title = u.Hostname()
+		} else {
+			preview.Title = link.ShortCode
+		}
+	}
+	c.HTML(http.StatusOK, "social.html", gin.H{
+		"ShortURL":    baseURL + "/" + link.ShortCode,
+		"LongURL":     link.LongURL,
+		"Title":       preview.Title,
+		"Description": preview.Description,
+		"Image":       preview.Image,
+	})
+}
+
+// Handler aperçu Open Graph d'un lien
+func GetPreviewHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+				return
+			}
+			log.Printf("Error retrieving preview for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, previewResponse(link, linkService, destinationHidden(link)))
+	}
+}
+
+// Handler remplacement de l'aperçu Open Graph d'un lien
+func SetPreviewHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req PreviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.SetPreview(shortCode, req.toModel())
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrInvalidLinkOptions):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			default:
+				log.Printf("Error updating preview for %s: %v", shortCode, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, previewResponse(link, linkService, false))
+	}
+}
+
+// previewResponse détaille l'aperçu saisi, celui relevé sur la destination et celui servi aux robots.
+// Avec hideFetched, l'aperçu relevé (titre et description de la page de destination) est omis.
+func previewResponse(link *models.Link, linkService *services.LinkService, hideFetched bool) gin.H {
+	if hideFetched {
+		return gin.H{
+			"short_code": link.ShortCode,
+			"custom":     previewSummary(link.Preview),
+			"effective":  previewSummary(link.Preview),
+		}
+	}
+	response := gin.H{
+		"short_code": link.ShortCode,
+		"custom":     previewSummary(link.Preview),
+		"fetched":    previewSummary(link.FetchedPreview),
+		"effective":  previewSummary(linkService.SocialPreview(link)),
+	}
+	if link.PreviewFetchedAt != nil {
+		response["fetched_at"] = link.PreviewFetchedAt
+	}
+	return response
+}
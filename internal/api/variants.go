@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// variantCookieMaxAge est la durée de l'attribution persistante d'un visiteur à une variante (30 jours).
+const variantCookieMaxAge = 30 * 24 * 60 * 60
+
+// VariantRequest est une destination pondérée d'un test A/B.
+type VariantRequest struct {
+	Name        string `json:"name"`   // Nom affiché dans les statistiques, "A", "B", ... par défaut
+	Weight      int    `json:"weight"` // Part relative du trafic, 1 par défaut
+	Destination string `json:"destination" binding:"required"`
+}
+
+// SetVariantsRequest est le corps de PUT /api/v1/links/:shortCode/variants.
+type SetVariantsRequest struct {
+	Variants []VariantRequest `json:"variants" binding:"dive"`
+	Sticky   bool             `json:"sticky"` // Garder chaque visiteur sur sa variante (cookie)
+}
+
+func toVariantModels(variants []VariantRequest) []models.LinkVariant {
+	if len(variants) == 0 {
+		return nil
+	}
+	out := make([]models.LinkVariant, 0, len(variants))
+	for _, v := range variants {
+		out = append(out, models.LinkVariant{Name: v.Name, Weight: v.Weight, Destination: v.Destination})
+	}
+	return out
+}
+
+// chooseVariant choisit la variante servie au visiteur. Avec StickyVariants, la variante
+// déjà attribuée (cookie) est reprise si elle existe toujours, sinon l'attribution est mémorisée.
+func chooseVariant(c *gin.Context, link *models.Link) *models.LinkVariant {
+	cookieName := "ab_" + link.ShortCode
+	if link.StickyVariants {
+		if value, err := c.Cookie(cookieName); err == nil {
+			if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+				if variant := services.VariantByID(link.Variants, uint(id)); variant != nil {
+					return variant
+				}
+			}
+		}
+	}
+	variant := services.PickVariant(link.Variants)
+	if variant != nil && link.StickyVariants {
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(cookieName, strconv.FormatUint(uint64(variant.ID), 10), variantCookieMaxAge,
+			"/"+link.ShortCode, "", c.Request.TLS != nil, true)
+	}
+	return variant
+}
+
+// variantsSummary décrit les variantes d'un lien, avec leur répartition si stats n'est pas nil ;
+// hideDestinations tait leurs destinations (voir destinationHidden).
+func variantsSummary(link *models.Link, stats []services.VariantStats, hideDestinations bool) gin.H {
+	variants := make([]gin.H, 0, len(link.Variants))
+	for i, v := range link.Variants {
+		summary := gin.H{"id": v.ID, "name": v.Name, "weight": v.Weight}
+		if !hideDestinations {
+			summary["destination"] = v.Destination
+		}
+		if stats != nil {
+			summary["clicks"] = stats[i].Clicks
+			summary["share"] = stats[i].Share
+			summary["target_share"] = stats[i].TargetShare
+		}
+		variants = append(variants, summary)
+	}
+	return gin.H{"sticky": link.StickyVariants, "variants": variants}
+}
+
+// Handler variantes A/B d'un lien
+func GetVariantsHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		link, err := linkService.GetLinkByShortCode(shortCode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+				return
+			}
+			log.Printf("Error retrieving variants for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		resp := variantsSummary(link, nil, destinationHidden(link))
+		resp["short_code"] = link.ShortCode
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// Handler remplacement des variantes A/B d'un lien
+func SetVariantsHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req SetVariantsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.SetVariants(shortCode, toVariantModels(req.Variants), req.Sticky)
+		if err != nil {
+			var urlErr *services.URLError
+			switch {
+			case errors.As(err, &urlErr):
+				c.JSON(http.StatusBadRequest, gin.H{"error": urlErr.Error(), "code": urlErr.Code})
+			case errors.Is(err, services.ErrDestinationBlocked):
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": services.URLErrBlocked})
+			case errors.Is(err, services.ErrInvalidLinkOptions):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			default:
+				log.Printf("Error updating variants for %s: %v", shortCode, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			}
+			return
+		}
+
+		resp := variantsSummary(link, nil, false)
+		resp["short_code"] = link.ShortCode
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"log" // Pour logger les informations ou erreurs de chargement de config
+	"strings"
 
 	"github.com/spf13/viper" // La bibliothèque pour la gestion de configuration
 )
@@ -10,19 +11,45 @@ import (
 // Les tags `mapstructure` sont utilisés par Viper pour mapper les clés du fichier de config
 // (ou des variables d'environnement) aux champs de la structure Go.
 type Config struct {
-	Server    ServerConfig    `mapstructure:"server"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	Analytics AnalyticsConfig `mapstructure:"analytics"`
-	Monitor   MonitorConfig   `mapstructure:"monitor"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Analytics   AnalyticsConfig   `mapstructure:"analytics"`
+	Monitor     MonitorConfig     `mapstructure:"monitor"`
+	Cache       CacheConfig       `mapstructure:"cache"`
+	Links       LinksConfig       `mapstructure:"links"`
+	UTM         UTMConfig         `mapstructure:"utm"`
+	Threats     ThreatsConfig     `mapstructure:"threats"`
+	Moderation  ModerationConfig  `mapstructure:"moderation"`
+	GeoIP       GeoIPConfig       `mapstructure:"geoip"`
+	Conversions ConversionsConfig `mapstructure:"conversions"`
+	Passwords   PasswordsConfig   `mapstructure:"passwords"`
+	Signing     SigningConfig     `mapstructure:"signing"`
 }
 
 type ServerConfig struct {
-	Port    int    `mapstructure:"port"`
-	BaseURL string `mapstructure:"base_url"`
+	Port          int    `mapstructure:"port"`
+	BaseURL       string `mapstructure:"base_url"`
+	BatchMaxItems int    `mapstructure:"batch_max_items"` // Nombre maximal de liens par requête de création par lot
+
+	IdempotencyWindowMinutes int `mapstructure:"idempotency_window_minutes"` // Durée de rejeu des réponses (Idempotency-Key), 0 = désactivé
+
+	AdminToken string `mapstructure:"admin_token"` // Jeton exigé par les routes d'administration (Authorization: Bearer), vide = désactivées
+
+	TemplatesDir string `mapstructure:"templates_dir"` // Répertoire de templates HTML remplaçant les pages embarquées, vide = pages par défaut
+
+	// Proxys (IP ou CIDR) dont les en-têtes X-Forwarded-For / X-Real-IP sont crus pour l'adresse du
+	// client ; vide = aucun, l'adresse est celle de la connexion TCP.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
-	Name string `mapstructure:"name"`
+	Name   string `mapstructure:"name"`   // Fichier SQLite (utilisé comme DSN par défaut avec le driver sqlite)
+	Driver string `mapstructure:"driver"` // sqlite, postgres ou mysql
+	DSN    string `mapstructure:"dsn"`    // Chaîne de connexion propre au driver
+
+	MaxOpenConns           int `mapstructure:"max_open_conns"`            // 0 = illimité
+	MaxIdleConns           int `mapstructure:"max_idle_conns"`            // 0 = valeur par défaut de database/sql
+	ConnMaxLifetimeMinutes int `mapstructure:"conn_max_lifetime_minutes"` // 0 = pas de limite
 }
 
 type AnalyticsConfig struct {
@@ -31,7 +58,95 @@ type AnalyticsConfig struct {
 }
 
 type MonitorConfig struct {
-	IntervalMinutes int `mapstructure:"interval_minutes"`
+	IntervalMinutes  int `mapstructure:"interval_minutes"`
+	FailureThreshold int `mapstructure:"failure_threshold"` // Échecs consécutifs avant d'appliquer la politique d'un lien
+
+	CertExpiryWarningDays   int    `mapstructure:"cert_expiry_warning_days"`   // Alerte si le certificat expire dans ce délai
+	DomainExpiryCheck       bool   `mapstructure:"domain_expiry_check"`        // Vérifier l'expiration des domaines via RDAP
+	DomainExpiryWarningDays int    `mapstructure:"domain_expiry_warning_days"` // Alerte si le domaine expire dans ce délai
+	RDAPURL                 string `mapstructure:"rdap_url"`                   // Service RDAP interrogé
+
+	ContentChangeThreshold int   `mapstructure:"content_change_threshold"` // % de différence signalé comme changement de contenu
+	ContentMaxBytes        int64 `mapstructure:"content_max_bytes"`        // Taille maximale du corps analysé
+
+	FetchPreviews       bool `mapstructure:"fetch_previews"`        // Relever l'aperçu Open Graph des destinations
+	PreviewRefreshHours int  `mapstructure:"preview_refresh_hours"` // Délai avant de relire l'aperçu d'une destination
+
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"` // Vérifier aussi les destinations privées ou locales
+
+	TickSeconds      int `mapstructure:"tick_seconds"`        // Fréquence de recherche des liens à vérifier
+	RefreshSeconds   int `mapstructure:"refresh_seconds"`     // Fréquence de rechargement de la liste complète des liens
+	JitterPercent    int `mapstructure:"jitter_percent"`      // Gigue appliquée aux échéances (% de l'intervalle)
+	MaxChecksPerTick int `mapstructure:"max_checks_per_tick"` // 0 = illimité
+}
+
+type CacheConfig struct {
+	Enabled            bool `mapstructure:"enabled"`
+	MaxEntries         int  `mapstructure:"max_entries"`          // Nombre maximal de liens gardés en mémoire (LRU)
+	TTLSeconds         int  `mapstructure:"ttl_seconds"`          // Durée de vie d'un lien en cache
+	NegativeTTLSeconds int  `mapstructure:"negative_ttl_seconds"` // Durée de vie d'un code inconnu en cache
+}
+
+type LinksConfig struct {
+	ReuseExisting bool `mapstructure:"reuse_existing"` // Retourner le lien existant vers la même destination au lieu d'en créer un
+
+	AllowedSchemes      []string `mapstructure:"allowed_schemes"`       // Schémas acceptés pour les URL de destination
+	MaxURLLength        int      `mapstructure:"max_url_length"`        // Longueur maximale d'une URL, 0 = illimitée
+	StripTrackingParams bool     `mapstructure:"strip_tracking_params"` // Retirer les paramètres de suivi (utm_*, fbclid, ...)
+	TrackingParams      []string `mapstructure:"tracking_params"`       // Paramètres retirés ; vide = liste par défaut
+
+	RedirectStatus  int    `mapstructure:"redirect_status"`  // Code de redirection par défaut : 301, 302, 307 ou 308
+	QueryPrecedence string `mapstructure:"query_precedence"` // Paramètre présent des deux côtés : destination, incoming ou append
+}
+
+// UTMConfig définit les paramètres UTM appliqués aux liens qui ne les renseignent pas.
+type UTMConfig struct {
+	Source   string `mapstructure:"source"`
+	Medium   string `mapstructure:"medium"`
+	Campaign string `mapstructure:"campaign"`
+	Term     string `mapstructure:"term"`
+	Content  string `mapstructure:"content"`
+}
+
+type ThreatsConfig struct {
+	DomainLists     []string `mapstructure:"domain_lists"`      // Fichiers de domaines bloqués (un par ligne, ou format hosts)
+	HashPrefixLists []string `mapstructure:"hash_prefix_lists"` // Fichiers de préfixes SHA-256 hexadécimaux (format Safe Browsing)
+	RegexLists      []string `mapstructure:"regex_lists"`       // Fichiers d'expressions régulières (une par ligne)
+
+	ReloadIntervalSeconds int `mapstructure:"reload_interval_seconds"` // Fréquence de détection des fichiers modifiés, 0 = jamais
+}
+
+type ModerationConfig struct {
+	ReportThreshold int `mapstructure:"report_threshold"` // Signaleurs distincts avant la page d'avertissement, 0 = jamais
+}
+
+type GeoIPConfig struct {
+	Database string `mapstructure:"database"` // Base locale au format MaxMind (.mmdb), vide = pas de géolocalisation
+}
+
+type ConversionsConfig struct {
+	TokenParam string `mapstructure:"token_param"` // Paramètre ajouté à la destination avec le jeton de clic
+	WindowDays int    `mapstructure:"window_days"` // Délai maximal entre le clic et la conversion, 0 = illimité
+}
+
+type PasswordsConfig struct {
+	CookieSecret   string `mapstructure:"cookie_secret"`   // Clé de signature des cookies de déverrouillage, vide = clé aléatoire au démarrage
+	UnlockMinutes  int    `mapstructure:"unlock_minutes"`  // Durée de validité d'un déverrouillage
+	MaxAttempts    int    `mapstructure:"max_attempts"`    // Échecs tolérés par adresse IP et par lien avant blocage
+	LockoutMinutes int    `mapstructure:"lockout_minutes"` // Fenêtre de comptage des échecs et durée du blocage
+}
+
+type SigningConfig struct {
+	Keys      []SigningKeyConfig `mapstructure:"keys"`       // Clés acceptées pour vérifier les URL signées
+	ActiveKey string             `mapstructure:"active_key"` // Clé utilisée pour signer, vide = la première
+	// Durées de validité des URL signées : par défaut et maximale.
+	DefaultTTLHours int `mapstructure:"default_ttl_hours"`
+	MaxTTLDays      int `mapstructure:"max_ttl_days"`
+}
+
+type SigningKeyConfig struct {
+	ID     string `mapstructure:"id"`     // Identifiant porté par les URL (paramètre kid)
+	Secret string `mapstructure:"secret"` // Secret HMAC, 16 caractères au moins
 }
 
 // LoadConfig charge la configuration de l'application en utilisant Viper.
@@ -56,13 +171,84 @@ func LoadConfig() (*Config, error) {
 
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.base_url", "http://localhost:8080")
+	viper.SetDefault("server.batch_max_items", 1000)
+	viper.SetDefault("server.idempotency_window_minutes", 1440)
+	viper.SetDefault("server.admin_token", "")
+	viper.SetDefault("server.templates_dir", "")
+	viper.SetDefault("server.trusted_proxies", []string{})
 
 	viper.SetDefault("database.name", "url_shortener.db")
+	viper.SetDefault("database.driver", "sqlite")
+	viper.SetDefault("database.dsn", "")
+	viper.SetDefault("database.max_open_conns", 0)
+	viper.SetDefault("database.max_idle_conns", 2)
+	viper.SetDefault("database.conn_max_lifetime_minutes", 0)
 
 	viper.SetDefault("analytics.buffer_size", 1000)
 	viper.SetDefault("analytics.worker_count", 5)
 
 	viper.SetDefault("monitor.interval_minutes", 5)
+	viper.SetDefault("monitor.failure_threshold", 3)
+	viper.SetDefault("monitor.cert_expiry_warning_days", 14)
+	viper.SetDefault("monitor.domain_expiry_check", false)
+	viper.SetDefault("monitor.domain_expiry_warning_days", 30)
+	viper.SetDefault("monitor.rdap_url", "https://rdap.org")
+	viper.SetDefault("monitor.content_change_threshold", 10)
+	viper.SetDefault("monitor.content_max_bytes", 1048576)
+	viper.SetDefault("monitor.fetch_previews", false)
+	viper.SetDefault("monitor.preview_refresh_hours", 24)
+	viper.SetDefault("monitor.allow_private_networks", false)
+	viper.SetDefault("monitor.tick_seconds", 30)
+	viper.SetDefault("monitor.refresh_seconds", 300)
+	viper.SetDefault("monitor.jitter_percent", 20)
+	viper.SetDefault("monitor.max_checks_per_tick", 0)
+
+	viper.SetDefault("cache.enabled", true)
+	viper.SetDefault("cache.max_entries", 10000)
+	viper.SetDefault("cache.ttl_seconds", 60)
+	viper.SetDefault("cache.negative_ttl_seconds", 10)
+
+	viper.SetDefault("links.reuse_existing", false)
+	viper.SetDefault("links.allowed_schemes", []string{"http", "https"})
+	viper.SetDefault("links.max_url_length", 2048)
+	viper.SetDefault("links.strip_tracking_params", false)
+	viper.SetDefault("links.redirect_status", 302)
+	viper.SetDefault("links.query_precedence", "destination")
+
+	viper.SetDefault("utm.source", "")
+	viper.SetDefault("utm.medium", "")
+	viper.SetDefault("utm.campaign", "")
+	viper.SetDefault("utm.term", "")
+	viper.SetDefault("utm.content", "")
+
+	viper.SetDefault("threats.domain_lists", []string{})
+	viper.SetDefault("threats.hash_prefix_lists", []string{})
+	viper.SetDefault("threats.regex_lists", []string{})
+	viper.SetDefault("threats.reload_interval_seconds", 60)
+
+	viper.SetDefault("moderation.report_threshold", 3)
+
+	viper.SetDefault("geoip.database", "")
+
+	viper.SetDefault("conversions.token_param", "sl_click")
+	viper.SetDefault("conversions.window_days", 30)
+
+	viper.SetDefault("passwords.cookie_secret", "")
+	viper.SetDefault("passwords.unlock_minutes", 60)
+	viper.SetDefault("passwords.max_attempts", 5)
+	viper.SetDefault("passwords.lockout_minutes", 15)
+
+	viper.SetDefault("signing.keys", []SigningKeyConfig{})
+	viper.SetDefault("signing.active_key", "")
+	viper.SetDefault("signing.default_ttl_hours", 24)
+	viper.SetDefault("signing.max_ttl_days", 90)
+
+	// Les variables d'environnement préfixées surchargent le fichier de config,
+	// ex: URLSHORTENER_DATABASE_DRIVER=postgres URLSHORTENER_DATABASE_DSN=...
+	// (pratique pour pointer les tests d'intégration vers une autre base).
+	viper.SetEnvPrefix("URLSHORTENER")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
 
 	//  : Lire le fichier de configuration.
 
@@ -78,8 +264,8 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// Log  pour vérifier la config chargée
-	log.Printf("Configuration loaded: Server Port=%d, DB Name=%s, Analytics Buffer=%d, Monitor Interval=%dmin",
-		cfg.Server.Port, cfg.Database.Name, cfg.Analytics.BufferSize, cfg.Monitor.IntervalMinutes)
+	log.Printf("Configuration loaded: Server Port=%d, DB Driver=%s, DB Name=%s, Analytics Buffer=%d, Monitor Interval=%dmin",
+		cfg.Server.Port, cfg.Database.Driver, cfg.Database.Name, cfg.Analytics.BufferSize, cfg.Monitor.IntervalMinutes)
 
 	return &cfg, nil // Retourne la configuration chargée
 }
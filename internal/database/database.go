@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Drivers de base de données supportés.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+// Open ouvre la connexion GORM décrite par la configuration et applique les réglages du pool.
+// C'est le point d'entrée unique utilisé par le serveur et toutes les commandes CLI.
+func Open(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := dialectorFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %w", driverName(cfg), err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+
+	maxOpen := cfg.MaxOpenConns
+	if driverName(cfg) == DriverSQLite && isInMemorySQLite(dsnFor(cfg)) {
+		// Chaque connexion à ":memory:" ouvre une base distincte : une seule connexion
+		// garantit que toutes les requêtes voient le même schéma (tests, bases jetables).
+		maxOpen = 1
+	}
+	if maxOpen > 0 {
+		sqlDB.SetMaxOpenConns(maxOpen)
+	}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetimeMinutes > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetimeMinutes) * time.Minute)
+	}
+
+	return db, nil
+}
+
+// Close ferme la connexion sous-jacente et se contente de logger une éventuelle erreur.
+// Elle est pensée pour être appelée avec defer juste après Open.
+func Close(db *gorm.DB) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Printf("WARN : Échec d'accès au driver SQL natif : %v", err)
+		return
+	}
+	if err := sqlDB.Close(); err != nil {
+		log.Printf("WARN : Échec de la fermeture de la connexion DB : %v", err)
+	}
+}
+
+// dialectorFor choisit le driver GORM correspondant à la configuration.
+func dialectorFor(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	var open func(dsn string) gorm.Dialector
+	switch driverName(cfg) {
+	case DriverSQLite:
+		open = sqlite.Open
+	case DriverPostgres:
+		open = postgres.Open
+	case DriverMySQL:
+		open = mysql.Open
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q (expected sqlite, postgres or mysql)", cfg.Driver)
+	}
+
+	dsn := dsnFor(cfg)
+	if dsn == "" {
+		return nil, fmt.Errorf("database.dsn is required for driver %q", driverName(cfg))
+	}
+	return open(dsn), nil
+}
+
+// driverName normalise le nom du driver ; SQLite est utilisé par défaut.
+func driverName(cfg config.DatabaseConfig) string {
+	switch driver := strings.ToLower(strings.TrimSpace(cfg.Driver)); driver {
+	case "", "sqlite3":
+		return DriverSQLite
+	case "postgresql", "pg":
+		return DriverPostgres
+	default:
+		return driver
+	}
+}
+
+// dsnFor retourne la chaîne de connexion ; pour SQLite, le nom de fichier historique
+// (database.name) sert de DSN si aucun n'est fourni.
+func dsnFor(cfg config.DatabaseConfig) string {
+	if cfg.DSN != "" {
+		return cfg.DSN
+	}
+	if driverName(cfg) == DriverSQLite {
+		return cfg.Name
+	}
+	return ""
+}
+
+func isInMemorySQLite(dsn string) bool {
+	return strings.Contains(dsn, ":memory:") || strings.Contains(dsn, "mode=memory")
+}
@@ -0,0 +1,90 @@
+package database_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/database/dbtest"
+)
+
+func TestOpen(t *testing.T) {
+	for _, target := range dbtest.Targets(t) {
+		t.Run(target.Name, func(t *testing.T) {
+			db := dbtest.Open(t, target)
+			var one int
+			if err := db.Raw("SELECT 1").Scan(&one).Error; err != nil {
+				t.Fatalf("SELECT 1: %v", err)
+			}
+			if one != 1 {
+				t.Fatalf("SELECT 1 = %d", one)
+			}
+		})
+	}
+}
+
+func TestOpenAppliesPoolSettings(t *testing.T) {
+	db, err := database.Open(config.DatabaseConfig{
+		Driver:       "sqlite3", // Alias accepté de sqlite
+		Name:         filepath.Join(t.TempDir(), "pool.db"),
+		MaxOpenConns: 7,
+	})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close(db)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sqlDB.Stats().MaxOpenConnections; got != 7 {
+		t.Errorf("MaxOpenConnections = %d, want 7", got)
+	}
+}
+
+func TestOpenInMemorySQLiteUsesSingleConnection(t *testing.T) {
+	db, err := database.Open(config.DatabaseConfig{DSN: "file::memory:", MaxOpenConns: 10})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close(db)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sqlDB.Stats().MaxOpenConnections; got != 1 {
+		t.Errorf("MaxOpenConnections = %d, want 1", got)
+	}
+
+	// Toutes les requêtes doivent voir le même schéma.
+	if err := db.Exec("CREATE TABLE t (id INTEGER)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if !db.Migrator().HasTable("t") {
+		t.Error("table created on another in-memory database")
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.DatabaseConfig
+		want string
+	}{
+		{"unsupported driver", config.DatabaseConfig{Driver: "oracle", DSN: "x"}, "unsupported database driver"},
+		{"postgres without dsn", config.DatabaseConfig{Driver: "postgresql", Name: "ignored.db"}, "database.dsn is required"},
+		{"mysql without dsn", config.DatabaseConfig{Driver: database.DriverMySQL}, "database.dsn is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := database.Open(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Open error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
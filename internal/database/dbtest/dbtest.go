@@ -0,0 +1,80 @@
+// Package dbtest fournit les bases de données des tests d'intégration.
+//
+// SQLite (fichier temporaire) est toujours disponible. PostgreSQL et MySQL sont testés
+// quand une base jetable est fournie par variable d'environnement, par exemple un
+// PostgreSQL embarqué ou un service de CI :
+//
+//	URLSHORTENER_TEST_POSTGRES_DSN="host=localhost user=test password=test dbname=test sslmode=disable"
+//	URLSHORTENER_TEST_MYSQL_DSN="test:test@tcp(localhost:3306)/test?parseTime=true"
+//
+// Ces bases sont vidées par les tests : ne jamais y pointer une base de production.
+package dbtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/migrations"
+	"gorm.io/gorm"
+)
+
+// Variables d'environnement désignant les bases PostgreSQL et MySQL de test.
+const (
+	PostgresDSNEnv = "URLSHORTENER_TEST_POSTGRES_DSN"
+	MySQLDSNEnv    = "URLSHORTENER_TEST_MYSQL_DSN"
+)
+
+// Target est une base de test : le nom du driver et la configuration pour database.Open.
+type Target struct {
+	Name   string
+	Config config.DatabaseConfig
+}
+
+// Targets retourne les bases disponibles : SQLite, plus PostgreSQL et MySQL si leur DSN est fourni.
+func Targets(t *testing.T) []Target {
+	t.Helper()
+	targets := []Target{{
+		Name:   database.DriverSQLite,
+		Config: config.DatabaseConfig{Driver: database.DriverSQLite, Name: filepath.Join(t.TempDir(), "test.db")},
+	}}
+	if dsn := os.Getenv(PostgresDSNEnv); dsn != "" {
+		targets = append(targets, Target{Name: database.DriverPostgres, Config: config.DatabaseConfig{Driver: database.DriverPostgres, DSN: dsn}})
+	}
+	if dsn := os.Getenv(MySQLDSNEnv); dsn != "" {
+		targets = append(targets, Target{Name: database.DriverMySQL, Config: config.DatabaseConfig{Driver: database.DriverMySQL, DSN: dsn}})
+	}
+	return targets
+}
+
+// Open ouvre la base de test ; la connexion est fermée à la fin du test.
+func Open(t *testing.T, target Target) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(target.Config)
+	if err != nil {
+		t.Fatalf("open %s: %v", target.Name, err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+	return db
+}
+
+// Migrated ouvre la base de test et y applique toutes les migrations ; les bases partagées
+// (PostgreSQL, MySQL) sont remises à vide avant et après le test.
+func Migrated(t *testing.T, target Target) *gorm.DB {
+	t.Helper()
+	db := Open(t, target)
+	if _, err := migrations.Down(db, len(migrations.All())); err != nil {
+		t.Fatalf("reset %s: %v", target.Name, err)
+	}
+	t.Cleanup(func() {
+		if _, err := migrations.Down(db, len(migrations.All())); err != nil {
+			t.Errorf("cleanup %s: %v", target.Name, err)
+		}
+	})
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("migrate %s: %v", target.Name, err)
+	}
+	return db
+}
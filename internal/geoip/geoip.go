@@ -0,0 +1,80 @@
+// Package geoip localise les adresses IP des visiteurs à partir d'une base locale
+// au format MaxMind (.mmdb : GeoLite2-Country, GeoLite2-City, DB-IP, ...), sans appel réseau.
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location est la position d'une adresse IP. Les champs sont vides si la base
+// ne connaît pas l'adresse (adresse privée, base pays sans villes, ...).
+type Location struct {
+	Country string // Code ISO 3166-1 alpha-2 en majuscules (ex: "FR")
+	City    string // Nom anglais de la ville
+}
+
+// record reprend les seuls champs lus dans les enregistrements MaxMind.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// Locator interroge une base .mmdb. Il est sûr pour un usage concurrent.
+// Un Locator nil ne localise rien : l'appelant n'a pas à tester si une base est configurée.
+type Locator struct {
+	reader *maxminddb.Reader
+}
+
+// Open charge la base .mmdb indiquée.
+func Open(path string) (*Locator, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database %s: %w", path, err)
+	}
+	return &Locator{reader: reader}, nil
+}
+
+// Lookup retourne la position d'une adresse IP (ex: c.ClientIP()).
+func (l *Locator) Lookup(ip string) Location {
+	if l == nil {
+		return Location{}
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return Location{}
+	}
+	var rec record
+	if err := l.reader.Lookup(addr, &rec); err != nil {
+		return Location{}
+	}
+	return Location{Country: strings.ToUpper(rec.Country.ISOCode), City: rec.City.Names["en"]}
+}
+
+// Country retourne le code pays d'une adresse IP, ou "" si elle est inconnue.
+func (l *Locator) Country(ip string) string {
+	return l.Lookup(ip).Country
+}
+
+// DatabaseType retourne le type de la base chargée (ex: "GeoLite2-City").
+func (l *Locator) DatabaseType() string {
+	if l == nil {
+		return ""
+	}
+	return l.reader.Metadata.DatabaseType
+}
+
+// Close libère la base.
+func (l *Locator) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.reader.Close()
+}
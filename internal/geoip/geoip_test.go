@@ -0,0 +1,67 @@
+package geoip_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/geoip"
+	"github.com/axellelanca/urlshortener/internal/geoip/geoiptest"
+)
+
+func TestLookup(t *testing.T) {
+	locator := geoiptest.Open(t)
+
+	tests := []struct {
+		name string
+		ip   string
+		want geoip.Location
+	}{
+		{"ville", geoiptest.IPParis, geoip.Location{Country: "FR", City: "Paris"}},
+		{"code pays en minuscules", geoiptest.IPBerlin, geoip.Location{Country: "DE", City: "Berlin"}},
+		{"pays sans ville", geoiptest.IPSwiss, geoip.Location{Country: "CH"}},
+		{"IPv6", geoiptest.IPBrussels, geoip.Location{Country: "BE", City: "Brussels"}},
+		{"adresse inconnue", geoiptest.IPUnknown, geoip.Location{}},
+		{"adresse invalide", "not-an-ip", geoip.Location{}},
+		{"adresse vide", "", geoip.Location{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := locator.Lookup(tt.ip); got != tt.want {
+				t.Errorf("Lookup(%q) = %+v, want %+v", tt.ip, got, tt.want)
+			}
+		})
+	}
+
+	if got := locator.Country(geoiptest.IPParis); got != "FR" {
+		t.Errorf("Country(%q) = %q, want FR", geoiptest.IPParis, got)
+	}
+	if got := locator.DatabaseType(); got != "URLShortener-Test-City" {
+		t.Errorf("DatabaseType() = %q", got)
+	}
+}
+
+func TestNilLocator(t *testing.T) {
+	var locator *geoip.Locator
+	if got := locator.Lookup(geoiptest.IPParis); got != (geoip.Location{}) {
+		t.Errorf("Lookup on nil locator = %+v, want zero", got)
+	}
+	if got := locator.Country(geoiptest.IPParis); got != "" {
+		t.Errorf("Country on nil locator = %q, want empty", got)
+	}
+	if got := locator.DatabaseType(); got != "" {
+		t.Errorf("DatabaseType on nil locator = %q, want empty", got)
+	}
+	if err := locator.Close(); err != nil {
+		t.Errorf("Close on nil locator: %v", err)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	if _, err := geoip.Open(filepath.Join(t.TempDir(), "missing.mmdb")); err == nil {
+		t.Error("Open of a missing file succeeded")
+	}
+	// Un fichier qui n'est pas une base MaxMind est refusé à l'ouverture.
+	if _, err := geoip.Open(filepath.Join("testdata", "generate.go")); err == nil {
+		t.Error("Open of a non-mmdb file succeeded")
+	}
+}
@@ -0,0 +1,37 @@
+// Package geoiptest fournit aux tests la base GeoIP de test (internal/geoip/testdata),
+// qui ne couvre que des réseaux réservés à la documentation : aucun appel réseau.
+package geoiptest
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/geoip"
+)
+
+// Adresses connues de la base de test.
+const (
+	IPParis    = "192.0.2.10"     // FR, Paris
+	IPBerlin   = "198.51.100.20"  // DE, Berlin
+	IPSwiss    = "203.0.113.30"   // CH, sans ville
+	IPBrussels = "2001:db8:1::40" // BE, Brussels
+	IPUnknown  = "198.18.0.1"     // Absente de la base
+)
+
+// Path retourne le chemin de la base de test.
+func Path() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "testdata", "test-city.mmdb")
+}
+
+// Open charge la base de test et la ferme à la fin du test.
+func Open(t testing.TB) *geoip.Locator {
+	t.Helper()
+	locator, err := geoip.Open(Path())
+	if err != nil {
+		t.Fatalf("geoip.Open: %v", err)
+	}
+	t.Cleanup(func() { locator.Close() })
+	return locator
+}
@@ -0,0 +1,62 @@
+//go:build ignore
+
+// Génère test-city.mmdb, la base GeoIP des tests, sur les seuls réseaux réservés
+// à la documentation (RFC 5737, RFC 3849) :
+//
+//	192.0.2.0/24     FR  Paris
+//	198.51.100.0/24  de  Berlin   (code en minuscules : Lookup le normalise)
+//	203.0.113.0/24   CH  (sans ville)
+//	2001:db8:1::/48  BE  Brussels
+//
+// mmdbwriter n'est pas une dépendance du module : pour régénérer la base, copier ce
+// fichier dans un module temporaire, puis
+//
+//	go mod init gen && go get github.com/maxmind/mmdbwriter@v1.0.0
+//	go run . /chemin/vers/internal/geoip/testdata/test-city.mmdb
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+)
+
+func main() {
+	w, err := mmdbwriter.New(mmdbwriter.Options{
+		DatabaseType:            "URLShortener-Test-City",
+		Description:             map[string]string{"en": "Test fixture, documentation networks only"},
+		RecordSize:              24,
+		IncludeReservedNetworks: true,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	add := func(cidr, iso, city string) {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rec := mmdbtype.Map{"country": mmdbtype.Map{"iso_code": mmdbtype.String(iso)}}
+		if city != "" {
+			rec["city"] = mmdbtype.Map{"names": mmdbtype.Map{"en": mmdbtype.String(city), "fr": mmdbtype.String(city + " (fr)")}}
+		}
+		if err := w.Insert(n, rec); err != nil {
+			log.Fatal(err)
+		}
+	}
+	add("192.0.2.0/24", "FR", "Paris")
+	add("198.51.100.0/24", "de", "Berlin")
+	add("203.0.113.0/24", "CH", "")
+	add("2001:db8:1::/48", "BE", "Brussels")
+	f, err := os.Create(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := w.WriteTo(f); err != nil {
+		log.Fatal(err)
+	}
+}
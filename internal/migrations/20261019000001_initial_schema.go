@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Instantané des modèles au moment de cette migration. Les migrations ne doivent pas
+// utiliser les structs de internal/models, qui continueront d'évoluer.
+type initialLink struct {
+	ID        uint      `gorm:"primaryKey"`
+	ShortCode string    `gorm:"size:10;uniqueIndex;not null"`
+	LongURL   string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	FailurePolicy    string `gorm:"size:20;default:keep"`
+	FailureThreshold int
+	FallbackURL      string
+
+	ContentMonitoring bool
+
+	MonitorDisabled        bool
+	MonitorIntervalMinutes int
+	MonitorPriority        int
+}
+
+func (initialLink) TableName() string { return "links" }
+
+type initialClick struct {
+	ID        uint        `gorm:"primaryKey"`
+	LinkID    uint        `gorm:"index"`
+	Link      initialLink `gorm:"foreignKey:LinkID"`
+	Timestamp time.Time
+	UserAgent string `gorm:"size:255"`
+	IPAddress string `gorm:"size:50"`
+}
+
+func (initialClick) TableName() string { return "clicks" }
+
+type initialLinkCheck struct {
+	ID         uint      `gorm:"primaryKey"`
+	LinkID     uint      `gorm:"index"`
+	CheckedAt  time.Time `gorm:"index"`
+	State      string    `gorm:"size:20"`
+	StatusCode int
+
+	ContentHash string `gorm:"size:64"`
+	SimHash     string `gorm:"size:16"`
+	Title       string `gorm:"size:255"`
+
+	ContentChanged bool   `gorm:"index"`
+	PreviousTitle  string `gorm:"size:255"`
+	Difference     int
+}
+
+func (initialLinkCheck) TableName() string { return "link_checks" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000001",
+		Name:    "initial_schema",
+		// AutoMigrate sur l'instantané : crée les tables sur une base vide et complète
+		// les colonnes manquantes d'une base créée par l'ancien 'migrate' (AutoMigrate).
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&initialLink{}, &initialClick{}, &initialLinkCheck{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&initialLinkCheck{}, &initialClick{}, &initialLink{})
+		},
+	})
+}
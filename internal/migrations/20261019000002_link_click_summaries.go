@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type clickSummariesLinkClickSummary struct {
+	ID         uint   `gorm:"primaryKey"`
+	LinkID     uint   `gorm:"uniqueIndex"`
+	Source     string `gorm:"size:20"`
+	Clicks     int
+	ImportedAt time.Time
+}
+
+func (clickSummariesLinkClickSummary) TableName() string { return "link_click_summaries" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000002",
+		Name:    "link_click_summaries",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&clickSummariesLinkClickSummary{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&clickSummariesLinkClickSummary{})
+		},
+	})
+}
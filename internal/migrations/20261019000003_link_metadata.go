@@ -0,0 +1,25 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type linkMetadataLink struct {
+	Metadata string `gorm:"type:text"`
+}
+
+func (linkMetadataLink) TableName() string { return "links" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000003",
+		Name:    "link_metadata",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&linkMetadataLink{}, "Metadata") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&linkMetadataLink{}, "Metadata")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&linkMetadataLink{}, "Metadata")
+		},
+	})
+}
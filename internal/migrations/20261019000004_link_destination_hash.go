@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+	"gorm.io/gorm"
+)
+
+type destinationHashLink struct {
+	ID              uint `gorm:"primaryKey"`
+	LongURL         string
+	DestinationHash string `gorm:"size:64;index"`
+}
+
+func (destinationHashLink) TableName() string { return "links" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000004",
+		Name:    "link_destination_hash",
+		// Ajoute l'empreinte de destination et la calcule pour les liens existants.
+		// Une évolution future de urlnorm devra s'accompagner de sa propre migration de recalcul.
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if !m.HasColumn(&destinationHashLink{}, "DestinationHash") {
+				if err := m.AddColumn(&destinationHashLink{}, "DestinationHash"); err != nil {
+					return err
+				}
+			}
+			if !m.HasIndex(&destinationHashLink{}, "DestinationHash") {
+				if err := m.CreateIndex(&destinationHashLink{}, "DestinationHash"); err != nil {
+					return err
+				}
+			}
+
+			var batch []destinationHashLink
+			return tx.Select("id", "long_url").Order("id").
+				FindInBatches(&batch, 500, func(batchTx *gorm.DB, _ int) error {
+					for _, link := range batch {
+						err := tx.Model(&destinationHashLink{}).Where("id = ?", link.ID).
+							Update("destination_hash", urlnorm.Hash(link.LongURL)).Error
+						if err != nil {
+							return err
+						}
+					}
+					return nil
+				}).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if m.HasIndex(&destinationHashLink{}, "DestinationHash") {
+				if err := m.DropIndex(&destinationHashLink{}, "DestinationHash"); err != nil {
+					return err
+				}
+			}
+			return m.DropColumn(&destinationHashLink{}, "DestinationHash")
+		},
+	})
+}
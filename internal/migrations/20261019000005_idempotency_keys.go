@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type idempotencyKeysIdempotencyKey struct {
+	ID           uint   `gorm:"primaryKey"`
+	Key          string `gorm:"column:idempotency_key;size:255;uniqueIndex:idx_idempotency_key_scope"`
+	Scope        string `gorm:"size:100;uniqueIndex:idx_idempotency_key_scope"`
+	RequestHash  string `gorm:"size:64"`
+	StatusCode   int
+	ContentType  string `gorm:"size:100"`
+	ResponseBody string `gorm:"type:text"`
+	CreatedAt    time.Time
+	ExpiresAt    time.Time `gorm:"index"`
+}
+
+func (idempotencyKeysIdempotencyKey) TableName() string { return "idempotency_keys" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000005",
+		Name:    "idempotency_keys",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&idempotencyKeysIdempotencyKey{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&idempotencyKeysIdempotencyKey{})
+		},
+	})
+}
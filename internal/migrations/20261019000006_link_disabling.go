@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type linkDisablingLink struct {
+	Disabled       bool   `gorm:"index;default:false"`
+	DisabledReason string `gorm:"size:255"`
+	DisabledAt     *time.Time
+}
+
+func (linkDisablingLink) TableName() string { return "links" }
+
+type linkDisablingLinkAudit struct {
+	ID        uint      `gorm:"primaryKey"`
+	LinkID    uint      `gorm:"index"`
+	ShortCode string    `gorm:"size:10"`
+	Action    string    `gorm:"size:30"`
+	Actor     string    `gorm:"size:100"`
+	Reason    string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (linkDisablingLinkAudit) TableName() string { return "link_audits" }
+
+var linkDisablingColumns = []string{"Disabled", "DisabledReason", "DisabledAt"}
+
+func init() {
+	Register(Migration{
+		Version: "20261019000006",
+		Name:    "link_disabling",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			for _, column := range linkDisablingColumns {
+				if m.HasColumn(&linkDisablingLink{}, column) {
+					continue
+				}
+				if err := m.AddColumn(&linkDisablingLink{}, column); err != nil {
+					return err
+				}
+			}
+			if !m.HasIndex(&linkDisablingLink{}, "Disabled") {
+				if err := m.CreateIndex(&linkDisablingLink{}, "Disabled"); err != nil {
+					return err
+				}
+			}
+			return m.CreateTable(&linkDisablingLinkAudit{})
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropTable(&linkDisablingLinkAudit{}); err != nil {
+				return err
+			}
+			if m.HasIndex(&linkDisablingLink{}, "Disabled") {
+				if err := m.DropIndex(&linkDisablingLink{}, "Disabled"); err != nil {
+					return err
+				}
+			}
+			for _, column := range linkDisablingColumns {
+				if err := m.DropColumn(&linkDisablingLink{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type linkReportsLink struct {
+	Flagged bool `gorm:"default:false"`
+}
+
+func (linkReportsLink) TableName() string { return "links" }
+
+type linkReportsLinkReport struct {
+	ID         uint      `gorm:"primaryKey"`
+	LinkID     uint      `gorm:"index"`
+	Reason     string    `gorm:"type:text"`
+	ReporterIP string    `gorm:"size:45"`
+	Status     string    `gorm:"size:20;index;default:open"`
+	CreatedAt  time.Time `gorm:"index"`
+	ResolvedAt *time.Time
+	ResolvedBy string `gorm:"size:100"`
+}
+
+func (linkReportsLinkReport) TableName() string { return "link_reports" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000007",
+		Name:    "link_reports",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if !m.HasColumn(&linkReportsLink{}, "Flagged") {
+				if err := m.AddColumn(&linkReportsLink{}, "Flagged"); err != nil {
+					return err
+				}
+			}
+			return m.CreateTable(&linkReportsLinkReport{})
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropTable(&linkReportsLinkReport{}); err != nil {
+				return err
+			}
+			return m.DropColumn(&linkReportsLink{}, "Flagged")
+		},
+	})
+}
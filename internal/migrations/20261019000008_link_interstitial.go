@@ -0,0 +1,26 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type linkInterstitialLink struct {
+	Interstitial bool `gorm:"default:false"`
+}
+
+func (linkInterstitialLink) TableName() string { return "links" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000008",
+		Name:    "link_interstitial",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if m.HasColumn(&linkInterstitialLink{}, "Interstitial") {
+				return nil
+			}
+			return m.AddColumn(&linkInterstitialLink{}, "Interstitial")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&linkInterstitialLink{}, "Interstitial")
+		},
+	})
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type linkRedirectOptionsLink struct {
+	RedirectType int
+	ForwardQuery bool `gorm:"default:false"`
+	ForwardPath  bool `gorm:"default:false"`
+}
+
+func (linkRedirectOptionsLink) TableName() string { return "links" }
+
+var linkRedirectOptionsColumns = []string{"RedirectType", "ForwardQuery", "ForwardPath"}
+
+func init() {
+	Register(Migration{
+		Version: "20261019000009",
+		Name:    "link_redirect_options",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			for _, column := range linkRedirectOptionsColumns {
+				if m.HasColumn(&linkRedirectOptionsLink{}, column) {
+					continue
+				}
+				if err := m.AddColumn(&linkRedirectOptionsLink{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			for _, column := range linkRedirectOptionsColumns {
+				if err := m.DropColumn(&linkRedirectOptionsLink{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
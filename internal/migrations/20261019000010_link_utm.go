@@ -0,0 +1,51 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type linkUTMLink struct {
+	UTMSource   string `gorm:"size:100"`
+	UTMMedium   string `gorm:"size:100"`
+	UTMCampaign string `gorm:"size:100;index"`
+	UTMTerm     string `gorm:"size:100"`
+	UTMContent  string `gorm:"size:100"`
+}
+
+func (linkUTMLink) TableName() string { return "links" }
+
+var linkUTMColumns = []string{"UTMSource", "UTMMedium", "UTMCampaign", "UTMTerm", "UTMContent"}
+
+func init() {
+	Register(Migration{
+		Version: "20261019000010",
+		Name:    "link_utm",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			for _, column := range linkUTMColumns {
+				if m.HasColumn(&linkUTMLink{}, column) {
+					continue
+				}
+				if err := m.AddColumn(&linkUTMLink{}, column); err != nil {
+					return err
+				}
+			}
+			if !m.HasIndex(&linkUTMLink{}, "UTMCampaign") {
+				return m.CreateIndex(&linkUTMLink{}, "UTMCampaign")
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if m.HasIndex(&linkUTMLink{}, "UTMCampaign") {
+				if err := m.DropIndex(&linkUTMLink{}, "UTMCampaign"); err != nil {
+					return err
+				}
+			}
+			for _, column := range linkUTMColumns {
+				if err := m.DropColumn(&linkUTMLink{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type linkRulesLinkRule struct {
+	ID       uint `gorm:"primaryKey"`
+	LinkID   uint `gorm:"index"`
+	Position int
+
+	OS        string `gorm:"size:20"`
+	Device    string `gorm:"size:20"`
+	Languages string `gorm:"size:255"`
+	TimeFrom  string `gorm:"size:5"`
+	TimeTo    string `gorm:"size:5"`
+	Timezone  string `gorm:"size:64"`
+
+	Destination string `gorm:"not null"`
+	CreatedAt   time.Time
+}
+
+func (linkRulesLinkRule) TableName() string { return "link_rules" }
+
+type linkRulesClick struct {
+	RuleID *uint
+}
+
+func (linkRulesClick) TableName() string { return "clicks" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000011",
+		Name:    "link_rules",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.CreateTable(&linkRulesLinkRule{}); err != nil {
+				return err
+			}
+			if m.HasColumn(&linkRulesClick{}, "RuleID") {
+				return nil
+			}
+			return m.AddColumn(&linkRulesClick{}, "RuleID")
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropColumn(&linkRulesClick{}, "RuleID"); err != nil {
+				return err
+			}
+			return m.DropTable(&linkRulesLinkRule{})
+		},
+	})
+}
@@ -0,0 +1,62 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type clickGeoClick struct {
+	Country string `gorm:"size:2;index"`
+	City    string `gorm:"size:100"`
+}
+
+func (clickGeoClick) TableName() string { return "clicks" }
+
+type clickGeoLinkRule struct {
+	Countries string `gorm:"size:255"`
+}
+
+func (clickGeoLinkRule) TableName() string { return "link_rules" }
+
+var clickGeoColumns = []string{"Country", "City"}
+
+func init() {
+	Register(Migration{
+		Version: "20261019000012",
+		Name:    "click_geo",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			for _, column := range clickGeoColumns {
+				if m.HasColumn(&clickGeoClick{}, column) {
+					continue
+				}
+				if err := m.AddColumn(&clickGeoClick{}, column); err != nil {
+					return err
+				}
+			}
+			if !m.HasIndex(&clickGeoClick{}, "Country") {
+				if err := m.CreateIndex(&clickGeoClick{}, "Country"); err != nil {
+					return err
+				}
+			}
+			if m.HasColumn(&clickGeoLinkRule{}, "Countries") {
+				return nil
+			}
+			return m.AddColumn(&clickGeoLinkRule{}, "Countries")
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropColumn(&clickGeoLinkRule{}, "Countries"); err != nil {
+				return err
+			}
+			if m.HasIndex(&clickGeoClick{}, "Country") {
+				if err := m.DropIndex(&clickGeoClick{}, "Country"); err != nil {
+					return err
+				}
+			}
+			for _, column := range clickGeoColumns {
+				if err := m.DropColumn(&clickGeoClick{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type linkVariantsLinkVariant struct {
+	ID       uint `gorm:"primaryKey"`
+	LinkID   uint `gorm:"index"`
+	Position int
+
+	Name        string `gorm:"size:50"`
+	Destination string `gorm:"not null"`
+	Weight      int
+
+	CreatedAt time.Time
+}
+
+func (linkVariantsLinkVariant) TableName() string { return "link_variants" }
+
+type linkVariantsLink struct {
+	StickyVariants bool `gorm:"default:false"`
+}
+
+func (linkVariantsLink) TableName() string { return "links" }
+
+type linkVariantsClick struct {
+	VariantID *uint
+}
+
+func (linkVariantsClick) TableName() string { return "clicks" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000013",
+		Name:    "link_variants",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.CreateTable(&linkVariantsLinkVariant{}); err != nil {
+				return err
+			}
+			if !m.HasColumn(&linkVariantsLink{}, "StickyVariants") {
+				if err := m.AddColumn(&linkVariantsLink{}, "StickyVariants"); err != nil {
+					return err
+				}
+			}
+			if m.HasColumn(&linkVariantsClick{}, "VariantID") {
+				return nil
+			}
+			return m.AddColumn(&linkVariantsClick{}, "VariantID")
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropColumn(&linkVariantsClick{}, "VariantID"); err != nil {
+				return err
+			}
+			if err := m.DropColumn(&linkVariantsLink{}, "StickyVariants"); err != nil {
+				return err
+			}
+			return m.DropTable(&linkVariantsLinkVariant{})
+		},
+	})
+}
@@ -0,0 +1,82 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type conversionsConversion struct {
+	ID      uint   `gorm:"primaryKey"`
+	ClickID uint   `gorm:"uniqueIndex:idx_conversions_click_goal"`
+	LinkID  uint   `gorm:"index"`
+	Goal    string `gorm:"size:100;uniqueIndex:idx_conversions_click_goal"`
+
+	ClickedAt        time.Time
+	SecondsToConvert int64
+	CreatedAt        time.Time
+}
+
+func (conversionsConversion) TableName() string { return "conversions" }
+
+type conversionsClick struct {
+	Token *string `gorm:"size:32;uniqueIndex"`
+}
+
+func (conversionsClick) TableName() string { return "clicks" }
+
+type conversionsLink struct {
+	TrackConversions bool   `gorm:"default:false"`
+	ConversionGoal   string `gorm:"size:100"`
+}
+
+func (conversionsLink) TableName() string { return "links" }
+
+var conversionsLinkColumns = []string{"TrackConversions", "ConversionGoal"}
+
+func init() {
+	Register(Migration{
+		Version: "20261019000014",
+		Name:    "conversions",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.CreateTable(&conversionsConversion{}); err != nil {
+				return err
+			}
+			for _, column := range conversionsLinkColumns {
+				if m.HasColumn(&conversionsLink{}, column) {
+					continue
+				}
+				if err := m.AddColumn(&conversionsLink{}, column); err != nil {
+					return err
+				}
+			}
+			if !m.HasColumn(&conversionsClick{}, "Token") {
+				if err := m.AddColumn(&conversionsClick{}, "Token"); err != nil {
+					return err
+				}
+			}
+			if !m.HasIndex(&conversionsClick{}, "Token") {
+				return m.CreateIndex(&conversionsClick{}, "Token")
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if m.HasIndex(&conversionsClick{}, "Token") {
+				if err := m.DropIndex(&conversionsClick{}, "Token"); err != nil {
+					return err
+				}
+			}
+			if err := m.DropColumn(&conversionsClick{}, "Token"); err != nil {
+				return err
+			}
+			for _, column := range conversionsLinkColumns {
+				if err := m.DropColumn(&conversionsLink{}, column); err != nil {
+					return err
+				}
+			}
+			return m.DropTable(&conversionsConversion{})
+		},
+	})
+}
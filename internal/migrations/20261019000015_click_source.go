@@ -0,0 +1,37 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type clickSourceClick struct {
+	Source string `gorm:"size:20;index"`
+}
+
+func (clickSourceClick) TableName() string { return "clicks" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000015",
+		Name:    "click_source",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if !m.HasColumn(&clickSourceClick{}, "Source") {
+				if err := m.AddColumn(&clickSourceClick{}, "Source"); err != nil {
+					return err
+				}
+			}
+			if !m.HasIndex(&clickSourceClick{}, "Source") {
+				return m.CreateIndex(&clickSourceClick{}, "Source")
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if m.HasIndex(&clickSourceClick{}, "Source") {
+				if err := m.DropIndex(&clickSourceClick{}, "Source"); err != nil {
+					return err
+				}
+			}
+			return m.DropColumn(&clickSourceClick{}, "Source")
+		},
+	})
+}
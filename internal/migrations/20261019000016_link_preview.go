@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type linkPreviewLink struct {
+	OGTitle              string `gorm:"size:200"`
+	OGDescription        string `gorm:"size:500"`
+	OGImage              string `gorm:"size:2048"`
+	OGFetchedTitle       string `gorm:"size:200"`
+	OGFetchedDescription string `gorm:"size:500"`
+	OGFetchedImage       string `gorm:"size:2048"`
+	PreviewFetchedAt     *time.Time
+}
+
+func (linkPreviewLink) TableName() string { return "links" }
+
+var linkPreviewColumns = []string{
+	"OGTitle", "OGDescription", "OGImage",
+	"OGFetchedTitle", "OGFetchedDescription", "OGFetchedImage", "PreviewFetchedAt",
+}
+
+func init() {
+	Register(Migration{
+		Version: "20261019000016",
+		Name:    "link_preview",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			for _, column := range linkPreviewColumns {
+				if m.HasColumn(&linkPreviewLink{}, column) {
+					continue
+				}
+				if err := m.AddColumn(&linkPreviewLink{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			for _, column := range linkPreviewColumns {
+				if err := m.DropColumn(&linkPreviewLink{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type linkPasswordLink struct {
+	PasswordHash string `gorm:"size:100"`
+}
+
+func (linkPasswordLink) TableName() string { return "links" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000017",
+		Name:    "link_password",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if m.HasColumn(&linkPasswordLink{}, "PasswordHash") {
+				return nil
+			}
+			return m.AddColumn(&linkPasswordLink{}, "PasswordHash")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&linkPasswordLink{}, "PasswordHash")
+		},
+	})
+}
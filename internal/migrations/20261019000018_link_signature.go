@@ -0,0 +1,26 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type linkSignatureLink struct {
+	RequireSignature bool `gorm:"default:false"`
+}
+
+func (linkSignatureLink) TableName() string { return "links" }
+
+func init() {
+	Register(Migration{
+		Version: "20261019000018",
+		Name:    "link_signature",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if m.HasColumn(&linkSignatureLink{}, "RequireSignature") {
+				return nil
+			}
+			return m.AddColumn(&linkSignatureLink{}, "RequireSignature")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&linkSignatureLink{}, "RequireSignature")
+		},
+	})
+}
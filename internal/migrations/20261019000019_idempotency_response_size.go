@@ -0,0 +1,24 @@
+package migrations
+
+import "gorm.io/gorm"
+
+func init() {
+	Register(Migration{
+		Version: "20261019000019",
+		Name:    "idempotency_response_size",
+		// Sous MySQL, TEXT est limité à 64 Ko : trop peu pour la réponse d'un lot de liens.
+		// PostgreSQL et SQLite n'imposent pas de limite à TEXT.
+		Up: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != "mysql" {
+				return nil
+			}
+			return tx.Exec("ALTER TABLE idempotency_keys MODIFY response_body MEDIUMTEXT").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != "mysql" {
+				return nil
+			}
+			return tx.Exec("ALTER TABLE idempotency_keys MODIFY response_body TEXT").Error
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var nameSanitizer = regexp.MustCompile(`[^a-z0-9]+`)
+
+var migrationTemplate = template.Must(template.New("migration").Parse(`package migrations
+
+import "gorm.io/gorm"
+
+func init() {
+	Register(Migration{
+		Version: "{{ .Version }}",
+		Name:    "{{ .Name }}",
+		Up: func(tx *gorm.DB) error {
+			// TODO : décrire l'évolution du schéma (et la reprise de données éventuelle).
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			// TODO : annuler exactement ce que fait Up.
+			return nil
+		},
+	})
+}
+`))
+
+// Create génère le squelette d'une nouvelle migration dans dir et retourne le chemin du fichier.
+// La migration n'est prise en compte qu'après recompilation du binaire.
+func Create(dir, name string, now time.Time) (string, error) {
+	slug := strings.Trim(nameSanitizer.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return "", fmt.Errorf("invalid migration name %q", name)
+	}
+
+	version := now.UTC().Format("20060102150405")
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s.go", version, slug))
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := migrationTemplate.Execute(file, struct{ Version, Name string }{version, slug}); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,183 @@
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration est une évolution versionnée du schéma, écrite en Go pour pouvoir
+// inclure des reprises de données en plus des changements de structure.
+// Chaque migration s'enregistre dans un init() de son propre fichier.
+type Migration struct {
+	Version string // Horodatage AAAAMMJJHHMMSS, détermine l'ordre d'application
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration est une ligne de la table 'schema_migrations' :
+// une migration appliquée à la base.
+type SchemaMigration struct {
+	Version   string `gorm:"primaryKey;size:14"`
+	Name      string `gorm:"size:255"`
+	AppliedAt time.Time
+}
+
+// TableName force le nom de la table de suivi.
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status décrit l'état d'une migration pour la commande 'migrate status'.
+type Status struct {
+	Version   string
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Unknown   bool // Appliquée en base mais absente de ce binaire
+}
+
+var registry = map[string]Migration{}
+
+// Register ajoute une migration au registre. Elle panique si la version est déjà prise,
+// ce qui signale un conflit dès le démarrage plutôt qu'à l'application.
+func Register(m Migration) {
+	if _, exists := registry[m.Version]; exists {
+		panic(fmt.Sprintf("migration %s registered twice", m.Version))
+	}
+	if m.Up == nil || m.Down == nil {
+		panic(fmt.Sprintf("migration %s must define Up and Down", m.Version))
+	}
+	registry[m.Version] = m
+}
+
+// All retourne les migrations connues, de la plus ancienne à la plus récente.
+func All() []Migration {
+	all := make([]Migration, 0, len(registry))
+	for _, m := range registry {
+		all = append(all, m)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	return all
+}
+
+// applied retourne les migrations enregistrées en base, indexées par version.
+func applied(db *gorm.DB) (map[string]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to prepare schema_migrations table: %w", err)
+	}
+
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	done := make(map[string]SchemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+// Pending retourne les migrations pas encore appliquées, dans l'ordre.
+func Pending(db *gorm.DB) ([]Migration, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range All() {
+		if _, ok := done[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up applique toutes les migrations en attente, chacune dans sa transaction.
+// Elle s'arrête à la première erreur et retourne les migrations appliquées jusque-là.
+func Up(db *gorm.DB) ([]Migration, error) {
+	pending, err := Pending(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range pending {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s_%s failed: %w", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down annule les 'steps' dernières migrations appliquées, de la plus récente à la plus ancienne.
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	all := All()
+	var reverted []Migration
+	for i := len(all) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := all[i]
+		if _, ok := done[m.Version]; !ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{Version: m.Version}).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("rollback of %s_%s failed: %w", m.Version, m.Name, err)
+		}
+		reverted = append(reverted, m)
+	}
+	return reverted, nil
+}
+
+// StatusOf retourne l'état de chaque migration connue, plus celles appliquées
+// en base par un binaire plus récent.
+func StatusOf(db *gorm.DB) ([]Status, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, m := range All() {
+		row, ok := done[m.Version]
+		statuses = append(statuses, Status{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   ok,
+			AppliedAt: row.AppliedAt,
+		})
+		delete(done, m.Version)
+	}
+	for _, row := range done {
+		statuses = append(statuses, Status{
+			Version:   row.Version,
+			Name:      row.Name,
+			Applied:   true,
+			AppliedAt: row.AppliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
@@ -0,0 +1,158 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/database/dbtest"
+	"github.com/axellelanca/urlshortener/internal/migrations"
+	"gorm.io/gorm"
+)
+
+// schemaTables sont les tables créées par les migrations.
+var schemaTables = []string{
+	"links", "clicks", "link_checks", "link_click_summaries", "idempotency_keys",
+	"link_audits", "link_reports", "link_rules", "link_variants", "conversions",
+}
+
+// migrated prépare une base à jour ; les bases partagées (PostgreSQL, MySQL) sont
+// remises à vide avant et après le test.
+func migrated(t *testing.T, target dbtest.Target) *gorm.DB {
+	t.Helper()
+	db := dbtest.Open(t, target)
+	if _, err := migrations.Down(db, len(migrations.All())); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := migrations.Down(db, len(migrations.All())); err != nil {
+			t.Errorf("cleanup: %v", err)
+		}
+	})
+	return db
+}
+
+func assertSchema(t *testing.T, db *gorm.DB, present bool) {
+	t.Helper()
+	for _, table := range schemaTables {
+		if got := db.Migrator().HasTable(table); got != present {
+			t.Errorf("HasTable(%q) = %v, want %v", table, got, present)
+		}
+	}
+	if !present {
+		return
+	}
+	// Colonnes ajoutées par des migrations successives sur les tables existantes.
+	columns := map[string][]string{
+		"links":  {"destination_hash", "disabled", "flagged", "utm_campaign", "sticky_variants", "og_title", "password_hash", "require_signature"},
+		"clicks": {"rule_id", "country", "city", "variant_id", "source", "token"},
+	}
+	for table, names := range columns {
+		for _, name := range names {
+			if !db.Migrator().HasColumn(table, name) {
+				t.Errorf("HasColumn(%q, %q) = false", table, name)
+			}
+		}
+	}
+}
+
+func TestUpDownUp(t *testing.T) {
+	all := migrations.All()
+	for _, target := range dbtest.Targets(t) {
+		t.Run(target.Name, func(t *testing.T) {
+			db := migrated(t, target)
+
+			done, err := migrations.Up(db)
+			if err != nil {
+				t.Fatalf("Up: %v", err)
+			}
+			if len(done) != len(all) {
+				t.Fatalf("Up applied %d migrations, want %d", len(done), len(all))
+			}
+			assertSchema(t, db, true)
+
+			pending, err := migrations.Pending(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pending) != 0 {
+				t.Fatalf("%d migrations still pending after Up", len(pending))
+			}
+			if again, err := migrations.Up(db); err != nil || len(again) != 0 {
+				t.Fatalf("second Up = %d migrations, %v; want none", len(again), err)
+			}
+
+			reverted, err := migrations.Down(db, len(all))
+			if err != nil {
+				t.Fatalf("Down: %v", err)
+			}
+			if len(reverted) != len(all) {
+				t.Fatalf("Down reverted %d migrations, want %d", len(reverted), len(all))
+			}
+			assertSchema(t, db, false)
+
+			if done, err = migrations.Up(db); err != nil {
+				t.Fatalf("Up after Down: %v", err)
+			}
+			if len(done) != len(all) {
+				t.Fatalf("Up after Down applied %d migrations, want %d", len(done), len(all))
+			}
+			assertSchema(t, db, true)
+		})
+	}
+}
+
+// Chaque migration doit pouvoir être annulée puis réappliquée seule, sur un schéma complet.
+func TestEachMigrationReversible(t *testing.T) {
+	all := migrations.All()
+	for _, target := range dbtest.Targets(t) {
+		t.Run(target.Name, func(t *testing.T) {
+			db := migrated(t, target)
+			if _, err := migrations.Up(db); err != nil {
+				t.Fatalf("Up: %v", err)
+			}
+
+			for i := len(all) - 1; i >= 0; i-- {
+				reverted, err := migrations.Down(db, len(all)-i)
+				if err != nil {
+					t.Fatalf("Down to %s: %v", all[i].Version, err)
+				}
+				if len(reverted) != len(all)-i {
+					t.Fatalf("Down to %s reverted %d migrations", all[i].Version, len(reverted))
+				}
+				done, err := migrations.Up(db)
+				if err != nil {
+					t.Fatalf("Up from %s: %v", all[i].Version, err)
+				}
+				if len(done) != len(all)-i {
+					t.Fatalf("Up from %s applied %d migrations, want %d", all[i].Version, len(done), len(all)-i)
+				}
+			}
+			assertSchema(t, db, true)
+		})
+	}
+}
+
+func TestStatusOf(t *testing.T) {
+	target := dbtest.Targets(t)[0]
+	db := migrated(t, target)
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrations.Down(db, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := migrations.StatusOf(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := migrations.All()
+	if len(statuses) != len(all) {
+		t.Fatalf("StatusOf returned %d entries, want %d", len(statuses), len(all))
+	}
+	for i, status := range statuses {
+		wantApplied := i < len(all)-1
+		if status.Version != all[i].Version || status.Applied != wantApplied || status.Unknown {
+			t.Errorf("status[%d] = %+v, want version %s applied=%v", i, status, all[i].Version, wantApplied)
+		}
+	}
+}
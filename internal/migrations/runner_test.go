@@ -0,0 +1,292 @@
+package migrations
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// withRegistry remplace le registre par les migrations données le temps du test :
+// le fonctionnement du runner est vérifié indépendamment des migrations du projet.
+func withRegistry(t *testing.T, ms ...Migration) {
+	t.Helper()
+	saved := registry
+	registry = map[string]Migration{}
+	t.Cleanup(func() { registry = saved })
+	for _, m := range ms {
+		Register(m)
+	}
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrations.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// journal consigne l'ordre dans lequel le runner appelle Up et Down.
+type journal []string
+
+// table retourne une migration qui crée (Up) ou supprime (Down) la table name.
+func (j *journal) table(version, name string) Migration {
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up: func(tx *gorm.DB) error {
+			*j = append(*j, "up "+version)
+			return tx.Exec("CREATE TABLE " + name + " (id INTEGER PRIMARY KEY)").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			*j = append(*j, "down "+version)
+			return tx.Exec("DROP TABLE " + name).Error
+		},
+	}
+}
+
+func versions(ms []Migration) []string {
+	out := make([]string, len(ms))
+	for i, m := range ms {
+		out[i] = m.Version
+	}
+	return out
+}
+
+func TestAllSortsByVersion(t *testing.T) {
+	var j journal
+	withRegistry(t,
+		j.table("20260103000000", "c"),
+		j.table("20260101000000", "a"),
+		j.table("20260102000000", "b"),
+	)
+
+	want := []string{"20260101000000", "20260102000000", "20260103000000"}
+	if got := versions(All()); !reflect.DeepEqual(got, want) {
+		t.Fatalf("All() = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	var j journal
+	noop := func(*gorm.DB) error { return nil }
+	cases := map[string]Migration{
+		"duplicate version": j.table("20260101000000", "again"),
+		"missing Up":        {Version: "20260102000000", Name: "no_up", Down: noop},
+		"missing Down":      {Version: "20260103000000", Name: "no_down", Up: noop},
+	}
+	for name, m := range cases {
+		t.Run(name, func(t *testing.T) {
+			withRegistry(t, j.table("20260101000000", "a"))
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("Register(%s) did not panic", m.Version)
+				}
+			}()
+			Register(m)
+		})
+	}
+}
+
+func TestUpAppliesPendingInOrderOnce(t *testing.T) {
+	var j journal
+	withRegistry(t,
+		j.table("20260102000000", "b"),
+		j.table("20260101000000", "a"),
+	)
+	db := openTestDB(t)
+
+	done, err := Up(db)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if got, want := versions(done), []string{"20260101000000", "20260102000000"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Up applied %v, want %v", got, want)
+	}
+
+	// Une migration ajoutée ensuite est la seule appliquée au passage suivant.
+	Register(j.table("20260103000000", "c"))
+	pending, err := Pending(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := versions(pending), []string{"20260103000000"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Pending = %v, want %v", got, want)
+	}
+	if done, err = Up(db); err != nil || len(done) != 1 {
+		t.Fatalf("second Up = %v, %v; want only 20260103000000", versions(done), err)
+	}
+	if done, err = Up(db); err != nil || len(done) != 0 {
+		t.Fatalf("third Up = %v, %v; want none", versions(done), err)
+	}
+
+	want := journal{"up 20260101000000", "up 20260102000000", "up 20260103000000"}
+	if !reflect.DeepEqual(j, want) {
+		t.Fatalf("calls = %v, want %v", j, want)
+	}
+}
+
+func TestUpStopsAtFirstFailure(t *testing.T) {
+	var j journal
+	failing := j.table("20260102000000", "b")
+	failing.Up = func(tx *gorm.DB) error {
+		j = append(j, "up 20260102000000")
+		if err := tx.Exec("CREATE TABLE b (id INTEGER PRIMARY KEY)").Error; err != nil {
+			return err
+		}
+		return errors.New("boom")
+	}
+	withRegistry(t,
+		j.table("20260101000000", "a"),
+		failing,
+		j.table("20260103000000", "c"),
+	)
+	db := openTestDB(t)
+
+	done, err := Up(db)
+	if err == nil || !strings.Contains(err.Error(), "20260102000000_b") {
+		t.Fatalf("Up error = %v, want failure of 20260102000000_b", err)
+	}
+	if got, want := versions(done), []string{"20260101000000"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Up applied %v before failing, want %v", got, want)
+	}
+	// La transaction de la migration en échec est annulée : ni table, ni ligne de suivi.
+	if db.Migrator().HasTable("b") {
+		t.Error("table of the failed migration was kept")
+	}
+	if db.Migrator().HasTable("c") {
+		t.Error("migration after the failure was applied")
+	}
+	pending, err := Pending(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := versions(pending), []string{"20260102000000", "20260103000000"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Pending after failure = %v, want %v", got, want)
+	}
+}
+
+func TestDownRevertsLatestFirst(t *testing.T) {
+	var j journal
+	withRegistry(t,
+		j.table("20260101000000", "a"),
+		j.table("20260102000000", "b"),
+		j.table("20260103000000", "c"),
+	)
+	db := openTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := Down(db, 2)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if got, want := versions(reverted), []string{"20260103000000", "20260102000000"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Down reverted %v, want %v", got, want)
+	}
+	if !db.Migrator().HasTable("a") || db.Migrator().HasTable("b") || db.Migrator().HasTable("c") {
+		t.Error("Down(2) must drop only the two latest tables")
+	}
+
+	// Au-delà des migrations appliquées, Down s'arrête sans erreur.
+	if reverted, err = Down(db, 10); err != nil || len(reverted) != 1 {
+		t.Fatalf("Down(10) = %v, %v; want only 20260101000000", versions(reverted), err)
+	}
+	if reverted, err = Down(db, 1); err != nil || len(reverted) != 0 {
+		t.Fatalf("Down on empty schema = %v, %v; want none", versions(reverted), err)
+	}
+}
+
+func TestStatusOfReportsUnknownMigrations(t *testing.T) {
+	var j journal
+	withRegistry(t,
+		j.table("20260101000000", "a"),
+		j.table("20260103000000", "c"),
+	)
+	db := openTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Down(db, 1); err != nil {
+		t.Fatal(err)
+	}
+	// Migration appliquée par un binaire plus récent, inconnue de celui-ci.
+	if err := db.Create(&SchemaMigration{Version: "20260102000000", Name: "newer", AppliedAt: time.Now()}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := StatusOf(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		version          string
+		applied, unknown bool
+	}{
+		{"20260101000000", true, false},
+		{"20260102000000", true, true},
+		{"20260103000000", false, false},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("StatusOf returned %d entries, want %d", len(statuses), len(want))
+	}
+	for i, w := range want {
+		s := statuses[i]
+		if s.Version != w.version || s.Applied != w.applied || s.Unknown != w.unknown {
+			t.Errorf("status[%d] = %+v, want version %s applied=%v unknown=%v", i, s, w.version, w.applied, w.unknown)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 19, 12, 30, 45, 0, time.FixedZone("CEST", 2*3600))
+
+	path, err := Create(dir, "  Add Link Tags! ", now)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// La version est l'horodatage UTC ; le nom est réduit à [a-z0-9_].
+	if want := filepath.Join(dir, "20261019103045_add_link_tags.go"); path != want {
+		t.Fatalf("Create path = %q, want %q", path, want)
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), path, source, 0); err != nil {
+		t.Fatalf("generated migration does not parse: %v", err)
+	}
+	for _, want := range []string{`Version: "20261019103045"`, `Name:    "add_link_tags"`} {
+		if !strings.Contains(string(source), want) {
+			t.Errorf("generated migration lacks %s", want)
+		}
+	}
+
+	// Un fichier existant n'est jamais écrasé.
+	if _, err := Create(dir, "add link tags", now); err == nil {
+		t.Error("Create overwrote an existing migration")
+	}
+	if _, err := Create(dir, "!!!", now); err == nil {
+		t.Error("Create accepted a name without letters or digits")
+	}
+}
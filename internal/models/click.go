@@ -2,6 +2,9 @@ package models
 
 import "time"
 
+// ClickSourceQR marque les clics issus d'un QR code (paramètre ?src=qr de l'URL encodée).
+const ClickSourceQR = "qr"
+
 // Click représente un événement de clic sur un lien raccourci.
 // GORM utilisera ces tags pour créer la table 'clicks'.
 type Click struct {
@@ -11,6 +14,12 @@ type Click struct {
 	Timestamp time.Time // Horodatage précis du clic
 	UserAgent string    `gorm:"size:255"` // User-Agent de l'utilisateur qui a cliqué (informations sur le navigateur/OS)
 	IPAddress string    `gorm:"size:50"`  // Adresse IP de l'utilisateur
+	RuleID    *uint     // Règle de ciblage appliquée (nil = URL longue)
+	VariantID *uint     // Variante A/B servie, nil si le lien n'en a pas
+	Country   string    `gorm:"size:2;index"`        // Pays ISO 3166-1 alpha-2 déduit de l'IP (base GeoIP), vide si inconnu
+	City      string    `gorm:"size:100"`            // Ville déduite de l'IP, vide si inconnue ou base sans villes
+	Token     *string   `gorm:"size:32;uniqueIndex"` // Jeton transmis à la destination (suivi des conversions), nil sinon
+	Source    string    `gorm:"size:20;index"`       // Origine du clic (ClickSourceQR), vide = accès direct
 }
 
 //  créer la struct pour ClickEvent
@@ -23,4 +32,8 @@ type ClickEvent struct {
 	Timestamp time.Time
 	UserAgent string
 	IPAddress string
+	RuleID    *uint  // Règle de ciblage appliquée, nil si aucune
+	VariantID *uint  // Variante A/B servie, nil si aucune
+	Token     string // Jeton de clic ajouté à la destination, vide si le lien ne suit pas les conversions
+	Source    string // Origine du clic (ClickSourceQR), vide = accès direct
 }
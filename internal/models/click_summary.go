@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LinkClickSummary conserve le nombre de clics historiques d'un lien importé depuis
+// un autre raccourcisseur : seuls les totaux sont connus, pas les clics individuels.
+// Ces totaux s'ajoutent aux clics enregistrés dans les statistiques.
+type LinkClickSummary struct {
+	ID         uint   `gorm:"primaryKey"`
+	LinkID     uint   `gorm:"uniqueIndex"`
+	Source     string `gorm:"size:20"` // Outil d'origine : bitly, yourls, kutt, shlink ou import
+	Clicks     int
+	ImportedAt time.Time
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// DefaultConversionGoal est l'objectif enregistré quand ni le signalement ni le lien n'en précisent.
+const DefaultConversionGoal = "conversion"
+
+// Conversion est un objectif atteint (inscription, achat, ...) par un visiteur après un clic,
+// signalé par le site de destination avec le jeton de ce clic. Un clic ne convertit qu'une
+// fois par objectif.
+type Conversion struct {
+	ID      uint   `gorm:"primaryKey"`
+	ClickID uint   `gorm:"uniqueIndex:idx_conversions_click_goal"`
+	LinkID  uint   `gorm:"index"`
+	Goal    string `gorm:"size:100;uniqueIndex:idx_conversions_click_goal"`
+
+	ClickedAt        time.Time // Horodatage du clic d'origine
+	SecondsToConvert int64     // Délai entre le clic et la conversion
+	CreatedAt        time.Time
+}
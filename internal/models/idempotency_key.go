@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// IdempotencyKey mémorise la réponse d'une requête portant un en-tête Idempotency-Key,
+// pour la rejouer si le client renvoie la même requête pendant la fenêtre configurée.
+// StatusCode vaut 0 tant que la requête d'origine est en cours de traitement.
+type IdempotencyKey struct {
+	ID           uint   `gorm:"primaryKey"`
+	Key          string `gorm:"column:idempotency_key;size:255;uniqueIndex:idx_idempotency_key_scope"`
+	Scope        string `gorm:"size:100;uniqueIndex:idx_idempotency_key_scope"` // Méthode et route, ex. "POST /api/v1/links"
+	RequestHash  string `gorm:"size:64"`                                        // Empreinte du corps de la requête d'origine
+	StatusCode   int
+	ContentType  string `gorm:"size:100"`
+	ResponseBody string `gorm:"type:text"` // MEDIUMTEXT sous MySQL (16 Mo)
+	CreatedAt    time.Time
+	ExpiresAt    time.Time `gorm:"index"`
+}
@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+	"gorm.io/gorm"
+)
 
 //  : Créer la struct Link
 // Link représente un lien raccourci dans la base de données.
@@ -10,10 +15,169 @@ import "time"
 // LongURL : doit pas être null
 // CreateAt : Horodatage de la créatino du lien
 
+// Politiques appliquées à la redirection lorsque la destination d'un lien
+// est jugée durablement inaccessible par le moniteur.
+const (
+	FailurePolicyKeep        = "keep"        // Continuer à rediriger vers l'URL longue
+	FailurePolicyUnavailable = "unavailable" // Servir une page "destination indisponible" (503)
+	FailurePolicyFallback    = "fallback"    // Rediriger vers FallbackURL
+)
+
 // Link représente un lien raccourci dans la base de données.
 type Link struct {
 	ID        uint      `gorm:"primaryKey"`
 	ShortCode string    `gorm:"size:10;uniqueIndex;not null"`
 	LongURL   string    `gorm:"not null"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	// Politique en cas de destination cassée (voir FailurePolicy*).
+	FailurePolicy string `gorm:"size:20;default:keep"`
+	// Nombre d'échecs consécutifs avant d'appliquer la politique (0 = valeur de la configuration).
+	FailureThreshold int
+	// URL de repli utilisée avec la politique "fallback".
+	FallbackURL string
+
+	// Surveiller le contenu de la destination (empreinte du corps et du titre).
+	ContentMonitoring bool
+
+	// Réglages de surveillance propres au lien.
+	MonitorDisabled        bool // Exclure le lien du moniteur
+	MonitorIntervalMinutes int  // 0 = intervalle global du moniteur
+	MonitorPriority        int  // Les liens de priorité plus élevée sont vérifiés en premier
+
+	// Métadonnées libres fournies à la création (campagne, canal, ...), stockées en JSON.
+	Metadata map[string]string `gorm:"serializer:json"`
+
+	// Empreinte de l'URL longue normalisée (voir urlnorm), pour retrouver un lien existant
+	// vers la même destination. Maintenue automatiquement à chaque enregistrement.
+	DestinationHash string `gorm:"size:64;index"`
+
+	// Lien désactivé (destination listée comme malveillante, ...) : la redirection répond 410.
+	Disabled       bool   `gorm:"index;default:false"`
+	DisabledReason string `gorm:"size:255"`
+	DisabledAt     *time.Time
+
+	// Signalé par assez d'utilisateurs distincts : la redirection passe par une page d'avertissement.
+	Flagged bool `gorm:"default:false"`
+
+	// Toujours afficher une page "vous quittez ce site" avant de rediriger (liens partenaires, ...).
+	Interstitial bool `gorm:"default:false"`
+
+	// Code de redirection (301, 302, 307 ou 308) ; 0 = valeur de la configuration.
+	RedirectType int
+	// Transmettre à la destination les paramètres de requête et les segments de chemin supplémentaires.
+	ForwardQuery bool `gorm:"default:false"`
+	ForwardPath  bool `gorm:"default:false"`
+
+	// Paramètres UTM ajoutés à la destination lors de la redirection (colonnes utm_*).
+	UTM UTMParams `gorm:"embedded;embeddedPrefix:utm_"`
+
+	// Règles de ciblage, par position. Chargées avec le lien sur le chemin de redirection
+	// (GetLinkByShortCode) et enregistrées à sa création.
+	Rules []LinkRule `gorm:"-"`
+
+	// Destinations pondérées d'un test A/B, par position, chargées comme les règles.
+	// StickyVariants : un cookie garde chaque visiteur sur la variante qu'il a reçue.
+	Variants       []LinkVariant `gorm:"-"`
+	StickyVariants bool          `gorm:"default:false"`
+
+	// Suivi des conversions : un jeton de clic est ajouté à la destination, que le site renvoie
+	// quand le visiteur atteint l'objectif. ConversionGoal nomme l'objectif suivi (ex: "inscription").
+	TrackConversions bool   `gorm:"default:false"`
+	ConversionGoal   string `gorm:"size:100"`
+
+	// Aperçu servi aux robots des réseaux sociaux et messageries (balises Open Graph).
+	// Preview est saisi par l'utilisateur ; FetchedPreview est relevé sur la destination
+	// par le moniteur et complète les champs que Preview laisse vides.
+	Preview          LinkPreview `gorm:"embedded;embeddedPrefix:og_"`
+	FetchedPreview   LinkPreview `gorm:"embedded;embeddedPrefix:og_fetched_"`
+	PreviewFetchedAt *time.Time
+
+	// Empreinte bcrypt du mot de passe exigé avant la redirection ; vide = lien public.
+	PasswordHash string `gorm:"size:100"`
+
+	// N'accepter que les URL signées et non expirées (?exp=...&kid=...&sig=...), voir services.URLSigner.
+	RequireSignature bool `gorm:"default:false"`
+}
+
+// Tailles des colonnes og_* (titre, description et image de l'aperçu).
+const (
+	MaxPreviewTitle       = 200
+	MaxPreviewDescription = 500
+	MaxPreviewImage       = 2048
+)
+
+// LinkPreview regroupe les métadonnées Open Graph d'un lien.
+type LinkPreview struct {
+	Title       string `gorm:"size:200"`
+	Description string `gorm:"size:500"`
+	Image       string `gorm:"size:2048"` // URL absolue de l'image
+}
+
+// IsZero indique si aucun champ n'est renseigné.
+func (p LinkPreview) IsZero() bool {
+	return p == LinkPreview{}
+}
+
+// WithDefaults complète les champs vides avec ceux de defaults.
+func (p LinkPreview) WithDefaults(defaults LinkPreview) LinkPreview {
+	if p.Title == "" {
+		p.Title = defaults.Title
+	}
+	if p.Description == "" {
+		p.Description = defaults.Description
+	}
+	if p.Image == "" {
+		p.Image = defaults.Image
+	}
+	return p
+}
+
+// UTMParams regroupe les paramètres de suivi de campagne d'un lien.
+type UTMParams struct {
+	Source   string `gorm:"size:100"`
+	Medium   string `gorm:"size:100"`
+	Campaign string `gorm:"size:100;index"`
+	Term     string `gorm:"size:100"`
+	Content  string `gorm:"size:100"`
+}
+
+// Pairs retourne les paramètres renseignés, dans l'ordre habituel (utm_source, utm_medium, ...).
+func (p UTMParams) Pairs() [][2]string {
+	var pairs [][2]string
+	for _, kv := range [][2]string{
+		{"utm_source", p.Source},
+		{"utm_medium", p.Medium},
+		{"utm_campaign", p.Campaign},
+		{"utm_term", p.Term},
+		{"utm_content", p.Content},
+	} {
+		if kv[1] != "" {
+			pairs = append(pairs, kv)
+		}
+	}
+	return pairs
+}
+
+// WithDefaults complète les champs vides avec ceux de defaults.
+func (p UTMParams) WithDefaults(defaults UTMParams) UTMParams {
+	fill := func(v, d string) string {
+		if v == "" {
+			return d
+		}
+		return v
+	}
+	return UTMParams{
+		Source:   fill(p.Source, defaults.Source),
+		Medium:   fill(p.Medium, defaults.Medium),
+		Campaign: fill(p.Campaign, defaults.Campaign),
+		Term:     fill(p.Term, defaults.Term),
+		Content:  fill(p.Content, defaults.Content),
+	}
+}
+
+// BeforeSave recalcule l'empreinte de la destination avant chaque création ou mise à jour.
+func (l *Link) BeforeSave(tx *gorm.DB) error {
+	l.DestinationHash = urlnorm.Hash(l.LongURL)
+	return nil
 }
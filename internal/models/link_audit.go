@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Actions enregistrées dans le journal d'audit des liens.
+const (
+	AuditActionDisabled  = "disabled"  // Lien désactivé
+	AuditActionFlagged   = "flagged"   // Seuil de signalements atteint : page d'avertissement
+	AuditActionDismissed = "dismissed" // Signalements classés sans suite
+	AuditActionDeleted   = "deleted"   // Lien supprimé par un modérateur
+)
+
+// Auteurs des actions automatiques.
+const (
+	AuditActorMonitor = "monitor" // Passage du moniteur (listes de menaces)
+	AuditActorReports = "reports" // Seuil de signalements
+)
+
+// LinkAudit est une entrée du journal des actions de modération sur un lien.
+// Le code court est recopié pour que l'entrée reste lisible après la suppression du lien.
+type LinkAudit struct {
+	ID        uint      `gorm:"primaryKey"`
+	LinkID    uint      `gorm:"index"`
+	ShortCode string    `gorm:"size:10"`
+	Action    string    `gorm:"size:30"`
+	Actor     string    `gorm:"size:100"`
+	Reason    string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
+}
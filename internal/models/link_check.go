@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// LinkCheck est le résultat d'une vérification du moniteur sur un lien dont le contenu est surveillé.
+// Il conserve l'empreinte de la page pour détecter un remplacement ou une défiguration.
+type LinkCheck struct {
+	ID         uint      `gorm:"primaryKey"`
+	LinkID     uint      `gorm:"index"`
+	CheckedAt  time.Time `gorm:"index"`
+	State      string    `gorm:"size:20"`
+	StatusCode int
+
+	ContentHash string `gorm:"size:64"` // SHA-256 du corps normalisé
+	SimHash     string `gorm:"size:16"` // Empreinte de similarité (64 bits, hexadécimal)
+	Title       string `gorm:"size:255"`
+
+	// Renseignés quand l'empreinte a changé au-delà du seuil configuré.
+	ContentChanged bool   `gorm:"index"`
+	PreviousTitle  string `gorm:"size:255"`
+	Difference     int    // Pourcentage de bits différents entre les deux SimHash
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// États d'un signalement.
+const (
+	ReportStatusOpen      = "open"      // En attente de modération
+	ReportStatusDismissed = "dismissed" // Classé sans suite
+	ReportStatusActioned  = "actioned"  // Lien désactivé ou supprimé
+)
+
+// LinkReport est un signalement d'abus déposé sur un lien.
+type LinkReport struct {
+	ID         uint      `gorm:"primaryKey"`
+	LinkID     uint      `gorm:"index"`
+	Reason     string    `gorm:"type:text"`
+	ReporterIP string    `gorm:"size:45"`
+	Status     string    `gorm:"size:20;index;default:open"`
+	CreatedAt  time.Time `gorm:"index"`
+
+	ResolvedAt *time.Time
+	ResolvedBy string `gorm:"size:100"`
+}
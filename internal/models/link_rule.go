@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Systèmes d'exploitation reconnus dans le User-Agent.
+const (
+	OSIOS      = "ios"
+	OSAndroid  = "android"
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSLinux    = "linux"
+	OSChromeOS = "chromeos"
+)
+
+// Types d'appareils reconnus dans le User-Agent.
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+)
+
+// LinkRule est une règle de ciblage d'un lien : quand toutes ses conditions renseignées
+// correspondent à la requête, la redirection se fait vers Destination au lieu de LongURL.
+// Les règles sont évaluées par Position croissante ; la première qui correspond l'emporte.
+type LinkRule struct {
+	ID       uint `gorm:"primaryKey"`
+	LinkID   uint `gorm:"index"`
+	Position int
+
+	OS        string `gorm:"size:20"`  // Voir OS* ; vide = tous
+	Device    string `gorm:"size:20"`  // Voir Device* ; vide = tous
+	Languages string `gorm:"size:255"` // Langues préférées acceptées, séparées par des virgules (ex: "fr,de-ch")
+	Countries string `gorm:"size:255"` // Pays du visiteur (codes ISO, base GeoIP), séparés par des virgules (ex: "FR,BE")
+	TimeFrom  string `gorm:"size:5"`   // Début de la plage horaire "HH:MM" (incluse)
+	TimeTo    string `gorm:"size:5"`   // Fin de la plage horaire "HH:MM" (exclue) ; avant TimeFrom = passe minuit
+	Timezone  string `gorm:"size:64"`  // Fuseau de la plage horaire (ex: Europe/Paris), vide = UTC
+
+	Destination string `gorm:"not null"`
+	CreatedAt   time.Time
+}
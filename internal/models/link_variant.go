@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// LinkVariant est une destination d'un test A/B : chaque redirection choisit une variante
+// au hasard, proportionnellement à son poids (Weight) parmi celles du lien.
+type LinkVariant struct {
+	ID       uint `gorm:"primaryKey"`
+	LinkID   uint `gorm:"index"`
+	Position int
+
+	Name        string `gorm:"size:50"` // Nom affiché dans les statistiques (ex: "A", "nouvelle-page")
+	Destination string `gorm:"not null"`
+	Weight      int    // Part relative du trafic (ex: 70 et 30)
+
+	CreatedAt time.Time
+}
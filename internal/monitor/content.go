@@ -0,0 +1,138 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"math/bits"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	shingleSize     = 3   // Nombre de mots par fragment pour le SimHash
+	maxTitleLength  = 255 // Taille de la colonne Title de LinkCheck
+	simHashBitCount = 64
+)
+
+var (
+	titlePattern  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	noisePattern  = regexp.MustCompile(`(?is)<script[^>]*>.*?</script>|<style[^>]*>.*?</style>|<noscript[^>]*>.*?</noscript>|<!--.*?-->`)
+	tagPattern    = regexp.MustCompile(`(?s)<[^>]*>`)
+	spacesPattern = regexp.MustCompile(`\s+`)
+)
+
+// ContentFingerprint est l'empreinte du contenu d'une page à un instant donné.
+type ContentFingerprint struct {
+	Hash    string `json:"hash"`            // SHA-256 du texte normalisé
+	SimHash uint64 `json:"sim_hash,string"` // Empreinte de similarité
+	Title   string `json:"title"`           // Titre de la page
+}
+
+// ContentChange décrit le dernier changement de contenu détecté sur un lien.
+type ContentChange struct {
+	DetectedAt    time.Time `json:"detected_at"`
+	PreviousTitle string    `json:"previous_title"`
+	Title         string    `json:"title"`
+	Difference    int       `json:"difference_percent"`
+}
+
+// ContentReport est la partie "contenu" de l'état de santé d'un lien.
+type ContentReport struct {
+	Current    *ContentFingerprint `json:"current,omitempty"`
+	LastChange *ContentChange      `json:"last_change,omitempty"`
+}
+
+// fingerprintContent calcule l'empreinte d'un document HTML.
+// Le texte est normalisé (scripts, styles, balises et espaces retirés, minuscules)
+// pour ignorer les variations sans importance comme les jetons générés à chaque affichage.
+func fingerprintContent(body []byte) ContentFingerprint {
+	raw := string(body)
+
+	title := ""
+	if match := titlePattern.FindStringSubmatch(raw); match != nil {
+		title = strings.TrimSpace(spacesPattern.ReplaceAllString(html.UnescapeString(match[1]), " "))
+		if runes := []rune(title); len(runes) > maxTitleLength {
+			title = string(runes[:maxTitleLength])
+		}
+	}
+
+	text := noisePattern.ReplaceAllString(raw, " ")
+	text = tagPattern.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	text = strings.ToLower(strings.TrimSpace(spacesPattern.ReplaceAllString(text, " ")))
+
+	sum := sha256.Sum256([]byte(text))
+	return ContentFingerprint{
+		Hash:    hex.EncodeToString(sum[:]),
+		SimHash: simHash(strings.Fields(text)),
+		Title:   title,
+	}
+}
+
+// simHash calcule une empreinte de 64 bits où des textes proches donnent des empreintes
+// proches (au sens de la distance de Hamming).
+func simHash(words []string) uint64 {
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	add := func(fragment string) {
+		h := fnv.New64a()
+		h.Write([]byte(fragment))
+		v := h.Sum64()
+		for i := 0; i < simHashBitCount; i++ {
+			if v&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	if len(words) < shingleSize {
+		add(strings.Join(words, " "))
+	} else {
+		for i := 0; i+shingleSize <= len(words); i++ {
+			add(strings.Join(words[i:i+shingleSize], " "))
+		}
+	}
+
+	var result uint64
+	for i, w := range weights {
+		if w > 0 {
+			result |= 1 << uint(i)
+		}
+	}
+	return result
+}
+
+// contentDifference retourne le pourcentage de bits différents entre deux empreintes.
+// Deux contenus identiques donnent 0.
+func contentDifference(previous, current ContentFingerprint) int {
+	if previous.Hash == current.Hash {
+		return 0
+	}
+	distance := bits.OnesCount64(previous.SimHash ^ current.SimHash)
+	diff := distance * 100 / simHashBitCount
+	if diff == 0 {
+		// Le texte a changé mais pas assez pour déplacer le SimHash.
+		diff = 1
+	}
+	return diff
+}
+
+// formatSimHash et parseSimHash convertissent un SimHash pour son stockage en base.
+func formatSimHash(v uint64) string {
+	return fmt.Sprintf("%016x", v)
+}
+
+func parseSimHash(s string) uint64 {
+	v, _ := strconv.ParseUint(s, 16, 64)
+	return v
+}
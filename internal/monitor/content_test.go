@@ -0,0 +1,35 @@
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+func TestContentMonitoringDoesNotReadPrivatePages(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Console d'administration</title></head></html>"))
+	}))
+	defer internal.Close()
+	link := &models.Link{ID: 1, ShortCode: "intra", LongURL: internal.URL, ContentMonitoring: true}
+
+	m := NewUrlMonitor(nil, nil, time.Minute, Options{})
+	health := m.CheckNow(link)
+	if health.State != StateInaccessible {
+		t.Errorf("State = %s, want %s", health.State, StateInaccessible)
+	}
+	if health.Content != nil && health.Content.Current != nil {
+		t.Errorf("private page was fingerprinted, title %q", health.Content.Current.Title)
+	}
+
+	// Avec les réseaux privés autorisés (intranet), le titre est relevé normalement.
+	allowed := NewUrlMonitor(nil, nil, time.Minute, Options{AllowPrivateNetworks: true})
+	health = allowed.CheckNow(link)
+	if health.Content == nil || health.Content.Current == nil || !strings.Contains(health.Content.Current.Title, "administration") {
+		t.Errorf("content with AllowPrivateNetworks = %+v, want the page title", health.Content)
+	}
+}
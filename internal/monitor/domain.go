@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// domainCacheTTL évite d'interroger le registre à chaque vérification :
+// la date d'expiration d'un domaine change rarement.
+const domainCacheTTL = 24 * time.Hour
+
+// DomainReport décrit l'expiration d'un nom de domaine d'après RDAP.
+type DomainReport struct {
+	Domain        string    `json:"domain"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	DaysRemaining int       `json:"days_remaining,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// domainChecker interroge un service RDAP et garde les réponses en cache par domaine.
+type domainChecker struct {
+	baseURL string
+	client  *http.Client
+	mu      sync.Mutex
+	cache   map[string]DomainReport
+}
+
+func newDomainChecker(baseURL string) *domainChecker {
+	return &domainChecker{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		cache:   make(map[string]DomainReport),
+	}
+}
+
+// rdapDomain est le sous-ensemble de la réponse RDAP (RFC 9083) utilisé ici.
+type rdapDomain struct {
+	Events []struct {
+		Action string    `json:"eventAction"`
+		Date   time.Time `json:"eventDate"`
+	} `json:"events"`
+}
+
+// check retourne l'expiration du domaine enregistrable de l'URL donnée.
+// Elle retourne nil pour les adresses IP et les hôtes sans domaine enregistrable.
+func (d *domainChecker) check(rawURL string, now time.Time) *DomainReport {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" || net.ParseIP(u.Hostname()) != nil {
+		return nil
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(u.Hostname()))
+	if err != nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	cached, ok := d.cache[domain]
+	d.mu.Unlock()
+	if ok && now.Sub(cached.CheckedAt) < domainCacheTTL {
+		report := cached
+		if !report.ExpiresAt.IsZero() {
+			report.DaysRemaining = int(report.ExpiresAt.Sub(now).Hours() / 24)
+		}
+		return &report
+	}
+
+	report := DomainReport{Domain: domain, CheckedAt: now}
+	expiresAt, err := d.lookup(domain)
+	if err != nil {
+		report.Error = err.Error()
+	} else {
+		report.ExpiresAt = expiresAt
+		report.DaysRemaining = int(expiresAt.Sub(now).Hours() / 24)
+	}
+
+	d.mu.Lock()
+	d.cache[domain] = report
+	d.mu.Unlock()
+	return &report
+}
+
+// lookup interroge RDAP et extrait l'événement "expiration".
+func (d *domainChecker) lookup(domain string) (time.Time, error) {
+	resp, err := d.client.Get(d.baseURL + "/domain/" + url.PathEscape(domain))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("rdap lookup returned status %d", resp.StatusCode)
+	}
+
+	var body rdapDomain
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return time.Time{}, fmt.Errorf("invalid rdap response: %w", err)
+	}
+	for _, event := range body.Events {
+		if event.Action == "expiration" {
+			return event.Date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no expiration date published for %s", domain)
+}
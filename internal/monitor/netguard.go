@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// errPrivateAddress signale une destination que le moniteur refuse de contacter : sans cette
+// garde, quiconque peut créer un lien ferait lire au serveur des pages de son réseau interne
+// (titres et aperçus publiés ensuite par l'API).
+var errPrivateAddress = errors.New("destination address is not public")
+
+// reservedPrefixes complètent les catégories de net/netip : plages partagées ou réservées
+// qui ne désignent pas un hôte public.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "Ce réseau"
+	netip.MustParsePrefix("100.64.0.0/10"), // NAT des opérateurs (RFC 6598)
+	netip.MustParsePrefix("192.0.0.0/24"),  // Affectations de l'IETF
+	netip.MustParsePrefix("198.18.0.0/15"), // Bancs de test (RFC 2544)
+	netip.MustParsePrefix("240.0.0.0/4"),   // Réservé, dont la diffusion 255.255.255.255
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64 : peut traduire vers une adresse IPv4 privée
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// publicAddress indique si le moniteur peut contacter ip : ni boucle locale, ni réseau privé
+// (RFC 1918, fc00::/7), ni lien-local (169.254.0.0/16, dont les services de métadonnées des
+// clouds, fe80::/10), ni adresse non spécifiée, multicast ou réservée.
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// guardControl est le hook Control des connexions du moniteur. Il s'exécute après la
+// résolution DNS, sur l'adresse réellement contactée : un nom qui pointe vers le réseau
+// interne est refusé, y compris après une redirection.
+func guardControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(ip) {
+		return fmt.Errorf("%w (%s)", errPrivateAddress, ip)
+	}
+	return nil
+}
+
+// newDialer retourne le dialer des requêtes du moniteur ; sauf allowPrivate, il refuse les
+// adresses non publiques (voir publicAddress).
+func newDialer(timeout time.Duration, allowPrivate bool) *net.Dialer {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = guardControl
+	}
+	return dialer
+}
+
+// newTransport retourne le transport HTTP du moniteur. Les requêtes ne passent pas par un
+// proxy : le dialer doit voir l'adresse de la destination pour la contrôler.
+func newTransport(timeout time.Duration, allowPrivate bool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = newDialer(timeout, allowPrivate).DialContext
+	return transport
+}
+
+// checkRedirectTarget refuse une redirection vers un autre schéma que http(s) ou vers une
+// adresse IP littérale non publique ; les noms d'hôte sont contrôlés à la connexion.
+func checkRedirectTarget(u *url.URL, allowPrivate bool) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported scheme %q", u.Scheme)
+	}
+	if allowPrivate {
+		return nil
+	}
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil && !publicAddress(ip) {
+		return fmt.Errorf("redirect: %w (%s)", errPrivateAddress, ip)
+	}
+	return nil
+}
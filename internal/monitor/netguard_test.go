@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // Métadonnées des clouds
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false}, // IPv4 dans IPv6
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+	for _, tt := range tests {
+		if got := publicAddress(netip.MustParseAddr(tt.ip)); got != tt.public {
+			t.Errorf("publicAddress(%s) = %v, want %v", tt.ip, got, tt.public)
+		}
+	}
+}
+
+func TestCheckRedirectTarget(t *testing.T) {
+	tests := []struct {
+		target       string
+		allowPrivate bool
+		wantErr      bool
+	}{
+		{"https://example.com/page", false, false},
+		{"http://169.254.169.254/latest/meta-data/", false, true},
+		{"http://[::1]:8080/", false, true},
+		{"http://10.0.0.1/", true, false},
+		{"ftp://example.com/", false, true},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.target)
+		if err := checkRedirectTarget(u, tt.allowPrivate); (err != nil) != tt.wantErr {
+			t.Errorf("checkRedirectTarget(%s, allowPrivate=%v) = %v, want error: %v", tt.target, tt.allowPrivate, err, tt.wantErr)
+		}
+	}
+}
+
+func TestProbeRefusesPrivateDestinations(t *testing.T) {
+	var hits int
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte("<title>Interne</title>"))
+	}))
+	defer internal.Close()
+
+	m := NewUrlMonitor(nil, nil, time.Minute, Options{})
+	result := m.isUrlAccessible(internal.URL, true)
+	if !errors.Is(result.err, errPrivateAddress) {
+		t.Errorf("probe of %s: err = %v, want errPrivateAddress", internal.URL, result.err)
+	}
+	if hits != 0 || result.body != nil {
+		t.Errorf("private destination was fetched (%d requests, %d bytes read)", hits, len(result.body))
+	}
+
+	allowed := NewUrlMonitor(nil, nil, time.Minute, Options{AllowPrivateNetworks: true})
+	if result := allowed.isUrlAccessible(internal.URL, true); result.err != nil || len(result.body) == 0 {
+		t.Errorf("probe with AllowPrivateNetworks: err = %v, %d bytes read", result.err, len(result.body))
+	}
+}
@@ -0,0 +1,41 @@
+package monitor
+
+import (
+	"log"
+	"time"
+)
+
+// Types de notifications émises par le moniteur.
+const (
+	NotificationStateChange   = "state_change"   // Passage ACCESSIBLE <-> INACCESSIBLE, ...
+	NotificationPolicyApplied = "policy_applied" // Seuil d'échecs consécutifs atteint
+	NotificationRecovered     = "recovered"      // Destination de nouveau accessible après une panne
+	NotificationCertExpiry    = "cert_expiry"    // Certificat TLS proche de l'expiration
+	NotificationCertInvalid   = "cert_invalid"   // Certificat auto-signé, mauvais nom d'hôte, chaîne invalide
+	NotificationDomainExpiry  = "domain_expiry"  // Nom de domaine proche de l'expiration
+	NotificationContentChange = "content_change" // Empreinte de la page modifiée au-delà du seuil
+	NotificationLinkDisabled  = "link_disabled"  // Destination listée comme malveillante : lien désactivé
+)
+
+// Notification décrit un événement détecté par le moniteur sur un lien.
+type Notification struct {
+	Kind      string
+	ShortCode string
+	LongURL   string
+	Message   string
+	At        time.Time
+}
+
+// Notifier reçoit les notifications du moniteur.
+// L'implémentation par défaut se contente de les écrire dans les logs du serveur.
+type Notifier interface {
+	Notify(n Notification)
+}
+
+// LogNotifier écrit les notifications dans les logs avec le préfixe [NOTIFICATION].
+type LogNotifier struct{}
+
+// Notify implémente Notifier.
+func (LogNotifier) Notify(n Notification) {
+	log.Printf("[NOTIFICATION] %s", n.Message)
+}
@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// schedule mémorise la prochaine échéance de vérification de chaque lien.
+// Les échéances sont réparties avec une gigue pour éviter que toutes les
+// vérifications partent au même tick.
+type schedule struct {
+	mu       sync.Mutex
+	next     map[uint]time.Time
+	priority map[uint]int       // Priorité connue de chaque lien planifié, pour trier les échéances
+	inflight map[uint]*linkLock // Empêche deux vérifications simultanées du même lien
+
+	listedAt time.Time // Dernier chargement de la liste complète des liens
+}
+
+func newSchedule() *schedule {
+	return &schedule{
+		next:     make(map[uint]time.Time),
+		priority: make(map[uint]int),
+		inflight: make(map[uint]*linkLock),
+	}
+}
+
+// linkLock sérialise les vérifications d'un lien. L'entrée est retirée de schedule.inflight
+// quand plus aucune vérification ne la tient ni ne l'attend : la table ne contient que les
+// liens en cours de vérification.
+type linkLock struct {
+	mu   sync.Mutex
+	refs int // Vérifications qui tiennent ou attendent le verrou ; protégé par schedule.mu
+}
+
+// LinkInterval retourne l'intervalle de vérification d'un lien : le sien s'il en a un,
+// sinon l'intervalle global du moniteur.
+func (m *UrlMonitor) LinkInterval(link *models.Link) time.Duration {
+	if link.MonitorIntervalMinutes > 0 {
+		return time.Duration(link.MonitorIntervalMinutes) * time.Minute
+	}
+	return m.interval
+}
+
+// NextCheck retourne la prochaine vérification planifiée d'un lien.
+func (m *UrlMonitor) NextCheck(linkID uint) (time.Time, bool) {
+	m.schedule.mu.Lock()
+	defer m.schedule.mu.Unlock()
+	next, ok := m.schedule.next[linkID]
+	return next, ok
+}
+
+// listDue indique si la liste complète des liens doit être rechargée : à chaque
+// intervalle de rafraîchissement, et dès que les listes de menaces ont changé.
+func (m *UrlMonitor) listDue(now time.Time) bool {
+	if m.screener != nil && m.screener.Version() != m.screenedVersion {
+		return true
+	}
+	m.schedule.mu.Lock()
+	defer m.schedule.mu.Unlock()
+	return m.schedule.listedAt.IsZero() || now.Sub(m.schedule.listedAt) >= m.opts.Refresh
+}
+
+// planLinks synchronise la planification avec la liste complète des liens. Les liens vus
+// pour la première fois reçoivent une échéance initiale étalée dans la fenêtre de gigue ;
+// les liens supprimés, désactivés ou non surveillés sont oubliés.
+func (m *UrlMonitor) planLinks(links []models.Link, now time.Time) {
+	m.schedule.mu.Lock()
+	defer m.schedule.mu.Unlock()
+
+	seen := make(map[uint]bool, len(links))
+	for i := range links {
+		link := &links[i]
+		if link.MonitorDisabled || link.Disabled {
+			continue
+		}
+		seen[link.ID] = true
+		m.schedule.priority[link.ID] = link.MonitorPriority
+		if _, ok := m.schedule.next[link.ID]; !ok {
+			m.schedule.next[link.ID] = now.Add(m.jitter(m.LinkInterval(link)))
+		}
+	}
+
+	// Leur état en cache ne doit plus influencer la redirection.
+	for id := range m.schedule.next {
+		if !seen[id] {
+			m.unplan(id)
+		}
+	}
+	m.schedule.listedAt = now
+}
+
+// dueLinks retourne les identifiants des liens dont la vérification est arrivée à échéance,
+// triés par priorité décroissante puis par ancienneté de l'échéance.
+func (m *UrlMonitor) dueLinks(now time.Time) []uint {
+	m.schedule.mu.Lock()
+	defer m.schedule.mu.Unlock()
+
+	var due []uint
+	for id, next := range m.schedule.next {
+		if !next.After(now) {
+			due = append(due, id)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		pi, pj := m.schedule.priority[due[i]], m.schedule.priority[due[j]]
+		if pi != pj {
+			return pi > pj
+		}
+		return m.schedule.next[due[i]].Before(m.schedule.next[due[j]])
+	})
+
+	if limit := m.opts.MaxChecksPerTick; limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due
+}
+
+// forgetLink retire un lien de la planification (supprimé, désactivé ou plus surveillé).
+func (m *UrlMonitor) forgetLink(linkID uint) {
+	m.schedule.mu.Lock()
+	m.unplan(linkID)
+	m.schedule.mu.Unlock()
+}
+
+// unplan retire un lien de la planification et oublie son état ; schedule.mu doit être tenu.
+func (m *UrlMonitor) unplan(linkID uint) {
+	delete(m.schedule.next, linkID)
+	delete(m.schedule.priority, linkID)
+	m.forget(linkID)
+}
+
+// scheduleNext planifie la prochaine vérification d'un lien : intervalle ± gigue.
+func (m *UrlMonitor) scheduleNext(link *models.Link, from time.Time) {
+	interval := m.LinkInterval(link)
+	spread := m.jitter(interval)
+	next := from.Add(interval - m.jitterWindow(interval)/2 + spread)
+
+	m.schedule.mu.Lock()
+	m.schedule.next[link.ID] = next
+	m.schedule.priority[link.ID] = link.MonitorPriority
+	m.schedule.mu.Unlock()
+}
+
+// jitterWindow retourne la largeur de la fenêtre de gigue pour un intervalle donné.
+func (m *UrlMonitor) jitterWindow(interval time.Duration) time.Duration {
+	return interval * time.Duration(m.opts.JitterPercent) / 100
+}
+
+// jitter tire un décalage aléatoire dans [0, jitterWindow).
+func (m *UrlMonitor) jitter(interval time.Duration) time.Duration {
+	window := m.jitterWindow(interval)
+	if window <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(window)))
+}
+
+// lockLink sérialise les vérifications d'un même lien (planifiées ou à la demande).
+func (m *UrlMonitor) lockLink(linkID uint) func() {
+	m.schedule.mu.Lock()
+	lock, ok := m.schedule.inflight[linkID]
+	if !ok {
+		lock = &linkLock{}
+		m.schedule.inflight[linkID] = lock
+	}
+	lock.refs++
+	m.schedule.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		m.schedule.mu.Lock()
+		if lock.refs--; lock.refs == 0 {
+			delete(m.schedule.inflight, linkID)
+		}
+		m.schedule.mu.Unlock()
+	}
+}
+
+// forget efface l'état en cache d'un lien qui n'est plus surveillé.
+func (m *UrlMonitor) forget(linkID uint) {
+	m.mu.Lock()
+	delete(m.health, linkID)
+	m.mu.Unlock()
+}
@@ -0,0 +1,42 @@
+package monitor
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockLinkSerializesAndForgetsIdleLinks(t *testing.T) {
+	m := NewUrlMonitor(nil, nil, time.Minute, Options{})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(id uint) {
+			defer wg.Done()
+			unlock := m.lockLink(id)
+			defer unlock()
+			if id != 1 {
+				return
+			}
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}(uint(1 + i%2*(i+1))) // La moitié des vérifications porte sur le lien 1
+	}
+	wg.Wait()
+
+	if maxRunning != 1 {
+		t.Errorf("%d concurrent checks of the same link, want 1", maxRunning)
+	}
+	if n := len(m.schedule.inflight); n != 0 {
+		t.Errorf("%d link locks left after all checks finished, want 0", n)
+	}
+}
@@ -0,0 +1,122 @@
+package monitor
+
+import (
+	"html"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+var (
+	metaPattern = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrPattern = regexp.MustCompile(`(?is)([a-z_:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// previewDue indique si l'aperçu de la destination doit être (re)lu lors de cette vérification.
+// Un lien dont l'aperçu est entièrement saisi n'en a pas besoin.
+func (m *UrlMonitor) previewDue(link *models.Link, now time.Time) bool {
+	if !m.opts.FetchPreviews || link.Disabled {
+		return false
+	}
+	if p := link.Preview; p.Title != "" && p.Description != "" && p.Image != "" {
+		return false
+	}
+	return link.PreviewFetchedAt == nil || now.Sub(*link.PreviewFetchedAt) >= m.opts.PreviewRefresh
+}
+
+// updatePreview enregistre l'aperçu (Open Graph, Twitter Card, <title>) relevé sur la page.
+// Une destination en erreur est relue à la vérification suivante.
+func (m *UrlMonitor) updatePreview(link *models.Link, result probeResult, now time.Time) {
+	if result.err != nil || result.body == nil {
+		return
+	}
+	link.FetchedPreview = extractPreview(result.body, result.url)
+	link.PreviewFetchedAt = &now
+	if err := m.linkRepo.SaveFetchedPreview(link); err != nil {
+		log.Printf("[MONITOR] ERREUR lors de l'enregistrement de l'aperçu du lien %s : %v", link.ShortCode, err)
+	}
+}
+
+// extractPreview lit les balises Open Graph et Twitter Card de l'en-tête d'une page HTML,
+// avec repli sur <title> et la meta description. Les URL d'image relatives sont résolues
+// par rapport à base, l'URL finale de la page.
+func extractPreview(body []byte, base *url.URL) models.LinkPreview {
+	raw := string(body)
+	if i := strings.Index(strings.ToLower(raw), "</head>"); i >= 0 {
+		raw = raw[:i]
+	}
+
+	// Première valeur de chaque propriété (og:title, twitter:image, description, ...).
+	tags := map[string]string{}
+	for _, meta := range metaPattern.FindAllString(raw, -1) {
+		attrs := map[string]string{}
+		for _, match := range attrPattern.FindAllStringSubmatch(meta, -1) {
+			attrs[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+		}
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, seen := tags[key]; key != "" && !seen {
+			tags[key] = cleanText(attrs["content"])
+		}
+	}
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if v := tags[key]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	title := first("og:title", "twitter:title")
+	if title == "" {
+		if match := titlePattern.FindStringSubmatch(raw); match != nil {
+			title = cleanText(match[1])
+		}
+	}
+	return models.LinkPreview{
+		Title:       truncateRunes(title, models.MaxPreviewTitle),
+		Description: truncateRunes(first("og:description", "twitter:description", "description"), models.MaxPreviewDescription),
+		Image:       resolveImage(first("og:image", "og:image:url", "og:image:secure_url", "twitter:image", "twitter:image:src"), base),
+	}
+}
+
+// cleanText décode les entités HTML et normalise les espaces.
+func cleanText(s string) string {
+	return strings.TrimSpace(spacesPattern.ReplaceAllString(html.UnescapeString(s), " "))
+}
+
+func truncateRunes(s string, max int) string {
+	if runes := []rune(s); len(runes) > max {
+		return strings.TrimSpace(string(runes[:max]))
+	}
+	return s
+}
+
+// resolveImage retourne l'URL absolue (http ou https) de l'image, ou "" si elle est inutilisable.
+func resolveImage(raw string, base *url.URL) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	if image := u.String(); len(image) <= models.MaxPreviewImage {
+		return image
+	}
+	return ""
+}
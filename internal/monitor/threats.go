@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"log"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/threats"
+)
+
+// maxDisabledReason correspond à la taille de la colonne disabled_reason, en caractères.
+const maxDisabledReason = 255
+
+// screenAllLinks confronte tous les liens actifs aux listes de menaces quand celles-ci
+// ont été (re)chargées depuis le dernier passage. Entre deux rechargements, seuls les
+// liens vérifiés par le moniteur sont confrontés (voir screenLink).
+func (m *UrlMonitor) screenAllLinks(links []models.Link) {
+	if m.screener == nil {
+		return
+	}
+	version := m.screener.Version()
+	if version == m.screenedVersion {
+		return
+	}
+	disabled := 0
+	for i := range links {
+		if !links[i].Disabled && m.screenLink(&links[i]) {
+			disabled++
+		}
+	}
+	m.screenedVersion = version
+	log.Printf("[MONITOR] Liens confrontés aux listes de menaces (%d règle(s)) : %d lien(s) désactivé(s).",
+		m.screener.Size(), disabled)
+}
+
+// destinations retourne toutes les URL vers lesquelles un lien peut rediriger : la destination
+// principale, l'URL de repli, les destinations des règles de ciblage et des variantes A/B.
+func destinations(link *models.Link) []string {
+	urls := make([]string, 0, 2+len(link.Rules)+len(link.Variants))
+	urls = append(urls, link.LongURL)
+	if link.FallbackURL != "" {
+		urls = append(urls, link.FallbackURL)
+	}
+	for _, rule := range link.Rules {
+		urls = append(urls, rule.Destination)
+	}
+	for _, variant := range link.Variants {
+		urls = append(urls, variant.Destination)
+	}
+	return urls
+}
+
+// screenLink désactive le lien si l'une de ses destinations (voir destinations) figure
+// dans les listes de menaces. Elle retourne true si le lien est (désormais) désactivé.
+func (m *UrlMonitor) screenLink(link *models.Link) bool {
+	if link.Disabled {
+		return true
+	}
+	var match *threats.Match
+	var listed string
+	for _, destination := range destinations(link) {
+		if match = m.screener.Check(destination); match != nil {
+			listed = destination
+			break
+		}
+	}
+	if match == nil {
+		return false
+	}
+
+	reason := truncateRunes("threat list: "+match.String(), maxDisabledReason)
+	now := time.Now()
+	link.Disabled = true
+	link.DisabledReason = reason
+	link.DisabledAt = &now
+	// Seules les colonnes de désactivation sont écrites : le lien a pu être modifié depuis
+	// le chargement de la liste par le planificateur.
+	if err := m.linkRepo.UpdateLinkFields(link, "disabled", "disabled_reason", "disabled_at"); err != nil {
+		log.Printf("[MONITOR] ERREUR lors de la désactivation du lien %s : %v", link.ShortCode, err)
+		return true
+	}
+
+	if m.auditRepo != nil {
+		audit := &models.LinkAudit{
+			LinkID:    link.ID,
+			ShortCode: link.ShortCode,
+			Action:    models.AuditActionDisabled,
+			Actor:     models.AuditActorMonitor,
+			Reason:    reason,
+			CreatedAt: now,
+		}
+		if err := m.auditRepo.CreateAudit(audit); err != nil {
+			log.Printf("[MONITOR] ERREUR lors de l'audit de la désactivation du lien %s : %v", link.ShortCode, err)
+		}
+	}
+	m.notify(NotificationLinkDisabled, link, "Le lien %s (%s) a été désactivé : destination listée (%s).",
+		link.ShortCode, listed, match)
+	return true
+}
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/axellelanca/urlshortener/internal/database/dbtest"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/threats"
+)
+
+func TestScreenLinkDisablesWithValidReason(t *testing.T) {
+	// Une expression longue, faite de caractères multi-octets : le motif dépasse la taille
+	// de la colonne et une coupe à l'octet tomberait au milieu d'un caractère.
+	list := filepath.Join(t.TempDir(), "regexes.txt")
+	pattern := `^https://example\.com/` + strings.Repeat("é?", 150)
+	if err := os.WriteFile(list, []byte(pattern+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	screener, err := threats.NewScreener(threats.Sources{RegexLists: []string{list}})
+	if err != nil {
+		t.Fatalf("NewScreener: %v", err)
+	}
+
+	db := dbtest.Migrated(t, dbtest.Targets(t)[0])
+	linkRepo := repository.NewLinkRepository(db)
+	link := &models.Link{ShortCode: "listed", LongURL: "https://example.com/page"}
+	if err := linkRepo.CreateLink(link); err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	// Modification faite après le chargement du lien par le moniteur (ex: signalement).
+	other, _ := linkRepo.GetLinkByID(link.ID)
+	other.Flagged = true
+	if err := linkRepo.UpdateLink(other); err != nil {
+		t.Fatalf("UpdateLink: %v", err)
+	}
+
+	m := NewUrlMonitor(linkRepo, nil, time.Minute, Options{})
+	m.SetThreatScreener(screener, nil)
+	if !m.screenLink(link) {
+		t.Fatal("screenLink did not disable a listed destination")
+	}
+
+	got, err := linkRepo.GetLinkByID(link.ID)
+	if err != nil {
+		t.Fatalf("GetLinkByID: %v", err)
+	}
+	if !got.Disabled || got.DisabledAt == nil {
+		t.Errorf("link not disabled: disabled=%v disabled_at=%v", got.Disabled, got.DisabledAt)
+	}
+	if !utf8.ValidString(got.DisabledReason) {
+		t.Errorf("DisabledReason is not valid UTF-8: %q", got.DisabledReason)
+	}
+	if n := utf8.RuneCountInString(got.DisabledReason); n == 0 || n > maxDisabledReason {
+		t.Errorf("DisabledReason has %d characters, want 1 to %d", n, maxDisabledReason)
+	}
+	if !got.Flagged {
+		t.Error("screenLink reverted a change made after the link was loaded")
+	}
+}
@@ -0,0 +1,127 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/url"
+	"time"
+)
+
+// Problèmes de certificat distingués par le moniteur.
+const (
+	CertProblemSelfSigned       = "self_signed"
+	CertProblemHostnameMismatch = "hostname_mismatch"
+	CertProblemExpired          = "expired"
+	CertProblemUnknownAuthority = "unknown_authority"
+	CertProblemInvalidChain     = "invalid_chain"
+)
+
+// CertificateInfo résume un certificat de la chaîne présentée par la destination.
+type CertificateInfo struct {
+	Subject           string    `json:"subject"`
+	Issuer            string    `json:"issuer"`
+	DNSNames          []string  `json:"dns_names,omitempty"`
+	SerialNumber      string    `json:"serial_number"`
+	NotBefore         time.Time `json:"not_before"`
+	NotAfter          time.Time `json:"not_after"`
+	SHA256Fingerprint string    `json:"sha256_fingerprint"`
+}
+
+// TLSReport est le résultat de l'inspection TLS d'une destination HTTPS.
+type TLSReport struct {
+	Valid         bool              `json:"valid"`
+	Problem       string            `json:"problem,omitempty"` // Voir CertProblem*
+	Error         string            `json:"error,omitempty"`
+	ExpiresAt     time.Time         `json:"expires_at"`     // Expiration la plus proche de la chaîne
+	DaysRemaining int               `json:"days_remaining"` // Négatif si déjà expiré
+	Chain         []CertificateInfo `json:"chain"`
+}
+
+// newTLSReport construit un rapport à partir de la chaîne de certificats du pair.
+func newTLSReport(certs []*x509.Certificate, now time.Time) *TLSReport {
+	report := &TLSReport{Valid: true}
+	for _, cert := range certs {
+		sum := sha256.Sum256(cert.Raw)
+		report.Chain = append(report.Chain, CertificateInfo{
+			Subject:           cert.Subject.String(),
+			Issuer:            cert.Issuer.String(),
+			DNSNames:          cert.DNSNames,
+			SerialNumber:      cert.SerialNumber.String(),
+			NotBefore:         cert.NotBefore,
+			NotAfter:          cert.NotAfter,
+			SHA256Fingerprint: hex.EncodeToString(sum[:]),
+		})
+		if report.ExpiresAt.IsZero() || cert.NotAfter.Before(report.ExpiresAt) {
+			report.ExpiresAt = cert.NotAfter
+		}
+	}
+	if !report.ExpiresAt.IsZero() {
+		report.DaysRemaining = int(report.ExpiresAt.Sub(now).Hours() / 24)
+	}
+	return report
+}
+
+// classifyCertError détermine si une erreur de requête provient de la vérification
+// du certificat, et si oui de quel problème il s'agit.
+func classifyCertError(err error) (string, bool) {
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &hostnameErr) {
+		return CertProblemHostnameMismatch, true
+	}
+
+	var authorityErr x509.UnknownAuthorityError
+	if errors.As(err, &authorityErr) {
+		if cert := authorityErr.Cert; cert != nil && isSelfSigned(cert) {
+			return CertProblemSelfSigned, true
+		}
+		return CertProblemUnknownAuthority, true
+	}
+
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &invalidErr) {
+		if invalidErr.Reason == x509.Expired {
+			return CertProblemExpired, true
+		}
+		return CertProblemInvalidChain, true
+	}
+
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) {
+		return CertProblemInvalidChain, true
+	}
+	return "", false
+}
+
+// isSelfSigned indique si le certificat est signé par sa propre clé.
+func isSelfSigned(cert *x509.Certificate) bool {
+	return cert.Subject.String() == cert.Issuer.String() && cert.CheckSignatureFrom(cert) == nil
+}
+
+// fetchPeerCertificates récupère la chaîne présentée par l'hôte sans la vérifier.
+// Elle sert à décrire un certificat que la vérification standard a rejeté ; dialer est
+// celui du moniteur (voir newDialer).
+func fetchPeerCertificates(rawURL string, dialer *net.Dialer) ([]*x509.Certificate, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Hostname()
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, port), &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true, // Inspection seulement : la vérification a déjà échoué
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates, nil
+}
@@ -1,43 +1,183 @@
 package monitor
 
 import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"sync" // Pour protéger l'accès concurrentiel à knownStates
+	"net/url"
+	"sync" // Pour protéger l'accès concurrentiel à l'état de santé des liens
 	"time"
 
-	_ "github.com/axellelanca/urlshortener/internal/models"   // Importe les modèles de liens
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/models"     // Importe les modèles de liens
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le repository de liens
+	"github.com/axellelanca/urlshortener/internal/threats"
+	"gorm.io/gorm"
 )
 
+// HealthState décrit l'état d'une URL longue tel que vu par le moniteur.
+type HealthState string
+
+const (
+	StateUnknown      HealthState = "UNKNOWN" // Lien pas encore vérifié
+	StateAccessible   HealthState = "ACCESSIBLE"
+	StateInaccessible HealthState = "INACCESSIBLE"
+	StateCertInvalid  HealthState = "CERT_INVALID" // Certificat auto-signé, mauvais nom d'hôte ou chaîne invalide
+)
+
+// LinkHealth est l'état de santé mis en cache pour un lien.
+// Il est consulté par la redirection sans aller-retour en base de données.
+type LinkHealth struct {
+	State               HealthState `json:"state"`
+	ConsecutiveFailures int         `json:"consecutive_failures"`
+	StatusCode          int         `json:"status_code,omitempty"`
+	LastError           string      `json:"last_error,omitempty"`
+	LastCheckedAt       time.Time   `json:"last_checked_at"`
+
+	TLS     *TLSReport     `json:"tls,omitempty"`     // Destinations HTTPS uniquement
+	Domain  *DomainReport  `json:"domain,omitempty"`  // Si la vérification d'expiration de domaine est activée
+	Content *ContentReport `json:"content,omitempty"` // Liens dont le contenu est surveillé
+}
+
+// Options regroupe les réglages du moniteur en plus de l'intervalle.
+type Options struct {
+	FailureThreshold    int           // Échecs consécutifs par défaut avant d'appliquer la politique d'un lien
+	CertExpiryWarning   time.Duration // Fenêtre d'alerte avant l'expiration d'un certificat
+	DomainExpiryCheck   bool          // Interroger RDAP pour l'expiration des noms de domaine
+	DomainExpiryWarning time.Duration // Fenêtre d'alerte avant l'expiration d'un domaine
+	RDAPBaseURL         string        // Service RDAP (ex: https://rdap.org)
+
+	ContentChangeThreshold int   // Pourcentage de différence à partir duquel un changement de contenu est signalé
+	ContentMaxBytes        int64 // Taille maximale du corps lu pour l'empreinte
+
+	FetchPreviews  bool          // Relever l'aperçu (Open Graph) des destinations, utilisé par défaut
+	PreviewRefresh time.Duration // Délai avant de relire l'aperçu d'une destination
+
+	AllowPrivateNetworks bool // Contacter aussi les adresses privées, locales et lien-local (voir publicAddress)
+
+	Tick             time.Duration // Fréquence à laquelle le planificateur cherche les liens à vérifier
+	Refresh          time.Duration // Fréquence de rechargement de la liste complète des liens
+	JitterPercent    int           // Largeur de la gigue appliquée aux échéances, en % de l'intervalle
+	MaxChecksPerTick int           // Nombre maximal de vérifications par tick (0 = illimité), par priorité
+}
+
 // UrlMonitor gère la surveillance périodique des URLs longues.
 type UrlMonitor struct {
-	linkRepo    repository.LinkRepository // Pour récupérer les URLs à surveiller
-	interval    time.Duration             // Intervalle entre chaque vérification (ex: 5 minutes)
-	knownStates map[uint]bool             // État connu de chaque URL: map[LinkID]estAccessible (true/false)
-	mu          sync.Mutex                // Mutex pour protéger l'accès concurrentiel à knownStates
+	linkRepo  repository.LinkRepository      // Pour récupérer les URLs à surveiller
+	checkRepo repository.LinkCheckRepository // Historique des vérifications (empreintes de contenu), peut être nil
+	interval  time.Duration                  // Intervalle entre chaque vérification (ex: 5 minutes)
+	opts      Options
+	notifier  Notifier            // Destination des notifications (logs par défaut)
+	domains   *domainChecker      // nil si la vérification des domaines est désactivée
+	schedule  *schedule           // Prochaine échéance de chaque lien
+	health    map[uint]LinkHealth // État connu de chaque URL: map[LinkID]LinkHealth
+	mu        sync.RWMutex        // Protège l'accès concurrentiel à health (lu par les handlers HTTP)
+	transport *http.Transport     // Connexions aux destinations, limitées aux adresses publiques par défaut
+
+	screener        *threats.Screener          // Listes de menaces, nil si désactivées
+	auditRepo       repository.AuditRepository // Journal des liens désactivés
+	screenedVersion uint64                     // Version des listes appliquée à tous les liens
 }
 
 //	finir cette fonction
 //
 // NewUrlMonitor crée et retourne une nouvelle instance de UrlMonitor.
 // Attention: retourne un pointeur
-func NewUrlMonitor(linkRepo repository.LinkRepository, interval time.Duration) *UrlMonitor {
-	return &UrlMonitor{
-		linkRepo:    linkRepo,
-		interval:    interval,
-		knownStates: make(map[uint]bool),
+func NewUrlMonitor(linkRepo repository.LinkRepository, checkRepo repository.LinkCheckRepository,
+	interval time.Duration, opts Options) *UrlMonitor {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 1
+	}
+	if opts.ContentMaxBytes <= 0 {
+		opts.ContentMaxBytes = 1 << 20
+	}
+	if opts.PreviewRefresh <= 0 {
+		opts.PreviewRefresh = 24 * time.Hour
 	}
+	if opts.Tick <= 0 || opts.Tick > interval {
+		opts.Tick = interval
+	}
+	if opts.Refresh < opts.Tick {
+		opts.Refresh = opts.Tick
+	}
+	m := &UrlMonitor{
+		linkRepo:  linkRepo,
+		checkRepo: checkRepo,
+		interval:  interval,
+		opts:      opts,
+		notifier:  LogNotifier{},
+		schedule:  newSchedule(),
+		health:    make(map[uint]LinkHealth),
+		transport: newTransport(probeTimeout, opts.AllowPrivateNetworks),
+	}
+	if opts.DomainExpiryCheck && opts.RDAPBaseURL != "" {
+		m.domains = newDomainChecker(opts.RDAPBaseURL)
+	}
+	return m
+}
+
+// NewUrlMonitorFromConfig crée un moniteur à partir de la section 'monitor' de la configuration.
+func NewUrlMonitorFromConfig(linkRepo repository.LinkRepository, checkRepo repository.LinkCheckRepository,
+	cfg config.MonitorConfig) *UrlMonitor {
+	interval := time.Duration(cfg.IntervalMinutes) * time.Minute
+	return NewUrlMonitor(linkRepo, checkRepo, interval, Options{
+		FailureThreshold:    cfg.FailureThreshold,
+		CertExpiryWarning:   time.Duration(cfg.CertExpiryWarningDays) * 24 * time.Hour,
+		DomainExpiryCheck:   cfg.DomainExpiryCheck,
+		DomainExpiryWarning: time.Duration(cfg.DomainExpiryWarningDays) * 24 * time.Hour,
+		RDAPBaseURL:         cfg.RDAPURL,
+
+		ContentChangeThreshold: cfg.ContentChangeThreshold,
+		ContentMaxBytes:        cfg.ContentMaxBytes,
+
+		FetchPreviews:  cfg.FetchPreviews,
+		PreviewRefresh: time.Duration(cfg.PreviewRefreshHours) * time.Hour,
+
+		AllowPrivateNetworks: cfg.AllowPrivateNetworks,
+
+		Tick:             time.Duration(cfg.TickSeconds) * time.Second,
+		Refresh:          time.Duration(cfg.RefreshSeconds) * time.Second,
+		JitterPercent:    cfg.JitterPercent,
+		MaxChecksPerTick: cfg.MaxChecksPerTick,
+	})
+}
+
+// SetNotifier remplace la destination des notifications (logs par défaut).
+func (m *UrlMonitor) SetNotifier(n Notifier) {
+	m.notifier = n
+}
+
+// SetThreatScreener active la désactivation automatique des liens dont la destination
+// figure dans les listes de menaces ; chaque désactivation est consignée dans auditRepo.
+func (m *UrlMonitor) SetThreatScreener(screener *threats.Screener, auditRepo repository.AuditRepository) {
+	m.screener = screener
+	m.auditRepo = auditRepo
+}
+
+// notify envoie une notification concernant un lien.
+func (m *UrlMonitor) notify(kind string, link *models.Link, format string, args ...any) {
+	m.notifier.Notify(Notification{
+		Kind:      kind,
+		ShortCode: link.ShortCode,
+		LongURL:   link.LongURL,
+		Message:   fmt.Sprintf(format, args...),
+		At:        time.Now(),
+	})
 }
 
 // Start lance la boucle de surveillance périodique des URLs.
 // Cette fonction est conçue pour être lancée dans une goroutine séparée.
+// À chaque tick, seuls les liens dont l'échéance est atteinte sont vérifiés.
 func (m *UrlMonitor) Start() {
-	log.Printf("[MONITOR] Démarrage du moniteur d'URLs avec un intervalle de %v...", m.interval)
-	ticker := time.NewTicker(m.interval) // Crée un ticker qui envoie un signal à chaque intervalle
-	defer ticker.Stop()                  // S'assure que le ticker est arrêté quand Start se termine
+	log.Printf("[MONITOR] Démarrage du moniteur d'URLs avec un intervalle de %v (tick %v, gigue %d%%)...",
+		m.interval, m.opts.Tick, m.opts.JitterPercent)
+	ticker := time.NewTicker(m.opts.Tick) // Crée un ticker qui envoie un signal à chaque tick
+	defer ticker.Stop()                   // S'assure que le ticker est arrêté quand Start se termine
 
-	// Exécute une première vérification immédiatement au démarrage
+	// Planifie les liens dès le démarrage
 	m.checkUrls()
 
 	// Boucle principale du moniteur, déclenchée par le ticker
@@ -46,80 +186,385 @@ func (m *UrlMonitor) Start() {
 	}
 }
 
-// checkUrls effectue une vérification de l'état de toutes les URLs longues enregistrées.
-func (m *UrlMonitor) checkUrls() {
-	log.Println("[MONITOR] Lancement de la vérification de l'état des URLs...")
+// CheckNow vérifie immédiatement un lien, hors planification, et retourne le résultat.
+// La prochaine vérification planifiée est repoussée d'un intervalle.
+func (m *UrlMonitor) CheckNow(link *models.Link) LinkHealth {
+	health := m.checkLink(link)
+	if !link.MonitorDisabled {
+		m.scheduleNext(link, time.Now())
+	}
+	return health
+}
 
-	//  : Récupérer toutes les URLs longues actives depuis le linkRepo (GetAllLinks).
-	// Gérer l'erreur si la récupération échoue.
-	// Si erreur : log.Printf("[MONITOR] ERREUR lors de la récupération des liens pour la surveillance : %v", err)
-	links, err := m.linkRepo.GetAllLinks()
-	if err != nil {
-		log.Printf("[MONITOR] ERREUR lors de la récupération des liens pour la surveillance : %v", err)
-		return
+// Health retourne l'état de santé mis en cache pour un lien.
+// Le booléen vaut false si le lien n'a pas encore été vérifié.
+func (m *UrlMonitor) Health(linkID uint) (LinkHealth, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	h, ok := m.health[linkID]
+	return h, ok
+}
+
+// FailureThreshold retourne le nombre d'échecs consécutifs à partir duquel
+// la politique de panne du lien s'applique.
+func (m *UrlMonitor) FailureThreshold(link *models.Link) int {
+	if link.FailureThreshold > 0 {
+		return link.FailureThreshold
 	}
+	return m.opts.FailureThreshold
+}
+
+// IsBroken indique si la destination du lien a échoué suffisamment de fois
+// d'affilée pour que sa politique de panne s'applique.
+// Un lien redevient sain automatiquement dès qu'une vérification réussit.
+func (m *UrlMonitor) IsBroken(link *models.Link) bool {
+	if link.MonitorDisabled {
+		return false
+	}
+	h, ok := m.Health(link.ID)
+	if !ok {
+		return false
+	}
+	return h.ConsecutiveFailures >= m.FailureThreshold(link)
+}
 
-	for _, link := range links {
-		//  : Pour chaque lien, vérifier son accessibilité (isUrlAccessible).
-		currentState := m.isUrlAccessible(link.LongURL)
+// checkUrls vérifie les liens arrivés à échéance. La liste complète des liens n'est
+// rechargée qu'à l'intervalle de rafraîchissement (ou après un rechargement des listes
+// de menaces) ; entre deux, seuls les liens à vérifier sont relus en base.
+func (m *UrlMonitor) checkUrls() {
+	now := time.Now()
+	if m.listDue(now) {
+		links, err := m.linkRepo.GetAllLinks()
+		if err != nil {
+			log.Printf("[MONITOR] ERREUR lors de la récupération des liens pour la surveillance : %v", err)
+			return
+		}
+		m.planLinks(links, now)
+		m.screenAllLinks(links)
+	}
 
-		// Protéger l'accès à la map 'knownStates' car 'checkUrls' peut être exécuté concurremment
-		m.mu.Lock()
-		previousState, exists := m.knownStates[link.ID] // Récupère l'état précédent
-		m.knownStates[link.ID] = currentState           // Met à jour l'état actuel
-		m.mu.Unlock()
+	due := m.dueLinks(now)
+	if len(due) == 0 {
+		return
+	}
 
-		// Si c'est la première vérification pour ce lien, on initialise l'état sans notifier.
-		if !exists {
-			log.Printf("[MONITOR] État initial pour le lien %s (%s) : %s",
-				link.ShortCode, link.LongURL, formatState(currentState))
+	log.Printf("[MONITOR] Lancement de la vérification de l'état de %d URL(s)...", len(due))
+	for _, id := range due {
+		// Relire le lien : il a pu être modifié, désactivé ou supprimé depuis le dernier chargement.
+		link, err := m.linkRepo.GetLinkByID(id)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("[MONITOR] ERREUR lors de la récupération du lien %d : %v", id, err)
+				continue // Toujours à échéance : nouvel essai au prochain tick
+			}
+			m.forgetLink(id)
+			continue
+		}
+		if link.MonitorDisabled || link.Disabled {
+			m.forgetLink(id)
 			continue
 		}
+		if m.screenLink(link) {
+			m.forgetLink(id)
+			continue
+		}
+		m.checkLink(link)
+		m.scheduleNext(link, time.Now())
+	}
+	log.Println("[MONITOR] Vérification de l'état des URLs terminée.")
+}
+
+// checkLink vérifie un lien, met à jour son état en cache et génère les notifications.
+func (m *UrlMonitor) checkLink(link *models.Link) LinkHealth {
+	unlock := m.lockLink(link.ID)
+	defer unlock()
+
+	//  : Pour chaque lien, vérifier son accessibilité (isUrlAccessible).
+	// Le corps de la page n'est lu que pour l'empreinte de contenu ou l'aperçu.
+	fetchPreview := m.previewDue(link, time.Now())
+	result := m.isUrlAccessible(link.LongURL, link.ContentMonitoring || fetchPreview)
+	now := time.Now()
+
+	current := LinkHealth{
+		State:         StateAccessible,
+		StatusCode:    result.statusCode,
+		LastCheckedAt: now,
+		TLS:           result.tls,
+	}
+	switch {
+	case result.tls != nil && !result.tls.Valid:
+		current.State = StateCertInvalid
+		current.LastError = result.err.Error()
+	case result.err != nil:
+		current.State = StateInaccessible
+		current.LastError = result.err.Error()
+	}
+	if m.domains != nil {
+		current.Domain = m.domains.check(link.LongURL, now)
+	}
+
+	// Protéger l'accès à la map 'health' car elle est lue par les handlers HTTP
+	m.mu.Lock()
+	previous, exists := m.health[link.ID] // Récupère l'état précédent
+	if current.State != StateAccessible {
+		current.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+	}
+	m.mu.Unlock()
+
+	// L'empreinte de contenu dépend de l'état précédent : on la calcule hors verrou
+	// (elle peut lire l'historique en base) puis on publie l'état complet.
+	var change *ContentChange
+	if link.ContentMonitoring {
+		current.Content, change = m.updateContent(link, previous.Content, exists, result, now)
+	}
+
+	m.mu.Lock()
+	m.health[link.ID] = current // Met à jour l'état actuel
+	m.mu.Unlock()
 
+	if fetchPreview {
+		m.updatePreview(link, result, now)
+	}
+
+	// Si c'est la première vérification pour ce lien, on initialise l'état sans notifier
+	// le changement d'état.
+	if !exists {
+		log.Printf("[MONITOR] État initial pour le lien %s (%s) : %s",
+			link.ShortCode, link.LongURL, current.State)
+	} else if current.State != previous.State {
 		//  : Comparer l'état actuel avec l'état précédent.
 		// Si l'état a changé, générer une fausse notification dans les logs.
-		// log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !"
+		m.notify(NotificationStateChange, link, "Le lien %s (%s) est passé de %s à %s !",
+			link.ShortCode, link.LongURL, previous.State, current.State)
+	}
+
+	// Notifier le franchissement du seuil de panne, puis le rétablissement.
+	threshold := m.FailureThreshold(link)
+	switch {
+	case current.ConsecutiveFailures == threshold && link.FailurePolicy != models.FailurePolicyKeep:
+		m.notify(NotificationPolicyApplied, link, "Le lien %s (%s) a échoué %d fois d'affilée : politique '%s' appliquée.",
+			link.ShortCode, link.LongURL, threshold, link.FailurePolicy)
+	case current.ConsecutiveFailures == 0 && previous.ConsecutiveFailures >= threshold:
+		m.notify(NotificationRecovered, link, "Le lien %s (%s) est rétabli : redirection normale restaurée.",
+			link.ShortCode, link.LongURL)
+	}
+
+	m.notifyExpiry(link, previous, current)
+	if change != nil {
+		m.notify(NotificationContentChange, link, "Le contenu du lien %s (%s) a changé (%d%% de différence) : \"%s\" -> \"%s\".",
+			link.ShortCode, link.LongURL, change.Difference, change.PreviousTitle, change.Title)
+	}
+	return current
+}
+
+// updateContent calcule l'empreinte de la page, la compare à la précédente et enregistre
+// la vérification. Elle retourne le nouveau rapport et le changement détecté (nil sinon).
+func (m *UrlMonitor) updateContent(link *models.Link, previous *ContentReport, known bool,
+	result probeResult, now time.Time) (*ContentReport, *ContentChange) {
+
+	report := &ContentReport{}
+	if previous != nil {
+		*report = *previous
+	} else if !known && m.checkRepo != nil {
+		// Premier passage depuis le démarrage : repartir de l'historique enregistré.
+		report = m.loadContentReport(link)
+	}
+
+	// Destination en erreur : on garde la référence précédente.
+	if result.err != nil || result.body == nil {
+		return report, nil
+	}
+
+	fingerprint := fingerprintContent(result.body)
+	check := &models.LinkCheck{
+		LinkID:      link.ID,
+		CheckedAt:   now,
+		State:       string(StateAccessible),
+		StatusCode:  result.statusCode,
+		ContentHash: fingerprint.Hash,
+		SimHash:     formatSimHash(fingerprint.SimHash),
+		Title:       fingerprint.Title,
+	}
 
-		if currentState != previousState {
-			log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !",
-				link.ShortCode, link.LongURL, formatState(previousState), formatState(currentState))
+	var change *ContentChange
+	if report.Current != nil {
+		if diff := contentDifference(*report.Current, fingerprint); diff > 0 && diff >= m.opts.ContentChangeThreshold {
+			change = &ContentChange{
+				DetectedAt:    now,
+				PreviousTitle: report.Current.Title,
+				Title:         fingerprint.Title,
+				Difference:    diff,
+			}
+			report.LastChange = change
+			check.ContentChanged = true
+			check.PreviousTitle = change.PreviousTitle
+			check.Difference = diff
 		}
+	}
+	report.Current = &fingerprint
 
+	if m.checkRepo != nil {
+		if err := m.checkRepo.CreateCheck(check); err != nil {
+			log.Printf("[MONITOR] ERREUR lors de l'enregistrement de la vérification du lien %s : %v", link.ShortCode, err)
+		}
 	}
-	log.Println("[MONITOR] Vérification de l'état des URLs terminée.")
+	return report, change
+}
+
+// loadContentReport reconstruit le rapport de contenu depuis les vérifications enregistrées.
+func (m *UrlMonitor) loadContentReport(link *models.Link) *ContentReport {
+	report := &ContentReport{}
+
+	latest, err := m.checkRepo.GetLatestCheck(link.ID)
+	if err != nil {
+		log.Printf("[MONITOR] ERREUR lors de la lecture de l'historique du lien %s : %v", link.ShortCode, err)
+		return report
+	}
+	if latest != nil && latest.ContentHash != "" {
+		report.Current = &ContentFingerprint{
+			Hash:    latest.ContentHash,
+			SimHash: parseSimHash(latest.SimHash),
+			Title:   latest.Title,
+		}
+	}
+
+	lastChange, err := m.checkRepo.GetLatestContentChange(link.ID)
+	if err == nil && lastChange != nil {
+		report.LastChange = &ContentChange{
+			DetectedAt:    lastChange.CheckedAt,
+			PreviousTitle: lastChange.PreviousTitle,
+			Title:         lastChange.Title,
+			Difference:    lastChange.Difference,
+		}
+	}
+	return report
+}
+
+// notifyExpiry signale un certificat invalide ou l'entrée d'un certificat ou d'un domaine
+// dans sa fenêtre d'alerte. Chaque alerte n'est émise qu'une fois par changement.
+func (m *UrlMonitor) notifyExpiry(link *models.Link, previous, current LinkHealth) {
+	if tlsReport := current.TLS; tlsReport != nil {
+		prevProblem := ""
+		if previous.TLS != nil {
+			prevProblem = previous.TLS.Problem
+		}
+		if !tlsReport.Valid && tlsReport.Problem != prevProblem {
+			m.notify(NotificationCertInvalid, link, "Le certificat TLS du lien %s (%s) est invalide : %s.",
+				link.ShortCode, link.LongURL, tlsReport.Problem)
+		}
+
+		window := daysIn(m.opts.CertExpiryWarning)
+		wasInWindow := previous.TLS != nil && !previous.TLS.ExpiresAt.IsZero() && previous.TLS.DaysRemaining <= window
+		if window > 0 && tlsReport.Valid && !tlsReport.ExpiresAt.IsZero() &&
+			tlsReport.DaysRemaining <= window && !wasInWindow {
+			m.notify(NotificationCertExpiry, link, "Le certificat TLS du lien %s (%s) expire dans %d jour(s) (%s).",
+				link.ShortCode, link.LongURL, tlsReport.DaysRemaining, tlsReport.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+
+	if domain := current.Domain; domain != nil && !domain.ExpiresAt.IsZero() {
+		window := daysIn(m.opts.DomainExpiryWarning)
+		wasInWindow := previous.Domain != nil && !previous.Domain.ExpiresAt.IsZero() && previous.Domain.DaysRemaining <= window
+		if window > 0 && domain.DaysRemaining <= window && !wasInWindow {
+			m.notify(NotificationDomainExpiry, link, "Le domaine %s du lien %s expire dans %d jour(s) (%s).",
+				domain.Domain, link.ShortCode, domain.DaysRemaining, domain.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+}
+
+// daysIn convertit une durée en nombre de jours entiers.
+func daysIn(d time.Duration) int {
+	return int(d.Hours() / 24)
+}
+
+// probeResult est le résultat brut d'une requête de vérification.
+type probeResult struct {
+	statusCode int
+	err        error      // nil si l'URL est accessible
+	tls        *TLSReport // nil pour les destinations HTTP
+	body       []byte     // Corps de la page, lu seulement si demandé
+	url        *url.URL   // URL finale, après les redirections
 }
 
+// probeTimeout borne chaque vérification, pour éviter de bloquer trop longtemps (5 secondes c'est bien).
+const probeTimeout = 5 * time.Second
+
 // isUrlAccessible effectue une requête HTTP HEAD pour vérifier l'accessibilité d'une URL.
-func (m *UrlMonitor) isUrlAccessible(url string) bool {
-	//  Définir un timeout pour éviter de bloquer trop longtemps (5 secondes c'est bien)
+// Pour les destinations HTTPS, elle relève aussi la chaîne de certificats du premier hôte.
+// Si withBody est vrai, une requête GET est faite à la place pour lire le contenu de la page.
+// Sauf Options.AllowPrivateNetworks, les adresses non publiques sont refusées, redirections comprises.
+func (m *UrlMonitor) isUrlAccessible(rawURL string, withBody bool) probeResult {
+	timeout := probeTimeout
 
+	// L'état TLS pertinent est celui de l'hôte du lien, pas celui de la dernière redirection.
+	var originTLS *tls.ConnectionState
 	client := http.Client{
-		Timeout: 5 * time.Second,
+		Timeout:   timeout,
+		Transport: m.transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) == 1 && req.Response != nil {
+				originTLS = req.Response.TLS
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return checkRedirectTarget(req.URL, m.opts.AllowPrivateNetworks)
+		},
 	}
 
-	resp, err := client.Head(url)
+	var resp *http.Response
+	var err error
+	if withBody {
+		resp, err = client.Get(rawURL)
+	} else {
+		resp, err = client.Head(rawURL)
+	}
 
 	// : Effectuer une requête HEAD (plus légère que GET) sur l'URL.
 	// Un code de statut 2xx ou 3xx indique que l'URL est accessible.
 	// Si err : log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", url, err)
 
 	if err != nil {
-		log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", url, err)
-		return false
+		log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", rawURL, err)
+		result := probeResult{err: err}
+		if problem, ok := classifyCertError(err); ok && originTLS == nil {
+			// La vérification a échoué : récupérer la chaîne pour la décrire quand même.
+			certs, _ := fetchPeerCertificates(rawURL, newDialer(timeout, m.opts.AllowPrivateNetworks))
+			result.tls = newTLSReport(certs, time.Now())
+			result.tls.Valid = false
+			result.tls.Problem = problem
+			result.tls.Error = err.Error()
+		} else if originTLS != nil {
+			result.tls = newTLSReport(originTLS.PeerCertificates, time.Now())
+		}
+		return result
 	}
 
 	//  Assurez-vous de fermer le corps de la réponse pour libérer les ressources
 
 	defer resp.Body.Close()
 
+	result := probeResult{statusCode: resp.StatusCode, url: resp.Request.URL}
+	if originTLS == nil {
+		originTLS = resp.TLS
+	}
+	if originTLS != nil {
+		result.tls = newTLSReport(originTLS.PeerCertificates, time.Now())
+	}
+
 	// Déterminer l'accessibilité basée sur le code de statut HTTP.
-	return resp.StatusCode >= 200 && resp.StatusCode < 400 // Codes 2xx ou 3xx
-}
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 { // Codes 2xx ou 3xx
+		result.err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		return result
+	}
 
-// formatState est une fonction utilitaire pour rendre l'état plus lisible dans les logs.
-func formatState(accessible bool) string {
-	if accessible {
-		return "ACCESSIBLE"
+	if withBody {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, m.opts.ContentMaxBytes))
+		if err != nil {
+			result.err = fmt.Errorf("failed to read body: %w", err)
+			return result
+		}
+		result.body = body
 	}
-	return "INACCESSIBLE"
+	return result
 }
@@ -0,0 +1,197 @@
+// Package qr génère les QR codes des liens courts, en PNG ou en SVG, avec une taille,
+// un niveau de correction d'erreur, une marge et des couleurs paramétrables.
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Formats de sortie.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Bornes des paramètres.
+const (
+	MinSize   = 64
+	MaxSize   = 2048
+	MaxMargin = 16
+)
+
+// ErrInvalidOptions est retournée pour des paramètres de QR code invalides.
+var ErrInvalidOptions = errors.New("invalid QR code options")
+
+// Options décrit le QR code à générer.
+type Options struct {
+	Format     string      // FormatPNG ou FormatSVG
+	Size       int         // Côté de l'image en pixels (PNG) ou taille affichée (SVG)
+	Level      string      // Correction d'erreur : L (7 %), M (15 %), Q (25 %) ou H (30 %)
+	Margin     int         // Zone blanche autour du code, en modules (4 recommandés)
+	Foreground color.NRGBA // Couleur des modules
+	Background color.NRGBA // Couleur du fond (alpha 0 = transparent)
+}
+
+// DefaultOptions retourne un PNG de 256 pixels, correction M, marge de 4 modules, noir sur blanc.
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       256,
+		Level:      "M",
+		Margin:     4,
+		Foreground: color.NRGBA{A: 0xff},
+		Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+}
+
+// ContentType retourne le type MIME du format.
+func (o Options) ContentType() string {
+	if o.Format == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Validate vérifie les options.
+func (o Options) Validate() error {
+	if o.Format != FormatPNG && o.Format != FormatSVG {
+		return fmt.Errorf("%w: format must be png or svg", ErrInvalidOptions)
+	}
+	if o.Size < MinSize || o.Size > MaxSize {
+		return fmt.Errorf("%w: size must be between %d and %d", ErrInvalidOptions, MinSize, MaxSize)
+	}
+	if _, err := recoveryLevel(o.Level); err != nil {
+		return err
+	}
+	if o.Margin < 0 || o.Margin > MaxMargin {
+		return fmt.Errorf("%w: margin must be between 0 and %d", ErrInvalidOptions, MaxMargin)
+	}
+	return nil
+}
+
+func recoveryLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+	return 0, fmt.Errorf("%w: level must be L, M, Q or H", ErrInvalidOptions)
+}
+
+// ParseColor lit une couleur hexadécimale ("#1a2b3c", "1a2b3c", "#fff", "#1a2b3c80")
+// ou "transparent".
+func ParseColor(value string) (color.NRGBA, error) {
+	if strings.EqualFold(value, "transparent") {
+		return color.NRGBA{}, nil
+	}
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("%w: invalid color %q", ErrInvalidOptions, value)
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("%w: invalid color %q", ErrInvalidOptions, value)
+	}
+	return color.NRGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, nil
+}
+
+// Encode génère le QR code de content.
+func Encode(content string, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	level, _ := recoveryLevel(opts.Level)
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	code.DisableBorder = true // Marge appliquée ici, selon opts.Margin
+	modules := code.Bitmap()
+	if total := len(modules) + 2*opts.Margin; opts.Format == FormatPNG && opts.Size < total {
+		// Moins d'un pixel par module : le code ne serait plus lisible.
+		return nil, fmt.Errorf("%w: size must be at least %d for this link", ErrInvalidOptions, total)
+	}
+
+	if opts.Format == FormatSVG {
+		return encodeSVG(modules, opts), nil
+	}
+	return encodePNG(modules, opts)
+}
+
+// encodePNG dessine les modules sur une image de opts.Size pixels de côté.
+func encodePNG(modules [][]bool, opts Options) ([]byte, error) {
+	n := len(modules)
+	total := n + 2*opts.Margin
+	img := image.NewNRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	for y := 0; y < opts.Size; y++ {
+		my := y*total/opts.Size - opts.Margin
+		for x := 0; x < opts.Size; x++ {
+			mx := x*total/opts.Size - opts.Margin
+			c := opts.Background
+			if mx >= 0 && my >= 0 && mx < n && my < n && modules[my][mx] {
+				c = opts.Foreground
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeSVG décrit les modules en un seul chemin, une unité SVG par module.
+func encodeSVG(modules [][]bool, opts Options) []byte {
+	n := len(modules)
+	total := n + 2*opts.Margin
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, total, total)
+	if opts.Background.A > 0 {
+		fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`, total, total, svgColor(opts.Background))
+	}
+	fmt.Fprintf(&b, `<path fill="%s" d="`, svgColor(opts.Foreground))
+	for y, row := range modules {
+		for x := 0; x < n; x++ {
+			if !row[x] {
+				continue
+			}
+			// Modules contigus d'une ligne regroupés en un rectangle.
+			start := x
+			for x < n && row[x] {
+				x++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", start+opts.Margin, y+opts.Margin, x-start, x-start)
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.Bytes()
+}
+
+func svgColor(c color.NRGBA) string {
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("rgba(%d,%d,%d,%.3g)", c.R, c.G, c.B, float64(c.A)/255)
+}
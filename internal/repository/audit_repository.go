@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditRepository définit l'accès au journal d'audit des liens.
+type AuditRepository interface {
+	CreateAudit(audit *models.LinkAudit) error
+	ListAuditsByLinkID(linkID uint) ([]models.LinkAudit, error)
+}
+
+// GormAuditRepository est l'implémentation de AuditRepository utilisant GORM.
+type GormAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository crée et retourne une nouvelle instance de GormAuditRepository.
+func NewAuditRepository(db *gorm.DB) *GormAuditRepository {
+	return &GormAuditRepository{db: db}
+}
+
+// CreateAudit enregistre une entrée du journal.
+func (r *GormAuditRepository) CreateAudit(audit *models.LinkAudit) error {
+	return r.db.Create(audit).Error
+}
+
+// ListAuditsByLinkID retourne le journal d'un lien, du plus ancien au plus récent.
+func (r *GormAuditRepository) ListAuditsByLinkID(linkID uint) ([]models.LinkAudit, error) {
+	var audits []models.LinkAudit
+	err := r.db.Where("link_id = ?", linkID).Order("created_at, id").Find(&audits).Error
+	return audits, err
+}
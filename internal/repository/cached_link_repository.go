@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"container/list"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// CacheStats expose les compteurs du cache de redirection.
+type CacheStats struct {
+	Hits         uint64 `json:"hits"`          // Liens servis depuis le cache
+	NegativeHits uint64 `json:"negative_hits"` // Codes inconnus servis depuis le cache
+	Misses       uint64 `json:"misses"`        // Lectures transmises à la base
+	Evictions    uint64 `json:"evictions"`     // Entrées évincées faute de place
+	Entries      int    `json:"entries"`
+	Capacity     int    `json:"capacity"`
+}
+
+// CachedLinkRepository est un décorateur de LinkRepository qui garde en mémoire les
+// résultats de GetLinkByShortCode (LRU borné avec expiration).
+// Les codes inconnus sont aussi mis en cache, pour une durée plus courte, afin qu'une
+// énumération de codes ne se traduise pas par autant de requêtes en base.
+// Toute écriture passant par le décorateur invalide les entrées concernées.
+type CachedLinkRepository struct {
+	next        LinkRepository
+	capacity    int
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // shortCode -> élément de order
+	byID    map[uint]string          // LinkID -> shortCode, pour invalider après un changement de code
+	order   *list.List               // Du plus récemment utilisé au plus ancien
+
+	hits, negativeHits, misses, evictions atomic.Uint64
+}
+
+// cacheEntry est une entrée du cache ; link vaut nil pour une entrée négative.
+type cacheEntry struct {
+	shortCode string
+	link      *models.Link
+	expiresAt time.Time
+}
+
+// NewCachedLinkRepository enveloppe un LinkRepository avec un cache de capacity entrées.
+func NewCachedLinkRepository(next LinkRepository, capacity int, ttl, negativeTTL time.Duration) *CachedLinkRepository {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &CachedLinkRepository{
+		next:        next,
+		capacity:    capacity,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		entries:     make(map[string]*list.Element),
+		byID:        make(map[uint]string),
+		order:       list.New(),
+	}
+}
+
+// GetLinkByShortCode sert le lien depuis le cache si possible, sinon le lit en base
+// et mémorise le résultat (y compris l'absence de lien).
+func (r *CachedLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link, error) {
+	if entry, ok := r.lookup(shortCode); ok {
+		if entry.link == nil {
+			r.negativeHits.Add(1)
+			return nil, gorm.ErrRecordNotFound
+		}
+		r.hits.Add(1)
+		return copyLink(entry.link), nil
+	}
+
+	r.misses.Add(1)
+	link, err := r.next.GetLinkByShortCode(shortCode)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if r.negativeTTL > 0 {
+			r.store(shortCode, nil, r.negativeTTL)
+		}
+		return nil, err
+	case err != nil:
+		return nil, err
+	}
+
+	r.store(shortCode, copyLink(link), r.ttl)
+	return link, nil
+}
+
+// CreateLink crée le lien et oublie une éventuelle entrée négative pour son code.
+func (r *CachedLinkRepository) CreateLink(link *models.Link) error {
+	if err := r.next.CreateLink(link); err != nil {
+		return err
+	}
+	r.Invalidate(link.ShortCode)
+	return nil
+}
+
+// CreateLinks crée les liens et oublie les entrées négatives de leurs codes.
+func (r *CachedLinkRepository) CreateLinks(links []*models.Link) error {
+	if err := r.next.CreateLinks(links); err != nil {
+		return err
+	}
+	for _, link := range links {
+		r.Invalidate(link.ShortCode)
+	}
+	return nil
+}
+
+// UpdateLink met à jour le lien puis invalide son entrée (ancien et nouveau code).
+func (r *CachedLinkRepository) UpdateLink(link *models.Link) error {
+	err := r.next.UpdateLink(link)
+	r.invalidateLink(link)
+	return err
+}
+
+// UpdateLinkFields met à jour les colonnes indiquées du lien puis invalide son entrée.
+func (r *CachedLinkRepository) UpdateLinkFields(link *models.Link, columns ...string) error {
+	err := r.next.UpdateLinkFields(link, columns...)
+	r.invalidateLink(link)
+	return err
+}
+
+// SaveFetchedPreview enregistre l'aperçu relevé sur la destination puis invalide l'entrée du lien.
+func (r *CachedLinkRepository) SaveFetchedPreview(link *models.Link) error {
+	err := r.next.SaveFetchedPreview(link)
+	r.invalidateLink(link)
+	return err
+}
+
+// ReplaceRules remplace les règles de ciblage du lien puis invalide son entrée.
+func (r *CachedLinkRepository) ReplaceRules(link *models.Link, rules []models.LinkRule) error {
+	err := r.next.ReplaceRules(link, rules)
+	r.invalidateLink(link)
+	return err
+}
+
+// ReplaceVariants remplace les variantes A/B du lien puis invalide son entrée.
+func (r *CachedLinkRepository) ReplaceVariants(link *models.Link, variants []models.LinkVariant) error {
+	err := r.next.ReplaceVariants(link, variants)
+	r.invalidateLink(link)
+	return err
+}
+
+// DeleteLink supprime le lien puis invalide son entrée.
+func (r *CachedLinkRepository) DeleteLink(link *models.Link) error {
+	err := r.next.DeleteLink(link)
+	r.invalidateLink(link)
+	return err
+}
+
+// GetLinkByID n'est pas mis en cache (modération, moniteur, hors chemin de redirection).
+func (r *CachedLinkRepository) GetLinkByID(id uint) (*models.Link, error) {
+	return r.next.GetLinkByID(id)
+}
+
+// FindLinkByDestinationHash n'est pas mis en cache (création, hors chemin de redirection).
+func (r *CachedLinkRepository) FindLinkByDestinationHash(hash string) (*models.Link, error) {
+	return r.next.FindLinkByDestinationHash(hash)
+}
+
+// GetAllLinks n'est pas mis en cache (utilisé par le moniteur, hors chemin de redirection).
+func (r *CachedLinkRepository) GetAllLinks() ([]models.Link, error) {
+	return r.next.GetAllLinks()
+}
+
+// ListLinksByCampaign n'est pas mis en cache (statistiques, hors chemin de redirection).
+func (r *CachedLinkRepository) ListLinksByCampaign(campaign string, includeUnset bool) ([]models.Link, error) {
+	return r.next.ListLinksByCampaign(campaign, includeUnset)
+}
+
+// StreamLinks n'est pas mis en cache (export).
+func (r *CachedLinkRepository) StreamLinks(batchSize int, fn func(links []models.Link) error) error {
+	return r.next.StreamLinks(batchSize, fn)
+}
+
+// CountClicksByLinkID n'est pas mis en cache : le nombre de clics évolue en continu.
+func (r *CachedLinkRepository) CountClicksByLinkID(linkID uint) (int, error) {
+	return r.next.CountClicksByLinkID(linkID)
+}
+
+// CountClicksByRule n'est pas mis en cache : le nombre de clics évolue en continu.
+func (r *CachedLinkRepository) CountClicksByRule(linkID uint) (map[uint]int, error) {
+	return r.next.CountClicksByRule(linkID)
+}
+
+// CountClicksByVariant n'est pas mis en cache : le nombre de clics évolue en continu.
+func (r *CachedLinkRepository) CountClicksByVariant(linkID uint) (map[uint]int, error) {
+	return r.next.CountClicksByVariant(linkID)
+}
+
+// CountClicksByCountry n'est pas mis en cache : le nombre de clics évolue en continu.
+func (r *CachedLinkRepository) CountClicksByCountry(linkID uint) (map[string]int, error) {
+	return r.next.CountClicksByCountry(linkID)
+}
+
+// CountClicksBySource n'est pas mis en cache : le nombre de clics évolue en continu.
+func (r *CachedLinkRepository) CountClicksBySource(linkID uint) (map[string]int, error) {
+	return r.next.CountClicksBySource(linkID)
+}
+
+// Invalidate retire un code du cache.
+func (r *CachedLinkRepository) Invalidate(shortCode string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if elem, ok := r.entries[shortCode]; ok {
+		r.removeElement(elem)
+	}
+}
+
+// Purge vide entièrement le cache.
+func (r *CachedLinkRepository) Purge() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = make(map[string]*list.Element)
+	r.byID = make(map[uint]string)
+	r.order.Init()
+}
+
+// Stats retourne les compteurs du cache.
+func (r *CachedLinkRepository) Stats() CacheStats {
+	r.mu.Lock()
+	entries := r.order.Len()
+	r.mu.Unlock()
+
+	return CacheStats{
+		Hits:         r.hits.Load(),
+		NegativeHits: r.negativeHits.Load(),
+		Misses:       r.misses.Load(),
+		Evictions:    r.evictions.Load(),
+		Entries:      entries,
+		Capacity:     r.capacity,
+	}
+}
+
+func (r *CachedLinkRepository) invalidateLink(link *models.Link) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if previous, ok := r.byID[link.ID]; ok {
+		if elem, ok := r.entries[previous]; ok {
+			r.removeElement(elem)
+		}
+	}
+	if elem, ok := r.entries[link.ShortCode]; ok {
+		r.removeElement(elem)
+	}
+}
+
+// lookup retourne l'entrée non expirée d'un code et la marque comme récemment utilisée.
+func (r *CachedLinkRepository) lookup(shortCode string) (*cacheEntry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, ok := r.entries[shortCode]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		r.removeElement(elem)
+		return nil, false
+	}
+	r.order.MoveToFront(elem)
+	return entry, true
+}
+
+func (r *CachedLinkRepository) store(shortCode string, link *models.Link, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if elem, ok := r.entries[shortCode]; ok {
+		r.removeElement(elem)
+	}
+
+	entry := &cacheEntry{shortCode: shortCode, link: link, expiresAt: time.Now().Add(ttl)}
+	r.entries[shortCode] = r.order.PushFront(entry)
+	if link != nil {
+		r.byID[link.ID] = shortCode
+	}
+
+	for r.order.Len() > r.capacity {
+		r.removeElement(r.order.Back())
+		r.evictions.Add(1)
+	}
+}
+
+// removeElement retire une entrée ; r.mu doit être verrouillé.
+func (r *CachedLinkRepository) removeElement(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	r.order.Remove(elem)
+	delete(r.entries, entry.shortCode)
+	if entry.link != nil && r.byID[entry.link.ID] == entry.shortCode {
+		delete(r.byID, entry.link.ID)
+	}
+}
+
+// copyLink évite qu'un appelant modifie l'instance partagée par le cache : les règles,
+// les variantes, les métadonnées et les dates (pointeurs) sont copiées elles aussi.
+func copyLink(link *models.Link) *models.Link {
+	c := *link
+	c.Rules = slices.Clone(link.Rules)
+	c.Variants = slices.Clone(link.Variants)
+	c.Metadata = maps.Clone(link.Metadata)
+	c.DisabledAt = copyTime(link.DisabledAt)
+	c.PreviewFetchedAt = copyTime(link.PreviewFetchedAt)
+	return &c
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
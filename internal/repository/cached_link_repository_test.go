@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/database/dbtest"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+func TestCachedLinkIsNotSharedWithCallers(t *testing.T) {
+	db := dbtest.Migrated(t, dbtest.Targets(t)[0])
+	store := repository.NewLinkRepository(db)
+	cached := repository.NewCachedLinkRepository(store, 100, time.Minute, time.Minute)
+
+	disabledAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	link := &models.Link{
+		ShortCode:  "shared",
+		LongURL:    "https://example.com/",
+		Metadata:   map[string]string{"campaign": "rentree"},
+		DisabledAt: &disabledAt,
+		Rules:      []models.LinkRule{{Countries: "FR", Destination: "https://example.com/fr"}},
+		Variants:   []models.LinkVariant{{Name: "A", Destination: "https://example.com/a", Weight: 1}},
+	}
+	if err := cached.CreateLink(link); err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+
+	// Le premier appel remplit le cache, le second y lit : aucun ne doit pouvoir le modifier.
+	for i := 0; i < 2; i++ {
+		got, err := cached.GetLinkByShortCode("shared")
+		if err != nil {
+			t.Fatalf("GetLinkByShortCode: %v", err)
+		}
+		got.Rules[0].Destination = "https://evil.example/"
+		got.Variants[0].Destination = "https://evil.example/"
+		got.Metadata["campaign"] = "modifie"
+		*got.DisabledAt = time.Time{}
+	}
+
+	got, err := cached.GetLinkByShortCode("shared")
+	if err != nil {
+		t.Fatalf("GetLinkByShortCode: %v", err)
+	}
+	if got.Rules[0].Destination != "https://example.com/fr" {
+		t.Errorf("rule destination = %q, cached entry was modified", got.Rules[0].Destination)
+	}
+	if got.Variants[0].Destination != "https://example.com/a" {
+		t.Errorf("variant destination = %q, cached entry was modified", got.Variants[0].Destination)
+	}
+	if got.Metadata["campaign"] != "rentree" {
+		t.Errorf("metadata campaign = %q, cached entry was modified", got.Metadata["campaign"])
+	}
+	if got.DisabledAt == nil || !got.DisabledAt.Equal(disabledAt) {
+		t.Errorf("DisabledAt = %v, cached entry was modified", got.DisabledAt)
+	}
+}
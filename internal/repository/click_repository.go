@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 //	ClickRepository est une interface qui définit les méthodes d'accès aux données
@@ -14,6 +17,14 @@ type ClickRepository interface {
 	// Utilisé par LinkService pour les stats
 	CreateClick(click *models.Click) error
 	CountClicksByLinkID(linkID uint) (int, error)
+
+	// Utilisés par l'export et l'import
+	CreateClicks(clicks []models.Click) error
+	DeleteClicksByLinkID(linkID uint) error
+	StreamClicksByLinkID(linkID uint, batchSize int, fn func(clicks []models.Click) error) error
+	GetHistoricalClicks(linkID uint) (int, error)
+	SetHistoricalClicks(linkID uint, source string, clicks int) error
+	ExistingTokens(tokens []string) (map[string]bool, error)
 }
 
 // GormClickRepository est l'implémentation de l'interface ClickRepository utilisant GORM.
@@ -48,3 +59,67 @@ func (r *GormClickRepository) CountClicksByLinkID(linkID uint) (int, error) {
 
 	return int(count), nil // Convert the int64 count to an int
 }
+
+// CreateClicks insère un lot de clics en une seule requête.
+func (r *GormClickRepository) CreateClicks(clicks []models.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+	return r.db.Omit("Link").Create(&clicks).Error
+}
+
+// DeleteClicksByLinkID supprime tous les clics d'un lien.
+func (r *GormClickRepository) DeleteClicksByLinkID(linkID uint) error {
+	return r.db.Where("link_id = ?", linkID).Delete(&models.Click{}).Error
+}
+
+// StreamClicksByLinkID parcourt les clics d'un lien par lots, par ordre chronologique,
+// pour garder une mémoire constante même avec des millions de clics.
+func (r *GormClickRepository) StreamClicksByLinkID(linkID uint, batchSize int, fn func(clicks []models.Click) error) error {
+	var batch []models.Click
+	return r.db.Where("link_id = ?", linkID).Order("id").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
+// GetHistoricalClicks retourne le total de clics importé pour un lien (0 s'il n'y en a pas).
+func (r *GormClickRepository) GetHistoricalClicks(linkID uint) (int, error) {
+	var summaries []models.LinkClickSummary
+	if err := r.db.Where("link_id = ?", linkID).Limit(1).Find(&summaries).Error; err != nil {
+		return 0, err
+	}
+	if len(summaries) == 0 {
+		return 0, nil
+	}
+	return summaries[0].Clicks, nil
+}
+
+// SetHistoricalClicks enregistre (ou remplace) le total de clics importé pour un lien ;
+// un total nul supprime l'entrée.
+func (r *GormClickRepository) SetHistoricalClicks(linkID uint, source string, clicks int) error {
+	if clicks <= 0 {
+		return r.db.Where("link_id = ?", linkID).Delete(&models.LinkClickSummary{}).Error
+	}
+	summary := models.LinkClickSummary{LinkID: linkID, Source: source, Clicks: clicks, ImportedAt: time.Now()}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "link_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source", "clicks", "imported_at"}),
+	}).Create(&summary).Error
+}
+
+// ExistingTokens retourne, parmi les jetons de clic donnés, ceux déjà attribués à un clic.
+func (r *GormClickRepository) ExistingTokens(tokens []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(tokens) == 0 {
+		return existing, nil
+	}
+	var found []string
+	if err := r.db.Model(&models.Click{}).Where("token IN ?", tokens).Pluck("token", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, token := range found {
+		existing[token] = true
+	}
+	return existing, nil
+}
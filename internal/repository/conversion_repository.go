@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// GoalStats résume les conversions d'un lien pour un objectif.
+type GoalStats struct {
+	Goal                string
+	Conversions         int
+	AvgSecondsToConvert float64
+}
+
+// ConversionRepository définit l'accès aux conversions et aux clics qui les portent.
+type ConversionRepository interface {
+	GetClickByToken(token string) (*models.Click, error)
+	FindConversion(clickID uint, goal string) (*models.Conversion, error)
+	CreateConversion(conversion *models.Conversion) error
+	CountTrackedClicks(linkID uint) (int, error)
+	GoalStats(linkID uint) ([]GoalStats, error)
+	ListDelays(linkID uint, goal string) ([]int64, error)
+}
+
+// GormConversionRepository est l'implémentation de ConversionRepository utilisant GORM.
+type GormConversionRepository struct {
+	db *gorm.DB
+}
+
+// NewConversionRepository crée et retourne une nouvelle instance de GormConversionRepository.
+func NewConversionRepository(db *gorm.DB) *GormConversionRepository {
+	return &GormConversionRepository{db: db}
+}
+
+// GetClickByToken retrouve le clic porteur d'un jeton. gorm.ErrRecordNotFound si le jeton est inconnu.
+func (r *GormConversionRepository) GetClickByToken(token string) (*models.Click, error) {
+	var click models.Click
+	if err := r.db.Where("token = ?", token).First(&click).Error; err != nil {
+		return nil, err
+	}
+	return &click, nil
+}
+
+// FindConversion retourne la conversion d'un clic pour un objectif, ou gorm.ErrRecordNotFound.
+func (r *GormConversionRepository) FindConversion(clickID uint, goal string) (*models.Conversion, error) {
+	var conversion models.Conversion
+	if err := r.db.Where("click_id = ? AND goal = ?", clickID, goal).First(&conversion).Error; err != nil {
+		return nil, err
+	}
+	return &conversion, nil
+}
+
+// CreateConversion enregistre une conversion.
+func (r *GormConversionRepository) CreateConversion(conversion *models.Conversion) error {
+	return r.db.Create(conversion).Error
+}
+
+// CountTrackedClicks compte les clics d'un lien qui ont reçu un jeton de conversion.
+func (r *GormConversionRepository) CountTrackedClicks(linkID uint) (int, error) {
+	var count int64
+	err := r.db.Model(&models.Click{}).Where("link_id = ? AND token IS NOT NULL", linkID).Count(&count).Error
+	return int(count), err
+}
+
+// GoalStats regroupe les conversions d'un lien par objectif, les plus fréquents d'abord.
+func (r *GormConversionRepository) GoalStats(linkID uint) ([]GoalStats, error) {
+	var stats []GoalStats
+	err := r.db.Model(&models.Conversion{}).
+		Select("goal, COUNT(*) AS conversions, AVG(seconds_to_convert) AS avg_seconds_to_convert").
+		Where("link_id = ?", linkID).Group("goal").Order("conversions DESC, goal").Scan(&stats).Error
+	return stats, err
+}
+
+// ListDelays retourne les délais de conversion (en secondes) d'un objectif, par ordre croissant.
+func (r *GormConversionRepository) ListDelays(linkID uint, goal string) ([]int64, error) {
+	var delays []int64
+	err := r.db.Model(&models.Conversion{}).Where("link_id = ? AND goal = ?", linkID, goal).
+		Order("seconds_to_convert").Pluck("seconds_to_convert", &delays).Error
+	return delays, err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository stocke les réponses associées aux clés d'idempotence.
+type IdempotencyRepository interface {
+	Reserve(record *models.IdempotencyKey) (bool, error)
+	Find(key, scope string) (*models.IdempotencyKey, error)
+	Complete(record *models.IdempotencyKey) error
+	Release(record *models.IdempotencyKey) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// GormIdempotencyRepository est l'implémentation GORM de IdempotencyRepository.
+type GormIdempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository crée un GormIdempotencyRepository.
+func NewIdempotencyRepository(db *gorm.DB) *GormIdempotencyRepository {
+	return &GormIdempotencyRepository{db: db}
+}
+
+// Reserve insère la clé si elle n'existe pas encore ; retourne false si elle est déjà prise
+// (requête déjà traitée ou en cours de traitement).
+func (r *GormIdempotencyRepository) Reserve(record *models.IdempotencyKey) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Find retourne l'enregistrement d'une clé, ou nil si elle est inconnue.
+func (r *GormIdempotencyRepository) Find(key, scope string) (*models.IdempotencyKey, error) {
+	var records []models.IdempotencyKey
+	err := r.db.Where("idempotency_key = ? AND scope = ?", key, scope).Limit(1).Find(&records).Error
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	return &records[0], nil
+}
+
+// Complete enregistre la réponse de la requête d'origine.
+func (r *GormIdempotencyRepository) Complete(record *models.IdempotencyKey) error {
+	return r.db.Model(&models.IdempotencyKey{}).Where("id = ?", record.ID).Updates(map[string]any{
+		"status_code":   record.StatusCode,
+		"content_type":  record.ContentType,
+		"response_body": record.ResponseBody,
+	}).Error
+}
+
+// Release supprime une clé (réponse non mémorisable ou clé expirée).
+func (r *GormIdempotencyRepository) Release(record *models.IdempotencyKey) error {
+	return r.db.Delete(&models.IdempotencyKey{}, record.ID).Error
+}
+
+// DeleteExpired purge les clés dont la fenêtre est écoulée.
+func (r *GormIdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
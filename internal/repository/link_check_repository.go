@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// LinkCheckRepository définit l'accès aux résultats de vérification enregistrés par le moniteur.
+type LinkCheckRepository interface {
+	CreateCheck(check *models.LinkCheck) error
+	GetLatestCheck(linkID uint) (*models.LinkCheck, error)
+	GetLatestContentChange(linkID uint) (*models.LinkCheck, error)
+}
+
+// GormLinkCheckRepository est l'implémentation de LinkCheckRepository utilisant GORM.
+type GormLinkCheckRepository struct {
+	db *gorm.DB
+}
+
+// NewLinkCheckRepository crée et retourne une nouvelle instance de GormLinkCheckRepository.
+func NewLinkCheckRepository(db *gorm.DB) *GormLinkCheckRepository {
+	return &GormLinkCheckRepository{db: db}
+}
+
+// CreateCheck enregistre le résultat d'une vérification.
+func (r *GormLinkCheckRepository) CreateCheck(check *models.LinkCheck) error {
+	return r.db.Create(check).Error
+}
+
+// GetLatestCheck retourne la dernière vérification d'un lien, ou nil s'il n'y en a aucune.
+func (r *GormLinkCheckRepository) GetLatestCheck(linkID uint) (*models.LinkCheck, error) {
+	return r.latest(r.db.Where("link_id = ?", linkID))
+}
+
+// GetLatestContentChange retourne le dernier changement de contenu détecté, ou nil.
+func (r *GormLinkCheckRepository) GetLatestContentChange(linkID uint) (*models.LinkCheck, error) {
+	return r.latest(r.db.Where("link_id = ? AND content_changed = ?", linkID, true))
+}
+
+func (r *GormLinkCheckRepository) latest(query *gorm.DB) (*models.LinkCheck, error) {
+	var check models.LinkCheck
+	err := query.Order("checked_at DESC, id DESC").First(&check).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"fmt"
+
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
 )
@@ -11,9 +13,24 @@ import (
 
 type LinkRepository interface {
 	CreateLink(link *models.Link) error
+	CreateLinks(links []*models.Link) error
+	UpdateLink(link *models.Link) error
+	UpdateLinkFields(link *models.Link, columns ...string) error
+	SaveFetchedPreview(link *models.Link) error
+	ReplaceRules(link *models.Link, rules []models.LinkRule) error
+	ReplaceVariants(link *models.Link, variants []models.LinkVariant) error
+	DeleteLink(link *models.Link) error
 	GetLinkByShortCode(shortCode string) (*models.Link, error)
+	GetLinkByID(id uint) (*models.Link, error)
+	FindLinkByDestinationHash(hash string) (*models.Link, error)
 	GetAllLinks() ([]models.Link, error)
+	ListLinksByCampaign(campaign string, includeUnset bool) ([]models.Link, error)
+	StreamLinks(batchSize int, fn func(links []models.Link) error) error
 	CountClicksByLinkID(linkID uint) (int, error)
+	CountClicksByRule(linkID uint) (map[uint]int, error)
+	CountClicksByVariant(linkID uint) (map[uint]int, error)
+	CountClicksByCountry(linkID uint) (map[string]int, error)
+	CountClicksBySource(linkID uint) (map[string]int, error)
 }
 
 // :  GormLinkRepository est l'implémentation de LinkRepository utilisant GORM.
@@ -28,13 +45,145 @@ func NewLinkRepository(db *gorm.DB) *GormLinkRepository {
 	return &GormLinkRepository{db: db}
 }
 
-// CreateLink insère un nouveau lien dans la base de données.
+// CreateLink insère un nouveau lien dans la base de données, avec ses règles de ciblage
+// et ses variantes A/B.
 func (r *GormLinkRepository) CreateLink(link *models.Link) error {
 	//  1: Utiliser GORM pour créer un nouvel enregistrement (link) dans la table des liens.
-	return r.db.Create(link).Error
+	if len(link.Rules) == 0 && len(link.Variants) == 0 {
+		return r.db.Create(link).Error
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createLink(tx, link)
+	})
+}
+
+// CreateLinks insère plusieurs liens dans une seule transaction : soit tous sont créés, soit aucun.
+func (r *GormLinkRepository) CreateLinks(links []*models.Link) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, link := range links {
+			if err := createLink(tx, link); err != nil {
+				return fmt.Errorf("link %s: %w", link.ShortCode, err)
+			}
+		}
+		return nil
+	})
 }
 
-// GetLinkByShortCode récupère un lien de la base de données en utilisant son shortCode.
+// createLink insère un lien puis ses règles de ciblage et ses variantes dans la transaction tx.
+func createLink(tx *gorm.DB, link *models.Link) error {
+	if err := tx.Create(link).Error; err != nil {
+		return err
+	}
+	if err := createRules(tx, link); err != nil {
+		return err
+	}
+	return createVariants(tx, link)
+}
+
+func createRules(tx *gorm.DB, link *models.Link) error {
+	if len(link.Rules) == 0 {
+		return nil
+	}
+	for i := range link.Rules {
+		link.Rules[i].ID = 0
+		link.Rules[i].LinkID = link.ID
+		link.Rules[i].Position = i
+	}
+	return tx.Create(&link.Rules).Error
+}
+
+func createVariants(tx *gorm.DB, link *models.Link) error {
+	if len(link.Variants) == 0 {
+		return nil
+	}
+	for i := range link.Variants {
+		link.Variants[i].ID = 0
+		link.Variants[i].LinkID = link.ID
+		link.Variants[i].Position = i
+	}
+	return tx.Create(&link.Variants).Error
+}
+
+// ReplaceRules remplace l'ensemble des règles de ciblage d'un lien (liste vide = aucune règle).
+func (r *GormLinkRepository) ReplaceRules(link *models.Link, rules []models.LinkRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkRule{}).Error; err != nil {
+			return err
+		}
+		link.Rules = rules
+		return createRules(tx, link)
+	})
+}
+
+// ReplaceVariants remplace les variantes A/B d'un lien (liste vide = aucune variante)
+// et enregistre son réglage StickyVariants.
+func (r *GormLinkRepository) ReplaceVariants(link *models.Link, variants []models.LinkVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkVariant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(link).Update("sticky_variants", link.StickyVariants).Error; err != nil {
+			return err
+		}
+		link.Variants = variants
+		return createVariants(tx, link)
+	})
+}
+
+// UpdateLink enregistre toutes les modifications apportées à un lien existant.
+func (r *GormLinkRepository) UpdateLink(link *models.Link) error {
+	return r.db.Save(link).Error
+}
+
+// UpdateLinkFields enregistre uniquement les colonnes indiquées du lien (valeurs nulles comprises).
+// Contrairement à UpdateLink, elle n'écrase pas les autres champs modifiés entre-temps
+// par un autre processus (CLI, autre instance) depuis la lecture du lien, éventuellement en cache.
+func (r *GormLinkRepository) UpdateLinkFields(link *models.Link, columns ...string) error {
+	return r.db.Model(link).Select(columns).Updates(link).Error
+}
+
+// SaveFetchedPreview enregistre uniquement l'aperçu relevé sur la destination (og_fetched_*)
+// et sa date : le moniteur ne doit pas écraser les autres champs modifiés entre-temps.
+func (r *GormLinkRepository) SaveFetchedPreview(link *models.Link) error {
+	return r.db.Model(link).Updates(map[string]any{
+		"og_fetched_title":       link.FetchedPreview.Title,
+		"og_fetched_description": link.FetchedPreview.Description,
+		"og_fetched_image":       link.FetchedPreview.Image,
+		"preview_fetched_at":     link.PreviewFetchedAt,
+	}).Error
+}
+
+// DeleteLink supprime un lien ainsi que ses clics, ses totaux importés, son historique de vérifications,
+// ses signalements, ses règles de ciblage, ses variantes A/B et ses conversions.
+func (r *GormLinkRepository) DeleteLink(link *models.Link) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("link_id = ?", link.ID).Delete(&models.Click{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkCheck{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkClickSummary{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkReport{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkVariant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id = ?", link.ID).Delete(&models.Conversion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Link{}, link.ID).Error
+	})
+}
+
+// GetLinkByShortCode récupère un lien de la base de données en utilisant son shortCode,
+// avec ses règles de ciblage et ses variantes A/B (chemin de redirection).
 // Il renvoie gorm.ErrRecordNotFound si aucun lien n'est trouvé avec ce shortCode.
 func (r *GormLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link, error) {
 	var link models.Link
@@ -44,11 +193,52 @@ func (r *GormLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link,
 	if err != nil {
 		return nil, err
 	}
+	if err := r.loadTargets(&link); err != nil {
+		return nil, err
+	}
 	return &link, nil
 
 }
 
-// GetAllLinks récupère tous les liens de la base de données.
+// loadTargets charge les règles de ciblage et les variantes A/B d'un lien.
+func (r *GormLinkRepository) loadTargets(link *models.Link) error {
+	if err := r.db.Where("link_id = ?", link.ID).Order("position").Find(&link.Rules).Error; err != nil {
+		return err
+	}
+	return r.db.Where("link_id = ?", link.ID).Order("position").Find(&link.Variants).Error
+}
+
+// GetLinkByID récupère un lien par son identifiant, avec ses règles de ciblage et ses
+// variantes A/B, ou gorm.ErrRecordNotFound.
+func (r *GormLinkRepository) GetLinkByID(id uint) (*models.Link, error) {
+	var links []models.Link
+	if err := r.db.Where("id = ?", id).Limit(1).Find(&links).Error; err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err := r.loadTargets(&links[0]); err != nil {
+		return nil, err
+	}
+	return &links[0], nil
+}
+
+// FindLinkByDestinationHash retourne le plus ancien lien dont la destination normalisée a
+// cette empreinte, ou gorm.ErrRecordNotFound s'il n'y en a pas.
+func (r *GormLinkRepository) FindLinkByDestinationHash(hash string) (*models.Link, error) {
+	var links []models.Link
+	if err := r.db.Where("destination_hash = ?", hash).Order("id").Limit(1).Find(&links).Error; err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &links[0], nil
+}
+
+// GetAllLinks récupère tous les liens de la base de données, avec leurs règles de ciblage
+// et leurs variantes A/B (deux requêtes pour l'ensemble des liens).
 // Cette méthode est utilisée par le moniteur d'URLs.
 func (r *GormLinkRepository) GetAllLinks() ([]models.Link, error) {
 	var links []models.Link
@@ -57,11 +247,75 @@ func (r *GormLinkRepository) GetAllLinks() ([]models.Link, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := attachTargets(r.db, links); err != nil {
+		return nil, err
+	}
 	return links, nil
 
 }
 
-// CountClicksByLinkID compte le nombre total de clics pour un ID de lien donné.
+// ListLinksByCampaign retourne les liens de la campagne utm_campaign donnée, par ordre d'ID.
+// Avec includeUnset, les liens sans campagne (qui reçoivent la campagne par défaut) sont inclus.
+func (r *GormLinkRepository) ListLinksByCampaign(campaign string, includeUnset bool) ([]models.Link, error) {
+	var links []models.Link
+	query := r.db.Where("utm_campaign = ?", campaign)
+	if includeUnset {
+		query = r.db.Where("utm_campaign = ? OR utm_campaign = '' OR utm_campaign IS NULL", campaign)
+	}
+	if err := query.Order("id").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// StreamLinks parcourt tous les liens par lots, par ordre d'ID, sans les charger tous en mémoire.
+// Les liens sont chargés avec leurs règles de ciblage et leurs variantes A/B.
+// Cette méthode est utilisée par l'export.
+func (r *GormLinkRepository) StreamLinks(batchSize int, fn func(links []models.Link) error) error {
+	var batch []models.Link
+	return r.db.Order("id").FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		ids := make([]uint, len(batch))
+		for i := range batch {
+			ids[i] = batch[i].ID
+		}
+		if err := attachTargets(r.db.Where("link_id IN ?", ids), batch); err != nil {
+			return err
+		}
+		return fn(batch)
+	}).Error
+}
+
+// attachTargets charge en deux requêtes (restreintes par query) les règles de ciblage
+// et les variantes A/B des liens, et les rattache à chacun d'eux.
+func attachTargets(query *gorm.DB, links []models.Link) error {
+	byID := make(map[uint]*models.Link, len(links))
+	for i := range links {
+		links[i].Rules, links[i].Variants = nil, nil
+		byID[links[i].ID] = &links[i]
+	}
+	var rules []models.LinkRule
+	if err := query.Session(&gorm.Session{}).Order("link_id, position").Find(&rules).Error; err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if link, ok := byID[rule.LinkID]; ok {
+			link.Rules = append(link.Rules, rule)
+		}
+	}
+	var variants []models.LinkVariant
+	if err := query.Session(&gorm.Session{}).Order("link_id, position").Find(&variants).Error; err != nil {
+		return err
+	}
+	for _, variant := range variants {
+		if link, ok := byID[variant.LinkID]; ok {
+			link.Variants = append(link.Variants, variant)
+		}
+	}
+	return nil
+}
+
+// CountClicksByLinkID compte le nombre total de clics pour un ID de lien donné :
+// les clics enregistrés plus le total historique importé d'un autre raccourcisseur.
 func (r *GormLinkRepository) CountClicksByLinkID(linkID uint) (int, error) {
 	var count int64 // GORM retourne un int64 pour les comptes
 	//  4: Utiliser GORM pour compter les enregistrements dans la table 'clicks'
@@ -71,5 +325,85 @@ func (r *GormLinkRepository) CountClicksByLinkID(linkID uint) (int, error) {
 		return 0, err
 	}
 
-	return int(count), nil
+	var historical int64
+	err = r.db.Model(&models.LinkClickSummary{}).Where("link_id = ?", linkID).
+		Select("COALESCE(SUM(clicks), 0)").Scan(&historical).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count + historical), nil
+}
+
+// CountClicksByRule compte les clics enregistrés d'un lien pour chacune de ses règles de ciblage.
+func (r *GormLinkRepository) CountClicksByRule(linkID uint) (map[uint]int, error) {
+	var rows []struct {
+		RuleID uint
+		Clicks int
+	}
+	err := r.db.Model(&models.Click{}).Select("rule_id, COUNT(*) AS clicks").
+		Where("link_id = ? AND rule_id IS NOT NULL", linkID).Group("rule_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.RuleID] = row.Clicks
+	}
+	return counts, nil
+}
+
+// CountClicksByCountry compte les clics d'un lien par pays ; la clé "" regroupe les clics
+// dont le pays est inconnu (aucune base GeoIP, adresse privée, clics antérieurs).
+func (r *GormLinkRepository) CountClicksByCountry(linkID uint) (map[string]int, error) {
+	var rows []struct {
+		Country string
+		Clicks  int
+	}
+	err := r.db.Model(&models.Click{}).Select("COALESCE(country, '') AS country, COUNT(*) AS clicks").
+		Where("link_id = ?", linkID).Group("COALESCE(country, '')").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Country] += row.Clicks
+	}
+	return counts, nil
+}
+
+// CountClicksByVariant compte les clics enregistrés d'un lien pour chacune de ses variantes A/B.
+func (r *GormLinkRepository) CountClicksByVariant(linkID uint) (map[uint]int, error) {
+	var rows []struct {
+		VariantID uint
+		Clicks    int
+	}
+	err := r.db.Model(&models.Click{}).Select("variant_id, COUNT(*) AS clicks").
+		Where("link_id = ? AND variant_id IS NOT NULL", linkID).Group("variant_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.VariantID] = row.Clicks
+	}
+	return counts, nil
+}
+
+// CountClicksBySource compte les clics d'un lien par origine ; la clé "" regroupe les accès directs.
+func (r *GormLinkRepository) CountClicksBySource(linkID uint) (map[string]int, error) {
+	var rows []struct {
+		Source string
+		Clicks int
+	}
+	err := r.db.Model(&models.Click{}).Select("COALESCE(source, '') AS source, COUNT(*) AS clicks").
+		Where("link_id = ?", linkID).Group("COALESCE(source, '')").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Source] += row.Clicks
+	}
+	return counts, nil
 }
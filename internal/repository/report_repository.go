@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// ReportedLink résume les signalements d'un lien dans la file de modération.
+type ReportedLink struct {
+	LinkID  uint
+	Reports int
+	LastID  uint // Signalement le plus récent
+}
+
+// ReportRepository définit l'accès aux signalements d'abus.
+type ReportRepository interface {
+	CreateReport(report *models.LinkReport) error
+	HasOpenReportFrom(linkID uint, reporterIP string) (bool, error)
+	CountOpenReporters(linkID uint) (int, error)
+	ListReportedLinks(status string, limit int) ([]ReportedLink, error)
+	ListReports(linkID uint, status string, limit int) ([]models.LinkReport, error)
+	ResolveOpenReports(linkID uint, status, resolvedBy string, at time.Time) (int64, error)
+}
+
+// GormReportRepository est l'implémentation de ReportRepository utilisant GORM.
+type GormReportRepository struct {
+	db *gorm.DB
+}
+
+// NewReportRepository crée et retourne une nouvelle instance de GormReportRepository.
+func NewReportRepository(db *gorm.DB) *GormReportRepository {
+	return &GormReportRepository{db: db}
+}
+
+// CreateReport enregistre un signalement.
+func (r *GormReportRepository) CreateReport(report *models.LinkReport) error {
+	return r.db.Create(report).Error
+}
+
+// HasOpenReportFrom indique si cette adresse a déjà un signalement en attente sur le lien.
+func (r *GormReportRepository) HasOpenReportFrom(linkID uint, reporterIP string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.LinkReport{}).
+		Where("link_id = ? AND reporter_ip = ? AND status = ?", linkID, reporterIP, models.ReportStatusOpen).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountOpenReporters compte les adresses distinctes ayant un signalement en attente sur le lien.
+func (r *GormReportRepository) CountOpenReporters(linkID uint) (int, error) {
+	var count int64
+	err := r.db.Model(&models.LinkReport{}).
+		Where("link_id = ? AND status = ?", linkID, models.ReportStatusOpen).
+		Distinct("reporter_ip").Count(&count).Error
+	return int(count), err
+}
+
+// ListReportedLinks regroupe les signalements par lien, les plus signalés d'abord.
+// status vide = tous les signalements.
+func (r *GormReportRepository) ListReportedLinks(status string, limit int) ([]ReportedLink, error) {
+	var rows []ReportedLink
+	query := r.db.Model(&models.LinkReport{}).
+		Select("link_id, COUNT(*) AS reports, MAX(id) AS last_id").
+		Group("link_id").
+		Order("reports DESC, last_id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Scan(&rows).Error
+	return rows, err
+}
+
+// ListReports retourne les signalements d'un lien, du plus récent au plus ancien.
+func (r *GormReportRepository) ListReports(linkID uint, status string, limit int) ([]models.LinkReport, error) {
+	var reports []models.LinkReport
+	query := r.db.Where("link_id = ?", linkID).Order("id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&reports).Error
+	return reports, err
+}
+
+// ResolveOpenReports clôt les signalements en attente d'un lien avec le statut donné.
+func (r *GormReportRepository) ResolveOpenReports(linkID uint, status, resolvedBy string, at time.Time) (int64, error) {
+	res := r.db.Model(&models.LinkReport{}).
+		Where("link_id = ? AND status = ?", linkID, models.ReportStatusOpen).
+		Updates(map[string]any{"status": status, "resolved_by": resolvedBy, "resolved_at": at})
+	return res.RowsAffected, res.Error
+}
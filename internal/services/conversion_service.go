@@ -0,0 +1,184 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Erreurs retournées par l'enregistrement des conversions.
+var (
+	ErrInvalidConversion = errors.New("invalid conversion")
+	ErrUnknownClickToken = errors.New("unknown click token")
+	ErrConversionExpired = errors.New("conversion window expired")
+)
+
+// maxConversionGoal limite la taille du nom d'un objectif.
+const maxConversionGoal = 100
+
+// GoalConversions résume les conversions d'un lien pour un objectif.
+type GoalConversions struct {
+	Goal                   string
+	Conversions            int
+	Rate                   float64 // % des clics suivis qui ont converti
+	AvgSecondsToConvert    float64
+	MedianSecondsToConvert int64
+}
+
+// ConversionStats regroupe les conversions d'un lien : l'objectif du lien, puis tous les objectifs signalés.
+type ConversionStats struct {
+	TrackedClicks int // Clics ayant reçu un jeton
+	GoalConversions
+	Goals []GoalConversions
+}
+
+// ConversionService enregistre les conversions signalées par les sites de destination.
+type ConversionService struct {
+	conversionRepo repository.ConversionRepository
+	linkRepo       repository.LinkRepository
+	window         time.Duration // Délai maximal entre le clic et la conversion, 0 = illimité
+}
+
+// NewConversionService crée un ConversionService.
+func NewConversionService(conversionRepo repository.ConversionRepository, linkRepo repository.LinkRepository,
+	window time.Duration) *ConversionService {
+	return &ConversionService{conversionRepo: conversionRepo, linkRepo: linkRepo, window: window}
+}
+
+// NewClickToken génère un jeton de clic aléatoire (22 caractères, utilisable tel quel dans une URL).
+func NewClickToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// goalOf retourne l'objectif suivi par un lien.
+func goalOf(link *models.Link) string {
+	if link.ConversionGoal != "" {
+		return link.ConversionGoal
+	}
+	return models.DefaultConversionGoal
+}
+
+// RecordConversion enregistre la conversion du clic porteur du jeton, pour l'objectif goal
+// (celui du lien si vide). Un même clic ne convertit qu'une fois par objectif : un nouveau
+// signalement retourne la conversion existante avec created à false.
+func (s *ConversionService) RecordConversion(token, goal string, now time.Time) (conversion *models.Conversion, created bool, err error) {
+	token, goal = strings.TrimSpace(token), strings.TrimSpace(goal)
+	if token == "" || len(token) > 32 {
+		return nil, false, fmt.Errorf("%w: token must be 1 to 32 characters long", ErrInvalidConversion)
+	}
+	if len(goal) > maxConversionGoal {
+		return nil, false, fmt.Errorf("%w: goal must be at most %d characters long", ErrInvalidConversion, maxConversionGoal)
+	}
+
+	click, err := s.conversionRepo.GetClickByToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrUnknownClickToken
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch click: %w", err)
+	}
+	if goal == "" {
+		link, err := s.linkRepo.GetLinkByID(click.LinkID)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to fetch link: %w", err)
+		}
+		goal = goalOf(link)
+	}
+
+	if existing, err := s.conversionRepo.FindConversion(click.ID, goal); err == nil {
+		return existing, false, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, fmt.Errorf("failed to fetch conversion: %w", err)
+	}
+
+	delay := now.Sub(click.Timestamp)
+	if s.window > 0 && delay > s.window {
+		return nil, false, ErrConversionExpired
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	conversion = &models.Conversion{
+		ClickID:          click.ID,
+		LinkID:           click.LinkID,
+		Goal:             goal,
+		ClickedAt:        click.Timestamp,
+		SecondsToConvert: int64(delay / time.Second),
+	}
+	if err := s.conversionRepo.CreateConversion(conversion); err != nil {
+		// Deux signalements simultanés : l'index unique (clic, objectif) a retenu l'autre.
+		if existing, findErr := s.conversionRepo.FindConversion(click.ID, goal); findErr == nil {
+			return existing, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to save conversion: %w", err)
+	}
+	return conversion, true, nil
+}
+
+// Stats calcule les taux de conversion d'un lien et les délais entre clic et conversion.
+func (s *ConversionService) Stats(link *models.Link) (*ConversionStats, error) {
+	tracked, err := s.conversionRepo.CountTrackedClicks(link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tracked clicks: %w", err)
+	}
+	goals, err := s.conversionRepo.GoalStats(link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute conversion stats: %w", err)
+	}
+
+	stats := &ConversionStats{TrackedClicks: tracked, GoalConversions: GoalConversions{Goal: goalOf(link)}}
+	for _, g := range goals {
+		delays, err := s.conversionRepo.ListDelays(link.ID, g.Goal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list conversion delays: %w", err)
+		}
+		gc := GoalConversions{
+			Goal:                   g.Goal,
+			Conversions:            g.Conversions,
+			Rate:                   percent(g.Conversions, tracked),
+			AvgSecondsToConvert:    math.Round(g.AvgSecondsToConvert*10) / 10,
+			MedianSecondsToConvert: median(delays),
+		}
+		if gc.Goal == stats.Goal {
+			stats.GoalConversions = gc
+		}
+		stats.Goals = append(stats.Goals, gc)
+	}
+	return stats, nil
+}
+
+// median retourne la médiane d'une liste triée, 0 si elle est vide.
+func median(sorted []int64) int64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// validateConversions vérifie l'objectif demandé pour un lien ; nommer un objectif active le suivi.
+func validateConversions(opts *CreateLinkOptions) error {
+	opts.ConversionGoal = strings.TrimSpace(opts.ConversionGoal)
+	if len(opts.ConversionGoal) > maxConversionGoal {
+		return fmt.Errorf("%w: conversion goal must be at most %d characters long", ErrInvalidLinkOptions, maxConversionGoal)
+	}
+	if opts.ConversionGoal != "" {
+		opts.TrackConversions = true
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/axellelanca/urlshortener/internal/geoip"
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// SetGeoLocator branche la base GeoIP utilisée par les règles de ciblage par pays.
+// Sans base, ces règles ne correspondent jamais.
+func (s *LinkService) SetGeoLocator(locator *geoip.Locator) {
+	s.geo = locator
+}
+
+// VisitorCountry retourne le pays d'une adresse IP, ou "" s'il est inconnu.
+func (s *LinkService) VisitorCountry(ip string) string {
+	return s.geo.Country(ip)
+}
+
+// ClicksByCountry retourne le nombre de clics d'un lien par pays (clé "" = pays inconnu).
+func (s *LinkService) ClicksByCountry(link *models.Link) (map[string]int, error) {
+	counts, err := s.linkRepo.CountClicksByCountry(link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks by country: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,85 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/geoip/geoiptest"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+)
+
+func TestCountryRules(t *testing.T) {
+	linkService := services.NewLinkService(nil)
+	linkService.SetGeoLocator(geoiptest.Open(t))
+
+	rules := []models.LinkRule{
+		{ID: 1, Position: 0, Countries: "FR,BE", Destination: "https://example.com/fr"},
+		{ID: 2, Position: 1, Countries: "DE", OS: models.OSIOS, Destination: "https://example.com/de-ios"},
+		{ID: 3, Position: 2, Countries: "DE", Destination: "https://example.com/de"},
+	}
+	const iPhone = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+
+	tests := []struct {
+		name      string
+		ip        string
+		userAgent string
+		wantRule  uint // 0 = aucune règle (destination principale)
+	}{
+		{"France", geoiptest.IPParis, "", 1},
+		{"Belgique (IPv6)", geoiptest.IPBrussels, "", 1},
+		{"Allemagne, iOS", geoiptest.IPBerlin, iPhone, 2},
+		{"Allemagne, autre système", geoiptest.IPBerlin, "curl/8.0", 3},
+		{"pays sans règle", geoiptest.IPSwiss, "", 0},
+		{"adresse inconnue", geoiptest.IPUnknown, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visitor := services.NewVisitor(tt.userAgent, "", time.Now())
+			visitor.Country = linkService.VisitorCountry(tt.ip)
+
+			var got uint
+			if rule := services.MatchRule(rules, visitor); rule != nil {
+				got = rule.ID
+			}
+			if got != tt.wantRule {
+				t.Errorf("visitor from %s (country %q) matched rule %d, want %d", tt.ip, visitor.Country, got, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestCountryRulesWithoutGeoIP(t *testing.T) {
+	linkService := services.NewLinkService(nil)
+	rules := []models.LinkRule{{ID: 1, Countries: "FR", Destination: "https://example.com/fr"}}
+
+	visitor := services.NewVisitor("", "", time.Now())
+	visitor.Country = linkService.VisitorCountry(geoiptest.IPParis)
+	if visitor.Country != "" {
+		t.Fatalf("VisitorCountry without GeoIP database = %q, want empty", visitor.Country)
+	}
+	if rule := services.MatchRule(rules, visitor); rule != nil {
+		t.Errorf("country rule matched without GeoIP database: %+v", rule)
+	}
+}
+
+func TestNormalizeCountryRules(t *testing.T) {
+	linkService := services.NewLinkService(nil)
+
+	rules := []models.LinkRule{{Countries: " fr, be ,", Destination: "https://example.com/fr"}}
+	if err := linkService.NormalizeTargets(rules, nil); err != nil {
+		t.Fatalf("NormalizeTargets: %v", err)
+	}
+	if rules[0].Countries != "FR,BE" {
+		t.Errorf("Countries = %q, want %q", rules[0].Countries, "FR,BE")
+	}
+
+	for _, countries := range []string{"FRA", "F1", "france"} {
+		rules := []models.LinkRule{{Countries: countries, Destination: "https://example.com/"}}
+		err := linkService.NormalizeTargets(rules, nil)
+		if !errors.Is(err, services.ErrInvalidLinkOptions) {
+			t.Errorf("NormalizeTargets(countries %q) = %v, want ErrInvalidLinkOptions", countries, err)
+		}
+	}
+}
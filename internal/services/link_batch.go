@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+)
+
+// ErrBatchAborted est attribuée aux éléments valides d'un lot atomique qui n'a pas été créé
+// parce qu'un autre élément a échoué.
+var ErrBatchAborted = errors.New("not created: another item of the atomic batch failed")
+
+// BatchItem est un lien à créer dans un lot.
+type BatchItem struct {
+	LongURL string
+	Options CreateLinkOptions
+}
+
+// BatchResult est le résultat de la création d'un élément du lot : Link est renseigné
+// en cas de succès, Err sinon. Reused indique un lien existant réutilisé (voir CreateOrReuseLink).
+type BatchResult struct {
+	Link   *models.Link
+	Reused bool
+	Err    error
+}
+
+// CreateLinksBatch crée plusieurs liens. En mode atomique, les liens sont tous créés
+// dans une même transaction, ou aucun ne l'est ; sinon chaque élément est créé
+// indépendamment. Les résultats suivent l'ordre des éléments.
+func (s *LinkService) CreateLinksBatch(items []BatchItem, atomic bool) []BatchResult {
+	results := make([]BatchResult, len(items))
+	reserved := make(map[string]bool, len(items))
+	pending := make(map[string]*models.Link) // Destination -> lien du lot, pour la réutilisation
+
+	// Préparation : validation et attribution des codes, sans écriture.
+	failed := false
+	for i, item := range items {
+		longURL, err := s.normalizeURLs(item.LongURL, &item.Options)
+		if err != nil {
+			results[i].Err = err
+			failed = true
+			continue
+		}
+		item.LongURL = longURL
+
+		reuse := s.shouldReuse(item.Options)
+		if reuse {
+			hash := urlnorm.Hash(item.LongURL)
+			if link, ok := pending[hash]; ok {
+				results[i] = BatchResult{Link: link, Reused: true}
+				continue
+			}
+			existing, err := s.findReusable(item.LongURL, item.Options)
+			if err != nil {
+				results[i].Err = err
+				failed = true
+				continue
+			}
+			if existing != nil {
+				results[i] = BatchResult{Link: existing, Reused: true}
+				continue
+			}
+		}
+
+		link, err := s.prepareLink(item.LongURL, item.Options, reserved)
+		if err != nil {
+			results[i].Err = err
+			failed = true
+			continue
+		}
+		reserved[link.ShortCode] = true
+		if reuse {
+			pending[urlnorm.Hash(item.LongURL)] = link
+		}
+		results[i].Link = link
+	}
+
+	if atomic {
+		if failed {
+			abortBatch(results, ErrBatchAborted)
+			return results
+		}
+		links := make([]*models.Link, 0, len(results))
+		for i := range results {
+			if !results[i].Reused {
+				links = append(links, results[i].Link)
+			}
+		}
+		if err := s.linkRepo.CreateLinks(links); err != nil {
+			abortBatch(results, fmt.Errorf("failed to create links in database: %w", err))
+		}
+		return results
+	}
+
+	for i := range results {
+		if results[i].Err != nil || results[i].Reused {
+			continue
+		}
+		if err := s.linkRepo.CreateLink(results[i].Link); err != nil {
+			results[i] = BatchResult{Err: fmt.Errorf("failed to create link in database: %w", err)}
+		}
+	}
+	// Un élément qui réutilise un lien du lot dont la création a échoué échoue aussi.
+	for i := range results {
+		if results[i].Reused && results[i].Link.ID == 0 {
+			results[i] = BatchResult{Err: errors.New("not created: the item it duplicates failed")}
+		}
+	}
+	return results
+}
+
+// abortBatch marque comme non créés tous les éléments qui n'ont pas déjà leur propre erreur.
+func abortBatch(results []BatchResult, err error) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = BatchResult{Err: err}
+		}
+	}
+}
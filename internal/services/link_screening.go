@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/axellelanca/urlshortener/internal/threats"
+)
+
+// ErrDestinationBlocked est la cause des erreurs de destination listée (voir BlockedError).
+var ErrDestinationBlocked = errors.New("destination is blocked")
+
+// URLErrBlocked est le code retourné aux clients pour une destination listée.
+const URLErrBlocked = "destination_blocked"
+
+// BlockedError signale une URL qui figure dans une liste de menaces. La règle en cause
+// est conservée pour les journaux mais n'apparaît pas dans le message destiné aux clients.
+type BlockedError struct {
+	Field string
+	Match *threats.Match
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("%s: %s matches a threat list", ErrDestinationBlocked, e.Field)
+}
+
+func (e *BlockedError) Unwrap() error {
+	return ErrDestinationBlocked
+}
+
+// SetThreatScreener active le filtrage des destinations par les listes de menaces (nil = désactivé).
+func (s *LinkService) SetThreatScreener(screener *threats.Screener) {
+	s.screener = screener
+}
+
+// checkURL normalise une URL puis la confronte aux listes de menaces.
+func (s *LinkService) checkURL(field, raw string) (string, error) {
+	normalized, err := s.urlValidator.Normalize(field, raw)
+	if err != nil {
+		return "", err
+	}
+	if match := s.screener.Check(normalized); match != nil {
+		log.Printf("Destination bloquée (%s) : %s, règle %s", field, normalized, match)
+		return "", &BlockedError{Field: field, Match: match}
+	}
+	return normalized, nil
+}
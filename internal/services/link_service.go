@@ -6,22 +6,108 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 
+	"github.com/axellelanca/urlshortener/internal/geoip"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/threats"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
 )
 
 const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
+// ErrInvalidLinkOptions est retournée quand les options de création d'un lien sont incohérentes.
+var ErrInvalidLinkOptions = errors.New("invalid link options")
+
+// ErrAliasTaken est retournée quand l'alias demandé est déjà utilisé comme code court.
+var ErrAliasTaken = errors.New("alias already in use")
+
+// Contraintes sur les alias et les métadonnées fournis à la création.
+const (
+	minAliasLength     = 3
+	maxAliasLength     = 10 // Taille de la colonne short_code
+	maxMetadataEntries = 20
+	maxMetadataKey     = 64
+	maxMetadataValue   = 512
+)
+
+// reservedAliases ne peuvent pas servir de code court : ils masqueraient des routes du serveur.
+var reservedAliases = map[string]bool{"api": true, "health": true}
+
+// CreateLinkOptions regroupe les réglages facultatifs d'un lien à sa création.
+type CreateLinkOptions struct {
+	FailurePolicy    string // keep (défaut), unavailable ou fallback
+	FailureThreshold int    // 0 = seuil par défaut du moniteur
+	FallbackURL      string // Requise avec la politique fallback
+
+	ContentMonitoring bool // Surveiller les changements de contenu de la destination
+
+	Interstitial bool // Afficher une page intermédiaire avant chaque redirection
+
+	RedirectType int  // 301, 302, 307 ou 308 ; 0 = valeur de la configuration
+	ForwardQuery bool // Transmettre les paramètres de la requête à la destination
+	ForwardPath  bool // Transmettre les segments de chemin après le code court
+
+	UTM models.UTMParams // Paramètres UTM ajoutés à la destination lors de la redirection
+
+	Rules []models.LinkRule // Règles de ciblage, dans l'ordre d'évaluation
+
+	Variants       []models.LinkVariant // Destinations pondérées d'un test A/B (au moins deux)
+	StickyVariants bool                 // Garder chaque visiteur sur sa variante (cookie)
+
+	TrackConversions bool   // Ajouter un jeton de clic à la destination (suivi des conversions)
+	ConversionGoal   string // Objectif suivi (ex: "inscription") ; le nommer active le suivi
+
+	Preview models.LinkPreview // Aperçu Open Graph servi aux réseaux sociaux et messageries
+
+	Password string // Mot de passe exigé avant la redirection (enregistré haché), vide = lien public
+
+	RequireSignature bool // N'accepter que les URL signées et non expirées (voir URLSigner)
+
+	Monitoring MonitoringSettings
+
+	Alias    string            // Code court souhaité ; généré si vide
+	Metadata map[string]string // Métadonnées libres enregistrées avec le lien
+
+	// Retourner le lien existant vers la même destination (URL normalisée) au lieu d'en créer
+	// un nouveau ; nil = réglage du service. Sans effet quand un alias, des règles, des variantes,
+	// un mot de passe ou des URL signées sont demandés.
+	ReuseExisting *bool
+}
+
+// MonitoringSettings décrit les réglages de surveillance d'un lien.
+// Les champs nil ne sont pas modifiés lors d'une mise à jour.
+type MonitoringSettings struct {
+	Enabled         *bool
+	IntervalMinutes *int // 0 = intervalle global du moniteur
+	Priority        *int
+}
+
 type LinkService struct {
-	linkRepo repository.LinkRepository
+	linkRepo      repository.LinkRepository
+	reuseExisting bool
+	urlValidator  *URLValidator
+	screener      *threats.Screener // nil si aucune liste de menaces n'est configurée
+
+	redirectPolicy RedirectPolicy
+	utmDefaults    models.UTMParams // Paramètres UTM appliqués aux liens qui ne les renseignent pas
+
+	geo *geoip.Locator // nil si aucune base GeoIP n'est configurée
+
+	clickTokenParam string // Paramètre portant le jeton de clic des liens qui suivent les conversions
 }
 
 func NewLinkService(linkRepo repository.LinkRepository) *LinkService {
-	return &LinkService{linkRepo: linkRepo}
+	return &LinkService{
+		linkRepo:        linkRepo,
+		urlValidator:    NewURLValidator(DefaultURLPolicy()),
+		redirectPolicy:  DefaultRedirectPolicy(),
+		clickTokenParam: DefaultClickTokenParam,
+	}
 }
 
 // GenerateShortCode génère un short code sécurisé
@@ -39,28 +125,251 @@ func (s *LinkService) GenerateShortCode(length int) (string, error) {
 	return string(code), nil
 }
 
+// SetReuseExisting active par défaut la réutilisation des liens existants vers la même destination.
+func (s *LinkService) SetReuseExisting(enabled bool) {
+	s.reuseExisting = enabled
+}
+
+// SetURLPolicy remplace les règles de validation des URL de destination.
+func (s *LinkService) SetURLPolicy(policy URLPolicy) {
+	s.urlValidator = NewURLValidator(policy)
+}
+
+// NormalizeURL valide une URL de destination et retourne la forme à enregistrer.
+// Les erreurs sont des *URLError, ou des *BlockedError pour une destination listée.
+func (s *LinkService) NormalizeURL(raw string) (string, error) {
+	return s.checkURL("long_url", raw)
+}
+
+// NormalizeTargets valide et normalise sur place des règles de ciblage et des variantes A/B
+// (conditions, poids, destinations), comme à leur enregistrement par l'API ; utilisé par l'import.
+func (s *LinkService) NormalizeTargets(rules []models.LinkRule, variants []models.LinkVariant) error {
+	if err := s.normalizeRules(rules); err != nil {
+		return err
+	}
+	return s.normalizeVariants(variants)
+}
+
+// NormalizeLink valide et normalise sur place un lien complet (destination, URL de repli,
+// règles de ciblage, variantes A/B et réglages) comme à sa création par l'API ; utilisé par l'import.
+func (s *LinkService) NormalizeLink(link *models.Link) error {
+	opts := CreateLinkOptions{
+		FailurePolicy:    link.FailurePolicy,
+		FailureThreshold: link.FailureThreshold,
+		FallbackURL:      link.FallbackURL,
+		RedirectType:     link.RedirectType,
+		UTM:              link.UTM,
+		Rules:            link.Rules,
+		Variants:         link.Variants,
+		TrackConversions: link.TrackConversions,
+		ConversionGoal:   link.ConversionGoal,
+		Preview:          link.Preview,
+		Monitoring:       MonitoringSettings{IntervalMinutes: &link.MonitorIntervalMinutes},
+		Metadata:         link.Metadata,
+	}
+	longURL, err := s.normalizeURLs(link.LongURL, &opts)
+	if err != nil {
+		return err
+	}
+	if err := validateOptions(&opts); err != nil {
+		return err
+	}
+
+	link.LongURL = longURL
+	link.FailurePolicy = opts.FailurePolicy
+	link.FallbackURL = opts.FallbackURL
+	link.UTM = opts.UTM
+	link.Rules = opts.Rules
+	link.Variants = opts.Variants
+	link.TrackConversions = opts.TrackConversions
+	link.ConversionGoal = opts.ConversionGoal
+	link.Preview = opts.Preview
+	return nil
+}
+
+// normalizeURLs valide l'URL de destination, l'URL de repli et les règles de ciblage des options,
+// et retourne l'URL de destination normalisée.
+func (s *LinkService) normalizeURLs(longURL string, opts *CreateLinkOptions) (string, error) {
+	if opts.FallbackURL != "" {
+		fallback, err := s.checkURL("fallback_url", opts.FallbackURL)
+		if err != nil {
+			return "", err
+		}
+		opts.FallbackURL = fallback
+	}
+	if len(opts.Rules) > 0 {
+		// Copie : les options d'un lot partagent parfois la même liste de règles.
+		opts.Rules = append([]models.LinkRule(nil), opts.Rules...)
+		if err := s.normalizeRules(opts.Rules); err != nil {
+			return "", err
+		}
+	}
+	if len(opts.Variants) > 0 {
+		opts.Variants = append([]models.LinkVariant(nil), opts.Variants...)
+		if err := s.normalizeVariants(opts.Variants); err != nil {
+			return "", err
+		}
+	}
+	return s.NormalizeURL(longURL)
+}
+
+// CreateLink crée un lien court avec les options par défaut.
 func (s *LinkService) CreateLink(longURL string) (*models.Link, error) {
+	return s.CreateLinkWithOptions(longURL, CreateLinkOptions{})
+}
 
-	const maxRetries = 5
-	var shortCode string
+// CreateLinkWithOptions crée un lien court en appliquant les options fournies.
+func (s *LinkService) CreateLinkWithOptions(longURL string, opts CreateLinkOptions) (*models.Link, error) {
+	link, _, err := s.CreateOrReuseLink(longURL, opts)
+	return link, err
+}
 
-	for i := 0; i < maxRetries; i++ {
+// CreateOrReuseLink crée un lien court, ou retourne le lien existant vers la même destination
+// quand la réutilisation est active ; reused indique ce second cas. Le lien réutilisé est
+// retourné tel quel, avec ses propres options.
+func (s *LinkService) CreateOrReuseLink(longURL string, opts CreateLinkOptions) (link *models.Link, reused bool, err error) {
+	longURL, err = s.normalizeURLs(longURL, &opts)
+	if err != nil {
+		return nil, false, err
+	}
 
-		code, err := s.GenerateShortCode(6)
+	existing, err := s.findReusable(longURL, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		return existing, true, nil
+	}
+
+	link, err = s.prepareLink(longURL, opts, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := s.linkRepo.CreateLink(link); err != nil {
+		return nil, false, fmt.Errorf("failed to create link in database: %w", err)
+	}
+
+	return link, false, nil
+}
+
+// shouldReuse indique si la création doit d'abord chercher un lien existant.
+func (s *LinkService) shouldReuse(opts CreateLinkOptions) bool {
+	if opts.Alias != "" || len(opts.Rules) > 0 || len(opts.Variants) > 0 || opts.Password != "" || opts.RequireSignature {
+		return false
+	}
+	if opts.ReuseExisting != nil {
+		return *opts.ReuseExisting
+	}
+	return s.reuseExisting
+}
+
+// findReusable retourne le lien existant à réutiliser pour cette destination, ou nil.
+func (s *LinkService) findReusable(longURL string, opts CreateLinkOptions) (*models.Link, error) {
+	if !s.shouldReuse(opts) {
+		return nil, nil
+	}
+	link, err := s.linkRepo.FindLinkByDestinationHash(urlnorm.Hash(longURL))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error looking up existing link: %w", err)
+	}
+	// Un lien désactivé, protégé par mot de passe ou à URL signées n'est jamais réutilisé.
+	if link.Disabled || link.PasswordHash != "" || link.RequireSignature {
+		return nil, nil
+	}
+	return link, nil
+}
+
+// prepareLink valide les options et construit le lien à créer, avec un code court libre.
+// reserved contient les codes déjà attribués mais pas encore enregistrés (création par lot).
+func (s *LinkService) prepareLink(longURL string, opts CreateLinkOptions, reserved map[string]bool) (*models.Link, error) {
+	if err := validateOptions(&opts); err != nil {
+		return nil, err
+	}
+	passwordHash := ""
+	if opts.Password != "" {
+		hash, err := HashPassword(opts.Password)
+		if err != nil {
+			return nil, err
+		}
+		passwordHash = hash
+	}
+
+	var shortCode string
+	if opts.Alias != "" {
+		if err := ValidateAlias(opts.Alias); err != nil {
+			return nil, err
+		}
+		taken, err := s.codeTaken(opts.Alias, reserved)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, fmt.Errorf("%w: %s", ErrAliasTaken, opts.Alias)
+		}
+		shortCode = opts.Alias
+	} else {
+		code, err := s.uniqueShortCode(reserved)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate short code: %w", err)
+			return nil, err
 		}
+		shortCode = code
+	}
+
+	// Création du lien
+	link := &models.Link{
+		LongURL:          longURL,
+		ShortCode:        shortCode,
+		CreatedAt:        time.Now(),
+		FailurePolicy:    opts.FailurePolicy,
+		FailureThreshold: opts.FailureThreshold,
+		FallbackURL:      opts.FallbackURL,
+
+		ContentMonitoring: opts.ContentMonitoring,
+		Interstitial:      opts.Interstitial,
+		Metadata:          opts.Metadata,
+
+		RedirectType: opts.RedirectType,
+		ForwardQuery: opts.ForwardQuery,
+		ForwardPath:  opts.ForwardPath,
+		UTM:          opts.UTM,
+		Rules:        opts.Rules,
 
-		_, err = s.linkRepo.GetLinkByShortCode(code)
+		Variants:       opts.Variants,
+		StickyVariants: opts.StickyVariants,
 
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			shortCode = code
-			break
+		TrackConversions: opts.TrackConversions,
+		ConversionGoal:   opts.ConversionGoal,
+
+		Preview:      opts.Preview,
+		PasswordHash: passwordHash,
+
+		RequireSignature: opts.RequireSignature,
+	}
+	applyMonitoring(link, opts.Monitoring)
+	return link, nil
+}
+
+// uniqueShortCode génère un code court qui n'existe ni en base ni dans reserved.
+func (s *LinkService) uniqueShortCode(reserved map[string]bool) (string, error) {
+	const maxRetries = 5
+
+	for i := 0; i < maxRetries; i++ {
+
+		code, err := s.GenerateShortCode(6)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate short code: %w", err)
 		}
 
+		taken, err := s.codeTaken(code, reserved)
 		if err != nil {
-			// Vraie erreur DB
-			return nil, fmt.Errorf("database error checking code uniqueness: %w", err)
+			return "", err
+		}
+		if !taken {
+			return code, nil
 		}
 
 		log.Printf("Short code '%s' already exists, retrying (%d/%d)...",
@@ -68,24 +377,157 @@ func (s *LinkService) CreateLink(longURL string) (*models.Link, error) {
 	}
 
 	// Si jamais aucun code n'a été trouvé
-	if shortCode == "" {
-		return nil, errors.New("failed to generate a unique short code after several attempts")
+	return "", errors.New("failed to generate a unique short code after several attempts")
+}
+
+// codeTaken indique si un code est déjà utilisé en base ou réservé par le lot en cours.
+func (s *LinkService) codeTaken(code string, reserved map[string]bool) (bool, error) {
+	if reserved[code] {
+		return true, nil
 	}
+	_, err := s.linkRepo.GetLinkByShortCode(code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		// Vraie erreur DB
+		return false, fmt.Errorf("database error checking code uniqueness: %w", err)
+	}
+	return true, nil
+}
 
-	// Création du lien
-	link := &models.Link{
-		LongURL:   longURL,
-		ShortCode: shortCode,
-		CreatedAt: time.Now(),
+// ValidateAlias vérifie qu'un alias peut servir de code court.
+func ValidateAlias(alias string) error {
+	if len(alias) < minAliasLength || len(alias) > maxAliasLength {
+		return fmt.Errorf("%w: alias must be %d to %d characters long", ErrInvalidLinkOptions, minAliasLength, maxAliasLength)
+	}
+	for _, c := range alias {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return fmt.Errorf("%w: alias may only contain letters, digits, '-' and '_'", ErrInvalidLinkOptions)
+		}
 	}
+	if reservedAliases[strings.ToLower(alias)] {
+		return fmt.Errorf("%w: alias %q is reserved", ErrInvalidLinkOptions, alias)
+	}
+	return nil
+}
 
-	if err := s.linkRepo.CreateLink(link); err != nil {
-		return nil, fmt.Errorf("failed to create link in database: %w", err)
+// validateMetadata borne la taille des métadonnées libres.
+func validateMetadata(metadata map[string]string) error {
+	if len(metadata) > maxMetadataEntries {
+		return fmt.Errorf("%w: at most %d metadata entries", ErrInvalidLinkOptions, maxMetadataEntries)
+	}
+	for k, v := range metadata {
+		if k == "" || len(k) > maxMetadataKey {
+			return fmt.Errorf("%w: metadata keys must be 1 to %d characters long", ErrInvalidLinkOptions, maxMetadataKey)
+		}
+		if len(v) > maxMetadataValue {
+			return fmt.Errorf("%w: metadata value for %q exceeds %d characters", ErrInvalidLinkOptions, k, maxMetadataValue)
+		}
+	}
+	return nil
+}
+
+// validateOptions normalise et vérifie les réglages des options, hors URL et code court.
+func validateOptions(opts *CreateLinkOptions) error {
+	if err := validateFailurePolicy(opts); err != nil {
+		return err
+	}
+	if err := validateMonitoring(opts.Monitoring); err != nil {
+		return err
+	}
+	if err := validateRedirect(*opts); err != nil {
+		return err
+	}
+	if err := validateUTM(&opts.UTM); err != nil {
+		return err
+	}
+	if err := validateConversions(opts); err != nil {
+		return err
+	}
+	if err := validatePreview(&opts.Preview); err != nil {
+		return err
+	}
+	return validateMetadata(opts.Metadata)
+}
+
+// validateFailurePolicy normalise et vérifie la politique de panne demandée.
+func validateFailurePolicy(opts *CreateLinkOptions) error {
+	if opts.FailurePolicy == "" {
+		opts.FailurePolicy = models.FailurePolicyKeep
+	}
+
+	switch opts.FailurePolicy {
+	case models.FailurePolicyKeep, models.FailurePolicyUnavailable:
+	case models.FailurePolicyFallback:
+		if opts.FallbackURL == "" {
+			return fmt.Errorf("%w: fallback policy requires a fallback URL", ErrInvalidLinkOptions)
+		}
+	default:
+		return fmt.Errorf("%w: unknown failure policy %q", ErrInvalidLinkOptions, opts.FailurePolicy)
+	}
+
+	if opts.FailureThreshold < 0 {
+		return fmt.Errorf("%w: failure threshold must be positive", ErrInvalidLinkOptions)
+	}
+	return nil
+}
+
+// validateMonitoring vérifie les réglages de surveillance demandés.
+func validateMonitoring(settings MonitoringSettings) error {
+	if settings.IntervalMinutes != nil && *settings.IntervalMinutes < 0 {
+		return fmt.Errorf("%w: monitor interval must be positive", ErrInvalidLinkOptions)
 	}
+	return nil
+}
 
+// applyMonitoring reporte les réglages de surveillance renseignés sur le lien.
+func applyMonitoring(link *models.Link, settings MonitoringSettings) {
+	if settings.Enabled != nil {
+		link.MonitorDisabled = !*settings.Enabled
+	}
+	if settings.IntervalMinutes != nil {
+		link.MonitorIntervalMinutes = *settings.IntervalMinutes
+	}
+	if settings.Priority != nil {
+		link.MonitorPriority = *settings.Priority
+	}
+}
+
+// UpdateMonitoring modifie les réglages de surveillance d'un lien existant.
+func (s *LinkService) UpdateMonitoring(shortCode string, settings MonitoringSettings) (*models.Link, error) {
+	if err := validateMonitoring(settings); err != nil {
+		return nil, err
+	}
+
+	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch link: %w", err)
+	}
+
+	// Seules les colonnes de surveillance sont écrites : le lien lu peut venir du cache et
+	// ne doit pas annuler une modification faite entre-temps (ex: désactivation par la CLI).
+	applyMonitoring(link, settings)
+	if err := s.linkRepo.UpdateLinkFields(link, "monitor_disabled", "monitor_interval_minutes", "monitor_priority"); err != nil {
+		return nil, fmt.Errorf("failed to update link: %w", err)
+	}
 	return link, nil
 }
 
+// DeleteLink supprime un lien et ses statistiques.
+func (s *LinkService) DeleteLink(shortCode string) error {
+	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return fmt.Errorf("failed to fetch link: %w", err)
+	}
+	if err := s.linkRepo.DeleteLink(link); err != nil {
+		return fmt.Errorf("failed to delete link: %w", err)
+	}
+	return nil
+}
+
 func (s *LinkService) GetLinkByShortCode(shortCode string) (*models.Link, error) {
 	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
 	if err != nil {
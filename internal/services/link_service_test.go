@@ -0,0 +1,57 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/database/dbtest"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+)
+
+func TestUpdateMonitoringKeepsConcurrentChanges(t *testing.T) {
+	for _, target := range dbtest.Targets(t) {
+		t.Run(target.Name, func(t *testing.T) {
+			db := dbtest.Migrated(t, target)
+			store := repository.NewLinkRepository(db)
+			cached := repository.NewCachedLinkRepository(store, 100, time.Minute, time.Minute)
+			linkService := services.NewLinkService(cached)
+
+			link, err := linkService.CreateLink("https://example.com/page")
+			if err != nil {
+				t.Fatalf("CreateLink: %v", err)
+			}
+			// Met le lien en cache avant la modification faite par un autre processus.
+			if _, err := cached.GetLinkByShortCode(link.ShortCode); err != nil {
+				t.Fatalf("GetLinkByShortCode: %v", err)
+			}
+
+			// Désactivation par la CLI (autre processus) : le cache du serveur n'en sait rien.
+			other, err := store.GetLinkByShortCode(link.ShortCode)
+			if err != nil {
+				t.Fatalf("GetLinkByShortCode: %v", err)
+			}
+			other.Disabled = true
+			other.DisabledReason = "moderation"
+			if err := store.UpdateLink(other); err != nil {
+				t.Fatalf("UpdateLink: %v", err)
+			}
+
+			priority := 5
+			if _, err := linkService.UpdateMonitoring(link.ShortCode, services.MonitoringSettings{Priority: &priority}); err != nil {
+				t.Fatalf("UpdateMonitoring: %v", err)
+			}
+
+			got, err := store.GetLinkByShortCode(link.ShortCode)
+			if err != nil {
+				t.Fatalf("GetLinkByShortCode: %v", err)
+			}
+			if !got.Disabled || got.DisabledReason != "moderation" {
+				t.Errorf("UpdateMonitoring reverted the concurrent disable: disabled=%v reason=%q", got.Disabled, got.DisabledReason)
+			}
+			if got.MonitorPriority != priority {
+				t.Errorf("MonitorPriority = %d, want %d", got.MonitorPriority, priority)
+			}
+		})
+	}
+}
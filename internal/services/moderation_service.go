@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Actions de modération sur un lien signalé.
+const (
+	ModerationDismiss = "dismiss" // Classer les signalements sans suite (et retirer l'avertissement)
+	ModerationDisable = "disable" // Désactiver le lien
+	ModerationDelete  = "delete"  // Supprimer le lien
+)
+
+// ErrInvalidReport est retournée pour un signalement ou une action de modération invalide.
+var ErrInvalidReport = errors.New("invalid report")
+
+// maxReportReason limite la taille du motif d'un signalement.
+const maxReportReason = 1000
+
+// ReportQueueEntry est un lien de la file de modération avec ses signalements récents.
+type ReportQueueEntry struct {
+	Link    *models.Link
+	Reports int                 // Nombre de signalements au statut demandé
+	Latest  []models.LinkReport // Signalements les plus récents
+}
+
+// ModerationService gère les signalements d'abus et les décisions des modérateurs.
+type ModerationService struct {
+	linkRepo   repository.LinkRepository
+	reportRepo repository.ReportRepository
+	auditRepo  repository.AuditRepository
+	threshold  int // Signaleurs distincts avant la page d'avertissement, 0 = jamais
+}
+
+// NewModerationService crée un ModerationService.
+func NewModerationService(linkRepo repository.LinkRepository, reportRepo repository.ReportRepository,
+	auditRepo repository.AuditRepository, threshold int) *ModerationService {
+	return &ModerationService{linkRepo: linkRepo, reportRepo: reportRepo, auditRepo: auditRepo, threshold: threshold}
+}
+
+// ReportLink enregistre un signalement. Un même signaleur (adresse IP) n'est compté qu'une
+// fois par lien tant que ses signalements sont en attente. Quand le nombre de signaleurs
+// distincts atteint le seuil, le lien passe derrière la page d'avertissement.
+// Le lien est retourné ; gorm.ErrRecordNotFound (enveloppée) s'il n'existe pas.
+func (s *ModerationService) ReportLink(shortCode, reason, reporterIP string) (*models.Link, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxReportReason {
+		return nil, fmt.Errorf("%w: reason must be 1 to %d characters long", ErrInvalidReport, maxReportReason)
+	}
+
+	link, err := s.freshLink(shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch link: %w", err)
+	}
+
+	duplicate, err := s.reportRepo.HasOpenReportFrom(link.ID, reporterIP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing reports: %w", err)
+	}
+	if duplicate {
+		return link, nil
+	}
+
+	report := &models.LinkReport{
+		LinkID:     link.ID,
+		Reason:     reason,
+		ReporterIP: reporterIP,
+		Status:     models.ReportStatusOpen,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.reportRepo.CreateReport(report); err != nil {
+		return nil, fmt.Errorf("failed to store report: %w", err)
+	}
+
+	if s.threshold <= 0 || link.Flagged || link.Disabled {
+		return link, nil
+	}
+	reporters, err := s.reportRepo.CountOpenReporters(link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reports: %w", err)
+	}
+	if reporters >= s.threshold {
+		link.Flagged = true
+		if err := s.linkRepo.UpdateLink(link); err != nil {
+			return nil, fmt.Errorf("failed to flag link: %w", err)
+		}
+		s.audit(link, models.AuditActionFlagged, models.AuditActorReports,
+			fmt.Sprintf("%d distinct reporters", reporters))
+	}
+	return link, nil
+}
+
+// Queue retourne les liens signalés, les plus signalés d'abord. status filtre les
+// signalements (open par défaut ; "all" pour tous) et latest borne le nombre de
+// signalements détaillés par lien.
+func (s *ModerationService) Queue(status string, limit, latest int) ([]ReportQueueEntry, error) {
+	switch status {
+	case "":
+		status = models.ReportStatusOpen
+	case "all":
+		status = ""
+	case models.ReportStatusOpen, models.ReportStatusDismissed, models.ReportStatusActioned:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidReport, status)
+	}
+
+	rows, err := s.reportRepo.ListReportedLinks(status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reported links: %w", err)
+	}
+
+	entries := make([]ReportQueueEntry, 0, len(rows))
+	for _, row := range rows {
+		link, err := s.linkRepo.GetLinkByID(row.LinkID)
+		if err != nil {
+			// Lien supprimé entre-temps : ses signalements ne sont plus modérables.
+			log.Printf("Reported link %d not found, skipped: %v", row.LinkID, err)
+			continue
+		}
+		reports, err := s.reportRepo.ListReports(row.LinkID, status, latest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list reports: %w", err)
+		}
+		entries = append(entries, ReportQueueEntry{Link: link, Reports: row.Reports, Latest: reports})
+	}
+	return entries, nil
+}
+
+// Moderate applique la décision d'un modérateur sur un lien signalé et clôt ses signalements
+// en attente. note est consignée dans le journal d'audit.
+func (s *ModerationService) Moderate(shortCode, action, moderator, note string) (*models.Link, error) {
+	if moderator == "" {
+		moderator = "admin"
+	}
+	link, err := s.freshLink(shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch link: %w", err)
+	}
+	now := time.Now()
+
+	switch action {
+	case ModerationDismiss:
+		if link.Flagged {
+			link.Flagged = false
+			if err := s.linkRepo.UpdateLink(link); err != nil {
+				return nil, fmt.Errorf("failed to update link: %w", err)
+			}
+		}
+		if _, err := s.reportRepo.ResolveOpenReports(link.ID, models.ReportStatusDismissed, moderator, now); err != nil {
+			return nil, fmt.Errorf("failed to resolve reports: %w", err)
+		}
+		s.audit(link, models.AuditActionDismissed, moderator, note)
+
+	case ModerationDisable:
+		reason := "moderation"
+		if note != "" {
+			reason += ": " + note
+		}
+		if runes := []rune(reason); len(runes) > 255 {
+			reason = string(runes[:255]) // Taille de la colonne disabled_reason, en caractères
+		}
+		link.Disabled = true
+		link.DisabledReason = reason
+		link.DisabledAt = &now
+		if err := s.linkRepo.UpdateLink(link); err != nil {
+			return nil, fmt.Errorf("failed to update link: %w", err)
+		}
+		if _, err := s.reportRepo.ResolveOpenReports(link.ID, models.ReportStatusActioned, moderator, now); err != nil {
+			return nil, fmt.Errorf("failed to resolve reports: %w", err)
+		}
+		s.audit(link, models.AuditActionDisabled, moderator, note)
+
+	case ModerationDelete:
+		// Les signalements sont supprimés avec le lien : seul le journal d'audit en garde la trace.
+		if err := s.linkRepo.DeleteLink(link); err != nil {
+			return nil, fmt.Errorf("failed to delete link: %w", err)
+		}
+		s.audit(link, models.AuditActionDeleted, moderator, note)
+
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q (dismiss, disable or delete)", ErrInvalidReport, action)
+	}
+	return link, nil
+}
+
+// freshLink relit le lien hors cache avant une modification : le cache des redirections
+// peut ignorer une décision prise depuis un autre processus (CLI).
+func (s *ModerationService) freshLink(shortCode string) (*models.Link, error) {
+	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.linkRepo.GetLinkByID(link.ID)
+}
+
+// audit consigne une action ; un échec d'écriture est journalisé sans annuler l'action.
+func (s *ModerationService) audit(link *models.Link, action, actor, reason string) {
+	if s.auditRepo == nil {
+		return
+	}
+	err := s.auditRepo.CreateAudit(&models.LinkAudit{
+		LinkID:    link.ID,
+		ShortCode: link.ShortCode,
+		Action:    action,
+		Actor:     actor,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Error writing audit entry (%s) for link %s: %v", action, link.ShortCode, err)
+	}
+}
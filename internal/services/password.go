@@ -0,0 +1,272 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Contraintes sur les mots de passe des liens (bcrypt ignore ce qui dépasse 72 octets).
+const (
+	minPasswordLength = 4
+	maxPasswordLength = 72
+)
+
+// maxTrackedFailures borne le nombre de couples (IP, lien) suivis : au-delà, les fenêtres
+// expirées sont purgées, puis les plus anciennes (même bloquées) jusqu'à pruneTarget.
+const (
+	maxTrackedFailures = 10000
+	pruneTarget        = maxTrackedFailures * 9 / 10
+)
+
+var (
+	// ErrWrongPassword est retournée quand le mot de passe d'un lien protégé est incorrect.
+	ErrWrongPassword = errors.New("wrong password")
+	// ErrTooManyAttempts est retournée quand l'adresse IP a épuisé ses essais pour ce lien.
+	ErrTooManyAttempts = errors.New("too many failed password attempts")
+)
+
+// HashPassword valide le mot de passe d'un lien et retourne son empreinte bcrypt.
+func HashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return "", fmt.Errorf("%w: password must be %d to %d bytes long", ErrInvalidLinkOptions, minPasswordLength, maxPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// SetPassword protège un lien par un mot de passe, ou retire la protection si password est vide.
+// Changer le mot de passe invalide les déverrouillages en cours (voir PasswordGuard).
+func (s *LinkService) SetPassword(shortCode, password string) (*models.Link, error) {
+	hash := ""
+	if password != "" {
+		var err error
+		if hash, err = HashPassword(password); err != nil {
+			return nil, err
+		}
+	}
+	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch link: %w", err)
+	}
+	link.PasswordHash = hash
+	// Seule l'empreinte est écrite : le lien lu peut venir du cache (voir UpdateMonitoring).
+	if err := s.linkRepo.UpdateLinkFields(link, "password_hash"); err != nil {
+		return nil, fmt.Errorf("failed to update link: %w", err)
+	}
+	return link, nil
+}
+
+// PasswordGuard vérifie les mots de passe des liens protégés, signe les jetons de déverrouillage
+// (cookies) et limite les essais infructueux par adresse IP et par lien.
+type PasswordGuard struct {
+	key         []byte        // Clé HMAC des jetons
+	unlockTTL   time.Duration // Validité d'un déverrouillage
+	maxAttempts int           // Échecs tolérés avant blocage, 0 = pas de limite
+	lockout     time.Duration // Fenêtre de comptage des échecs et durée du blocage
+
+	mu       sync.Mutex
+	failures map[failureKey]*failureWindow
+}
+
+type failureKey struct {
+	linkID uint
+	ip     string
+}
+
+type failureWindow struct {
+	count       int
+	inflight    int       // Essais réservés dont le mot de passe est en cours de vérification
+	start       time.Time // Début de la fenêtre
+	lockedUntil time.Time
+}
+
+// NewPasswordGuard crée le garde des liens protégés.
+func NewPasswordGuard(key []byte, unlockTTL time.Duration, maxAttempts int, lockout time.Duration) *PasswordGuard {
+	return &PasswordGuard{
+		key:         key,
+		unlockTTL:   unlockTTL,
+		maxAttempts: maxAttempts,
+		lockout:     lockout,
+		failures:    make(map[failureKey]*failureWindow),
+	}
+}
+
+// NewPasswordGuardFromConfig crée le garde à partir de la section 'passwords' de la configuration.
+// Sans clé configurée, une clé aléatoire est tirée : les déverrouillages ne survivent pas au redémarrage.
+func NewPasswordGuardFromConfig(cfg config.PasswordsConfig) (*PasswordGuard, error) {
+	key := []byte(cfg.CookieSecret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate cookie key: %w", err)
+		}
+	}
+	return NewPasswordGuard(key, time.Duration(cfg.UnlockMinutes)*time.Minute, cfg.MaxAttempts,
+		time.Duration(cfg.LockoutMinutes)*time.Minute), nil
+}
+
+// UnlockTTL retourne la durée de validité d'un déverrouillage.
+func (g *PasswordGuard) UnlockTTL() time.Duration {
+	return g.unlockTTL
+}
+
+// Unlock vérifie le mot de passe proposé par ip pour le lien et retourne un jeton de déverrouillage.
+// Les erreurs sont ErrWrongPassword ou ErrTooManyAttempts (voir RetryAfter).
+func (g *PasswordGuard) Unlock(link *models.Link, ip, password string, now time.Time) (string, error) {
+	key := failureKey{linkID: link.ID, ip: ip}
+	w, ok := g.reserveAttempt(key, now)
+	if !ok {
+		return "", ErrTooManyAttempts
+	}
+	wrong := bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil
+	g.finishAttempt(key, w, wrong, now)
+	if wrong {
+		return "", ErrWrongPassword
+	}
+
+	expires := now.Add(g.unlockTTL).Unix()
+	return strconv.FormatInt(expires, 10) + "." + g.sign(link, expires), nil
+}
+
+// Verify indique si le jeton déverrouille encore le lien.
+func (g *PasswordGuard) Verify(link *models.Link, token string, now time.Time) bool {
+	exp, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return false
+	}
+	return hmac.Equal([]byte(mac), []byte(g.sign(link, expires)))
+}
+
+// RetryAfter retourne le temps restant avant que ip puisse de nouveau essayer un mot de passe
+// pour le lien (0 si elle n'est pas bloquée).
+func (g *PasswordGuard) RetryAfter(link *models.Link, ip string, now time.Time) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	w, ok := g.failures[failureKey{linkID: link.ID, ip: ip}]
+	switch {
+	case !ok:
+		return 0
+	case now.Before(w.lockedUntil):
+		return w.lockedUntil.Sub(now)
+	case w.count+w.inflight >= g.maxAttempts:
+		// Les derniers essais autorisés sont en cours de vérification.
+		return time.Second
+	}
+	return 0
+}
+
+// sign calcule la signature d'un jeton. L'empreinte du mot de passe en fait partie :
+// changer ou retirer le mot de passe invalide les jetons déjà délivrés.
+func (g *PasswordGuard) sign(link *models.Link, expires int64) string {
+	mac := hmac.New(sha256.New, g.key)
+	fmt.Fprintf(mac, "%d|%s|%d|%s", link.ID, link.ShortCode, expires, link.PasswordHash)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// reserveAttempt réserve un essai pour le couple (IP, lien) avant la vérification du mot de passe :
+// des requêtes simultanées ne peuvent pas dépasser ensemble maxAttempts. Elle retourne la fenêtre
+// à passer à finishAttempt (nil sans limite), ou false si le couple est bloqué ou n'a plus d'essai.
+func (g *PasswordGuard) reserveAttempt(key failureKey, now time.Time) (*failureWindow, bool) {
+	if g.maxAttempts <= 0 {
+		return nil, true
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	w, ok := g.failures[key]
+	if !ok {
+		if len(g.failures) >= maxTrackedFailures {
+			g.pruneFailures(now)
+		}
+		w = &failureWindow{start: now}
+		g.failures[key] = w
+	}
+	if now.Before(w.lockedUntil) {
+		return nil, false
+	}
+	if w.inflight == 0 && now.Sub(w.start) >= g.lockout {
+		w.count, w.start = 0, now // Fenêtre expirée : nouveau décompte
+	}
+	if w.count+w.inflight >= g.maxAttempts {
+		return nil, false
+	}
+	w.inflight++
+	return w, true
+}
+
+// finishAttempt libère l'essai réservé et compte l'échec ; maxAttempts échecs dans la fenêtre
+// bloquent le couple (IP, lien). Un succès efface les échecs.
+func (g *PasswordGuard) finishAttempt(key failureKey, w *failureWindow, failed bool, now time.Time) {
+	if w == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	w.inflight--
+	if !failed {
+		w.count = 0
+		if w.inflight == 0 && g.failures[key] == w {
+			delete(g.failures, key)
+		}
+		return
+	}
+	w.count++
+	if w.count >= g.maxAttempts {
+		w.lockedUntil = now.Add(g.lockout)
+		w.count, w.start = 0, now
+	}
+}
+
+// pruneFailures oublie les fenêtres expirées et les blocages terminés. Si la table reste trop
+// grande (nombreuses adresses bloquées), les fenêtres les plus anciennes sont oubliées aussi,
+// sauf celles dont un essai est en cours : la mémoire reste bornée.
+func (g *PasswordGuard) pruneFailures(now time.Time) {
+	for key, w := range g.failures {
+		if w.inflight == 0 && now.Sub(w.start) >= g.lockout && !now.Before(w.lockedUntil) {
+			delete(g.failures, key)
+		}
+	}
+	if len(g.failures) <= pruneTarget {
+		return
+	}
+
+	type entry struct {
+		key   failureKey
+		start time.Time
+	}
+	oldest := make([]entry, 0, len(g.failures))
+	for key, w := range g.failures {
+		if w.inflight == 0 {
+			oldest = append(oldest, entry{key, w.start})
+		}
+	}
+	sort.Slice(oldest, func(i, j int) bool { return oldest[i].start.Before(oldest[j].start) })
+	for _, e := range oldest {
+		if len(g.failures) <= pruneTarget {
+			break
+		}
+		delete(g.failures, e.key)
+	}
+}
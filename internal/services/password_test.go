@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+func protectedLink(t *testing.T, password string) *models.Link {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &models.Link{ID: 1, ShortCode: "secret", PasswordHash: string(hash)}
+}
+
+func TestUnlockLimitsConcurrentAttempts(t *testing.T) {
+	const maxAttempts = 3
+	guard := NewPasswordGuard([]byte("key"), time.Hour, maxAttempts, time.Minute)
+	link := protectedLink(t, "correct")
+	now := time.Now()
+
+	// Des essais simultanés ne doivent pas dépasser ensemble la limite.
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	results := make(map[error]int)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := guard.Unlock(link, "192.0.2.1", "wrong", now)
+			mu.Lock()
+			results[err]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if results[ErrWrongPassword] != maxAttempts {
+		t.Errorf("%d passwords checked, want %d (results: %v)", results[ErrWrongPassword], maxAttempts, results)
+	}
+	if results[ErrWrongPassword]+results[ErrTooManyAttempts] != 50 {
+		t.Errorf("unexpected results: %v", results)
+	}
+	if _, err := guard.Unlock(link, "192.0.2.1", "correct", now); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("correct password while locked: err = %v, want ErrTooManyAttempts", err)
+	}
+	if retry := guard.RetryAfter(link, "192.0.2.1", now); retry != time.Minute {
+		t.Errorf("RetryAfter = %v, want %v", retry, time.Minute)
+	}
+
+	// Une autre adresse n'est pas bloquée, et le blocage prend fin.
+	if _, err := guard.Unlock(link, "192.0.2.2", "correct", now); err != nil {
+		t.Errorf("other IP: %v", err)
+	}
+	if _, err := guard.Unlock(link, "192.0.2.1", "correct", now.Add(time.Minute)); err != nil {
+		t.Errorf("after lockout: %v", err)
+	}
+	if len(guard.failures) != 0 {
+		t.Errorf("%d failure windows left after successful unlocks, want 0", len(guard.failures))
+	}
+}
+
+func TestPasswordFailuresAreBounded(t *testing.T) {
+	guard := NewPasswordGuard([]byte("key"), time.Hour, 1, time.Hour)
+	now := time.Now()
+
+	// Adresses toutes bloquées : aucune fenêtre n'a expiré.
+	for i := 0; i < maxTrackedFailures; i++ {
+		key := failureKey{linkID: 1, ip: fmt.Sprintf("ip-%d", i)}
+		guard.failures[key] = &failureWindow{start: now.Add(time.Duration(i) * time.Millisecond), lockedUntil: now.Add(time.Hour)}
+	}
+	busy := failureKey{linkID: 1, ip: "ip-0"} // La plus ancienne, avec un essai en cours
+	guard.failures[busy].inflight = 1
+
+	later := now.Add(time.Minute)
+	w, ok := guard.reserveAttempt(failureKey{linkID: 1, ip: "new"}, later)
+	if !ok {
+		t.Fatal("new IP could not try a password")
+	}
+	guard.finishAttempt(failureKey{linkID: 1, ip: "new"}, w, true, later)
+
+	if n := len(guard.failures); n > pruneTarget+1 {
+		t.Errorf("%d failure windows tracked, want at most %d", n, pruneTarget+1)
+	}
+	if _, ok := guard.failures[busy]; !ok {
+		t.Error("window with an attempt in progress was evicted")
+	}
+	if _, ok := guard.failures[failureKey{linkID: 1, ip: fmt.Sprintf("ip-%d", maxTrackedFailures-1)}]; !ok {
+		t.Error("most recent window was evicted")
+	}
+	if _, ok := guard.failures[failureKey{linkID: 1, ip: "ip-1"}]; ok {
+		t.Error("oldest idle window was kept")
+	}
+}
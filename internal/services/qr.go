@@ -0,0 +1,23 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// QRContent retourne l'URL encodée dans le QR code d'un lien : l'URL courte marquée
+// ?src=qr, pour attribuer les scans aux QR codes dans les statistiques.
+func QRContent(baseURL, shortCode string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + shortCode + "?src=" + models.ClickSourceQR
+}
+
+// ClicksBySource retourne le nombre de clics d'un lien par origine (clé "" = accès direct).
+func (s *LinkService) ClicksBySource(link *models.Link) (map[string]int, error) {
+	counts, err := s.linkRepo.CountClicksBySource(link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks by source: %w", err)
+	}
+	return counts, nil
+}
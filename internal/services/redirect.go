@@ -0,0 +1,185 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Priorité appliquée quand un paramètre transmis existe aussi dans l'URL de destination.
+const (
+	QueryPrecedenceDestination = "destination" // La valeur de la destination est conservée
+	QueryPrecedenceIncoming    = "incoming"    // La valeur transmise remplace celle de la destination
+	QueryPrecedenceAppend      = "append"      // Les deux valeurs sont conservées
+)
+
+// DefaultClickTokenParam est le paramètre qui transmet le jeton de clic à la destination.
+const DefaultClickTokenParam = "sl_click"
+
+// RedirectPolicy regroupe les réglages globaux de redirection.
+type RedirectPolicy struct {
+	Status          int    // Code de redirection des liens sans RedirectType (301, 302, 307 ou 308)
+	QueryPrecedence string // Voir QueryPrecedence*
+}
+
+// DefaultRedirectPolicy redirige en 302 et donne la priorité aux paramètres de la destination.
+func DefaultRedirectPolicy() RedirectPolicy {
+	return RedirectPolicy{Status: http.StatusFound, QueryPrecedence: QueryPrecedenceDestination}
+}
+
+// NewRedirectPolicy construit la politique de redirection à partir de la section 'links' de la configuration.
+func NewRedirectPolicy(cfg config.LinksConfig) (RedirectPolicy, error) {
+	policy := DefaultRedirectPolicy()
+	if cfg.RedirectStatus != 0 {
+		if !validRedirectStatus(cfg.RedirectStatus) {
+			return policy, fmt.Errorf("invalid links.redirect_status %d (expected 301, 302, 307 or 308)", cfg.RedirectStatus)
+		}
+		policy.Status = cfg.RedirectStatus
+	}
+	switch cfg.QueryPrecedence {
+	case "":
+	case QueryPrecedenceDestination, QueryPrecedenceIncoming, QueryPrecedenceAppend:
+		policy.QueryPrecedence = cfg.QueryPrecedence
+	default:
+		return policy, fmt.Errorf("invalid links.query_precedence %q (expected destination, incoming or append)", cfg.QueryPrecedence)
+	}
+	return policy, nil
+}
+
+// SetRedirectPolicy remplace les réglages globaux de redirection.
+func (s *LinkService) SetRedirectPolicy(policy RedirectPolicy) {
+	s.redirectPolicy = policy
+}
+
+// SetClickTokenParam remplace le paramètre qui transmet le jeton de clic à la destination.
+func (s *LinkService) SetClickTokenParam(param string) {
+	if param != "" {
+		s.clickTokenParam = param
+	}
+}
+
+// RedirectStatus retourne le code de redirection effectif d'un lien.
+func (s *LinkService) RedirectStatus(link *models.Link) int {
+	if link.RedirectType != 0 {
+		return link.RedirectType
+	}
+	return s.redirectPolicy.Status
+}
+
+// ResolveDestination construit l'URL vers laquelle rediriger : target (URL longue ou de repli),
+// complétée du chemin supplémentaire et des paramètres de la requête si le lien les transmet,
+// puis des paramètres UTM du lien qui n'y figurent pas encore et du jeton de clic (conversions).
+func (s *LinkService) ResolveDestination(link *models.Link, target, extraPath, rawQuery, clickToken string) (string, error) {
+	forwardPath := link.ForwardPath && extraPath != "" && extraPath != "/"
+	forwardQuery := link.ForwardQuery && rawQuery != ""
+	utm := s.EffectiveUTM(link)
+	if !forwardPath && !forwardQuery && utm == (models.UTMParams{}) && clickToken == "" {
+		return target, nil
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse destination: %w", err)
+	}
+	if forwardPath {
+		// path.Clean depuis la racine : un "../" ne peut pas remonter au-dessus du chemin de la destination.
+		u = u.JoinPath(path.Clean("/" + extraPath))
+	}
+	if forwardQuery {
+		u.RawQuery = mergeQuery(u.RawQuery, rawQuery, s.redirectPolicy.QueryPrecedence)
+	}
+	u.RawQuery = applyUTM(u.RawQuery, utm)
+	if clickToken != "" {
+		// Le jeton fait foi : une valeur transmise par le visiteur est remplacée.
+		u.RawQuery = mergeQuery(u.RawQuery, s.clickTokenParam+"="+url.QueryEscape(clickToken), QueryPrecedenceIncoming)
+	}
+	return u.String(), nil
+}
+
+// mergeQuery fusionne deux chaînes de requête sans les réencoder, en conservant l'ordre
+// des paramètres de la destination puis celui des paramètres transmis.
+func mergeQuery(destination, incoming, precedence string) string {
+	if destination == "" {
+		return incoming
+	}
+	if precedence == QueryPrecedenceAppend {
+		return destination + "&" + incoming
+	}
+
+	destKeys := queryKeys(destination)
+	incomingKeys := queryKeys(incoming)
+	var pairs []string
+	for _, pair := range strings.Split(destination, "&") {
+		if pair == "" {
+			continue
+		}
+		if precedence == QueryPrecedenceIncoming && incomingKeys[queryKey(pair)] {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	for _, pair := range strings.Split(incoming, "&") {
+		if pair == "" {
+			continue
+		}
+		if precedence == QueryPrecedenceDestination && destKeys[queryKey(pair)] {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// WithoutQueryParam retire d'une chaîne de requête les paramètres nommés keys, sans réencoder les autres.
+func WithoutQueryParam(rawQuery string, keys ...string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	var pairs []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair != "" && !slices.Contains(keys, queryKey(pair)) {
+			pairs = append(pairs, pair)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func queryKeys(rawQuery string) map[string]bool {
+	keys := make(map[string]bool)
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair != "" {
+			keys[queryKey(pair)] = true
+		}
+	}
+	return keys
+}
+
+// queryKey retourne le nom décodé d'un paramètre "nom=valeur".
+func queryKey(pair string) string {
+	key, _, _ := strings.Cut(pair, "=")
+	if unescaped, err := url.QueryUnescape(key); err == nil {
+		return unescaped
+	}
+	return key
+}
+
+func validRedirectStatus(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// validateRedirect vérifie le code de redirection demandé pour un lien.
+func validateRedirect(opts CreateLinkOptions) error {
+	if opts.RedirectType != 0 && !validRedirectStatus(opts.RedirectType) {
+		return fmt.Errorf("%w: redirect type must be 301, 302, 307 or 308", ErrInvalidLinkOptions)
+	}
+	return nil
+}
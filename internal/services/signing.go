@@ -0,0 +1,206 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Paramètres de requête des URL signées. Ils sont retirés avant la redirection.
+const (
+	SignatureExpiresParam = "exp"  // Expiration (horodatage Unix)
+	SignatureKeyParam     = "kid"  // Identifiant de la clé de signature
+	SignatureBindParam    = "bind" // Noms des paramètres couverts par la signature, séparés par des virgules
+	SignatureParam        = "sig"  // Signature HMAC-SHA256 (base64url)
+)
+
+// minSigningSecret est la longueur minimale d'un secret de signature.
+const minSigningSecret = 16
+
+var (
+	// ErrSignatureMissing est retournée quand une URL qui doit être signée ne l'est pas.
+	ErrSignatureMissing = errors.New("signature required")
+	// ErrSignatureInvalid est retournée pour une signature fausse, une clé inconnue ou des paramètres modifiés.
+	ErrSignatureInvalid = errors.New("invalid signature")
+	// ErrSignatureExpired est retournée quand une signature valide a dépassé son expiration.
+	ErrSignatureExpired = errors.New("signature expired")
+	// ErrInvalidSigningRequest est retournée quand la demande de signature est incohérente.
+	ErrInvalidSigningRequest = errors.New("invalid signing request")
+)
+
+// SigningKey est une clé de signature identifiée.
+type SigningKey struct {
+	ID     string
+	Secret []byte
+}
+
+// URLSigner signe et vérifie les URL courtes limitées dans le temps. Plusieurs clés peuvent être
+// actives en même temps : toutes sont acceptées à la vérification, seule la clé courante signe.
+type URLSigner struct {
+	keys       map[string][]byte
+	activeKey  string
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+// NewURLSigner crée un signataire ; active désigne la clé qui signe (vide = la première).
+func NewURLSigner(keys []SigningKey, active string, defaultTTL, maxTTL time.Duration) (*URLSigner, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no signing key")
+	}
+	s := &URLSigner{keys: make(map[string][]byte, len(keys)), activeKey: active, defaultTTL: defaultTTL, maxTTL: maxTTL}
+	for _, key := range keys {
+		if key.ID == "" || strings.ContainsAny(key.ID, "&=#?") {
+			return nil, fmt.Errorf("invalid signing key id %q", key.ID)
+		}
+		if len(key.Secret) < minSigningSecret {
+			return nil, fmt.Errorf("signing key %q: secret must be at least %d characters long", key.ID, minSigningSecret)
+		}
+		if _, dup := s.keys[key.ID]; dup {
+			return nil, fmt.Errorf("duplicate signing key id %q", key.ID)
+		}
+		s.keys[key.ID] = key.Secret
+	}
+	if s.activeKey == "" {
+		s.activeKey = keys[0].ID
+	}
+	if _, ok := s.keys[s.activeKey]; !ok {
+		return nil, fmt.Errorf("active signing key %q is not configured", s.activeKey)
+	}
+	if s.maxTTL <= 0 {
+		s.maxTTL = 90 * 24 * time.Hour
+	}
+	if s.defaultTTL <= 0 || s.defaultTTL > s.maxTTL {
+		s.defaultTTL = min(24*time.Hour, s.maxTTL)
+	}
+	return s, nil
+}
+
+// NewURLSignerFromConfig crée le signataire à partir de la section 'signing' de la configuration.
+// Il retourne nil sans erreur si aucune clé n'est configurée : les liens qui exigent
+// une signature refusent alors toutes les requêtes.
+func NewURLSignerFromConfig(cfg config.SigningConfig) (*URLSigner, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, nil
+	}
+	keys := make([]SigningKey, 0, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		keys = append(keys, SigningKey{ID: k.ID, Secret: []byte(k.Secret)})
+	}
+	return NewURLSigner(keys, cfg.ActiveKey,
+		time.Duration(cfg.DefaultTTLHours)*time.Hour, time.Duration(cfg.MaxTTLDays)*24*time.Hour)
+}
+
+// ActiveKey retourne l'identifiant de la clé qui signe les nouvelles URL.
+func (s *URLSigner) ActiveKey() string {
+	return s.activeKey
+}
+
+// Sign retourne l'URL courte signée baseURL/shortCode, valable ttl (0 = durée par défaut).
+// Les paramètres params sont ajoutés à l'URL et couverts par la signature : les modifier
+// ou les retirer l'invalide.
+func (s *URLSigner) Sign(baseURL, shortCode string, params map[string]string, ttl time.Duration, now time.Time) (string, time.Time, error) {
+	if s == nil {
+		return "", time.Time{}, fmt.Errorf("%w: URL signing is not configured (signing.keys)", ErrInvalidSigningRequest)
+	}
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl < 0 || ttl > s.maxTTL {
+		return "", time.Time{}, fmt.Errorf("%w: validity must be positive and at most %v", ErrInvalidSigningRequest, s.maxTTL)
+	}
+
+	bound := url.Values{}
+	names := make([]string, 0, len(params))
+	for name, value := range params {
+		switch {
+		case name == "" || strings.Contains(name, ","):
+			return "", time.Time{}, fmt.Errorf("%w: invalid parameter name %q", ErrInvalidSigningRequest, name)
+		case name == SignatureExpiresParam || name == SignatureKeyParam || name == SignatureBindParam || name == SignatureParam:
+			return "", time.Time{}, fmt.Errorf("%w: parameter name %q is reserved", ErrInvalidSigningRequest, name)
+		}
+		bound.Set(name, value)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	expires := now.Add(ttl).Truncate(time.Second)
+	exp := expires.Unix()
+	query := url.Values{}
+	for name, values := range bound {
+		query[name] = values
+	}
+	query.Set(SignatureExpiresParam, strconv.FormatInt(exp, 10))
+	query.Set(SignatureKeyParam, s.activeKey)
+	if len(names) > 0 {
+		query.Set(SignatureBindParam, strings.Join(names, ","))
+	}
+	query.Set(SignatureParam, s.sign(s.keys[s.activeKey], shortCode, exp, s.activeKey, bound))
+	return strings.TrimRight(baseURL, "/") + "/" + shortCode + "?" + query.Encode(), expires, nil
+}
+
+// Verify vérifie la signature portée par la requête d'un lien. Les erreurs sont
+// ErrSignatureMissing, ErrSignatureInvalid ou ErrSignatureExpired.
+func (s *URLSigner) Verify(shortCode string, query url.Values, now time.Time) error {
+	sig := query.Get(SignatureParam)
+	if sig == "" {
+		return ErrSignatureMissing
+	}
+	if s == nil {
+		return fmt.Errorf("%w: URL signing is not configured", ErrSignatureInvalid)
+	}
+	exp, err := strconv.ParseInt(query.Get(SignatureExpiresParam), 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	kid := query.Get(SignatureKeyParam)
+	secret, ok := s.keys[kid]
+	if !ok {
+		return fmt.Errorf("%w: unknown key %q", ErrSignatureInvalid, kid)
+	}
+	bound := url.Values{}
+	if bind := query.Get(SignatureBindParam); bind != "" {
+		for _, name := range strings.Split(bind, ",") {
+			bound[name] = query[name]
+		}
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.sign(secret, shortCode, exp, kid, bound))) {
+		return ErrSignatureInvalid
+	}
+	// L'expiration n'est vérifiée qu'une fois la signature validée : elle est alors authentique.
+	if now.Unix() >= exp {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+// sign calcule la signature du code court, de l'expiration, de la clé et des paramètres liés.
+func (s *URLSigner) sign(secret []byte, shortCode string, exp int64, kid string, bound url.Values) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%d\n%s\n%s", shortCode, exp, kid, bound.Encode())
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SetRequireSignature active ou désactive l'exigence d'une URL signée pour un lien.
+func (s *LinkService) SetRequireSignature(shortCode string, required bool) (*models.Link, error) {
+	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch link: %w", err)
+	}
+	link.RequireSignature = required
+	// Seule l'exigence est écrite : le lien lu peut venir du cache (voir UpdateMonitoring).
+	if err := s.linkRepo.UpdateLinkFields(link, "require_signature"); err != nil {
+		return nil, fmt.Errorf("failed to update link: %w", err)
+	}
+	return link, nil
+}
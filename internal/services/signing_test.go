@@ -0,0 +1,158 @@
+package services_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/services"
+)
+
+var (
+	oldKey = services.SigningKey{ID: "2025", Secret: []byte("ancienne-cle-de-signature")}
+	newKey = services.SigningKey{ID: "2026", Secret: []byte("nouvelle-cle-de-signature")}
+)
+
+func newSigner(t *testing.T, active string, keys ...services.SigningKey) *services.URLSigner {
+	t.Helper()
+	signer, err := services.NewURLSigner(keys, active, time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("NewURLSigner: %v", err)
+	}
+	return signer
+}
+
+// signedQuery signe le lien "promo" et retourne les paramètres de l'URL obtenue.
+func signedQuery(t *testing.T, signer *services.URLSigner, params map[string]string, now time.Time) url.Values {
+	t.Helper()
+	signed, _, err := signer.Sign("https://sho.rt", "promo", params, time.Hour, now)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("parse %s: %v", signed, err)
+	}
+	if u.Path != "/promo" {
+		t.Fatalf("signed URL path = %q, want /promo", u.Path)
+	}
+	return u.Query()
+}
+
+func TestURLSignerVerify(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	signer := newSigner(t, oldKey.ID, oldKey)
+	params := map[string]string{"ref": "newsletter", "user": "42"}
+
+	tests := []struct {
+		name      string
+		shortCode string
+		edit      func(q url.Values)
+		at        time.Time
+		want      error
+	}{
+		{"valid", "promo", nil, now, nil},
+		{"unbound parameter added", "promo", func(q url.Values) { q.Set("utm_source", "x") }, now, nil},
+		{"just before expiry", "promo", nil, now.Add(time.Hour - time.Second), nil},
+		{"expired", "promo", nil, now.Add(time.Hour), services.ErrSignatureExpired},
+		{"no signature", "promo", func(q url.Values) { q.Del(services.SignatureParam) }, now, services.ErrSignatureMissing},
+		{"other short code", "autre", nil, now, services.ErrSignatureInvalid},
+		{"bound parameter changed", "promo", func(q url.Values) { q.Set("user", "43") }, now, services.ErrSignatureInvalid},
+		{"bound parameter removed", "promo", func(q url.Values) { q.Del("ref") }, now, services.ErrSignatureInvalid},
+		{"bound parameter repeated", "promo", func(q url.Values) { q.Add("user", "43") }, now, services.ErrSignatureInvalid},
+		{"bind list emptied", "promo", func(q url.Values) {
+			q.Del(services.SignatureBindParam)
+			q.Del("ref")
+			q.Del("user")
+		}, now, services.ErrSignatureInvalid},
+		{"bind list shortened", "promo", func(q url.Values) { q.Set(services.SignatureBindParam, "ref") }, now, services.ErrSignatureInvalid},
+		{"expiry extended", "promo", func(q url.Values) {
+			q.Set(services.SignatureExpiresParam, "4102444800")
+		}, now.Add(2 * time.Hour), services.ErrSignatureInvalid},
+		{"expiry not a number", "promo", func(q url.Values) { q.Set(services.SignatureExpiresParam, "demain") }, now, services.ErrSignatureInvalid},
+		{"unknown key", "promo", func(q url.Values) { q.Set(services.SignatureKeyParam, "inconnue") }, now, services.ErrSignatureInvalid},
+		{"signature altered", "promo", func(q url.Values) {
+			sig := []byte(q.Get(services.SignatureParam))
+			sig[0] ^= 1
+			q.Set(services.SignatureParam, string(sig))
+		}, now, services.ErrSignatureInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := signedQuery(t, signer, params, now)
+			if tt.edit != nil {
+				tt.edit(query)
+			}
+			err := signer.Verify(tt.shortCode, query, tt.at)
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLSignerKeyRotation(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	before := newSigner(t, oldKey.ID, oldKey)
+	during := newSigner(t, newKey.ID, oldKey, newKey)
+	after := newSigner(t, newKey.ID, newKey)
+
+	// Pendant la rotation, les URL signées avec l'ancienne clé restent valables.
+	issuedBefore := signedQuery(t, before, nil, now)
+	if err := during.Verify("promo", issuedBefore, now); err != nil {
+		t.Errorf("URL signed with the previous key rejected during rotation: %v", err)
+	}
+
+	// Les nouvelles URL sont signées avec la clé active.
+	issuedDuring := signedQuery(t, during, nil, now)
+	if kid := issuedDuring.Get(services.SignatureKeyParam); kid != newKey.ID {
+		t.Errorf("new URL signed with key %q, want %q", kid, newKey.ID)
+	}
+	if err := after.Verify("promo", issuedDuring, now); err != nil {
+		t.Errorf("URL signed with the active key rejected after rotation: %v", err)
+	}
+
+	// Une fois l'ancienne clé retirée, ses URL sont refusées.
+	if err := after.Verify("promo", issuedBefore, now); !errors.Is(err, services.ErrSignatureInvalid) {
+		t.Errorf("URL signed with a retired key: Verify = %v, want ErrSignatureInvalid", err)
+	}
+	// Une clé de même identifiant mais de secret différent ne valide pas la signature.
+	forged := newSigner(t, oldKey.ID, services.SigningKey{ID: oldKey.ID, Secret: []byte("un-autre-secret-assez-long")})
+	if err := forged.Verify("promo", issuedBefore, now); !errors.Is(err, services.ErrSignatureInvalid) {
+		t.Errorf("URL verified with another secret: Verify = %v, want ErrSignatureInvalid", err)
+	}
+}
+
+func TestURLSignerWithoutKeys(t *testing.T) {
+	var signer *services.URLSigner // Aucune clé configurée (NewURLSignerFromConfig retourne nil)
+	now := time.Now()
+	query := signedQuery(t, newSigner(t, "", oldKey), nil, now)
+	if err := signer.Verify("promo", query, now); !errors.Is(err, services.ErrSignatureInvalid) {
+		t.Errorf("Verify without keys = %v, want ErrSignatureInvalid", err)
+	}
+	if err := signer.Verify("promo", url.Values{}, now); !errors.Is(err, services.ErrSignatureMissing) {
+		t.Errorf("Verify of an unsigned URL without keys = %v, want ErrSignatureMissing", err)
+	}
+	if _, _, err := signer.Sign("https://sho.rt", "promo", nil, 0, now); !errors.Is(err, services.ErrInvalidSigningRequest) {
+		t.Errorf("Sign without keys = %v, want ErrInvalidSigningRequest", err)
+	}
+}
+
+func TestURLSignerSignRejectsInvalidRequests(t *testing.T) {
+	signer := newSigner(t, "", oldKey)
+	now := time.Now()
+	for name, tt := range map[string]struct {
+		params map[string]string
+		ttl    time.Duration
+	}{
+		"reserved parameter": {map[string]string{services.SignatureParam: "x"}, 0},
+		"comma in name":      {map[string]string{"a,b": "x"}, 0},
+		"validity too long":  {nil, 48 * time.Hour},
+		"negative validity":  {nil, -time.Minute},
+	} {
+		if _, _, err := signer.Sign("https://sho.rt", "promo", tt.params, tt.ttl, now); !errors.Is(err, services.ErrInvalidSigningRequest) {
+			t.Errorf("%s: Sign = %v, want ErrInvalidSigningRequest", name, err)
+		}
+	}
+}
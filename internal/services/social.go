@@ -0,0 +1,91 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// previewCrawlers sont les fragments (en minuscules) des User-Agent des robots qui construisent
+// l'aperçu d'un lien collé dans un réseau social ou une messagerie. Les moteurs de recherche
+// n'en font pas partie : ils doivent suivre la redirection.
+var previewCrawlers = []string{
+	"facebookexternalhit", "facebookcatalog", "facebot", // Facebook, Messenger, iMessage
+	"twitterbot",
+	"linkedinbot",
+	"slackbot", "slack-imgproxy",
+	"discordbot",
+	"telegrambot",
+	"whatsapp",
+	"skypeuripreview", "microsoftpreview", // Skype, Teams, Outlook
+	"pinterest",
+	"redditbot",
+	"mastodon",
+	"cardyb", // Bluesky
+	"mattermost",
+	"viber",
+	"embedly", "iframely", "vkshare",
+}
+
+// IsPreviewCrawler indique si le User-Agent est celui d'un robot d'aperçu de lien
+// (réseau social, messagerie), qui reçoit les balises Open Graph au lieu d'une redirection.
+func IsPreviewCrawler(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, crawler := range previewCrawlers {
+		if strings.Contains(ua, crawler) {
+			return true
+		}
+	}
+	return false
+}
+
+// SocialPreview retourne l'aperçu d'un lien : les champs saisis, complétés par ceux relevés
+// sur la destination par le moniteur.
+func (s *LinkService) SocialPreview(link *models.Link) models.LinkPreview {
+	return link.Preview.WithDefaults(link.FetchedPreview)
+}
+
+// SetPreview remplace l'aperçu saisi d'un lien (champs vides = valeurs relevées sur la destination).
+func (s *LinkService) SetPreview(shortCode string, preview models.LinkPreview) (*models.Link, error) {
+	if err := validatePreview(&preview); err != nil {
+		return nil, err
+	}
+	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch link: %w", err)
+	}
+	link.Preview = preview
+	// Seul l'aperçu saisi est écrit : le lien lu peut venir du cache (voir UpdateMonitoring).
+	if err := s.linkRepo.UpdateLinkFields(link, "og_title", "og_description", "og_image"); err != nil {
+		return nil, fmt.Errorf("failed to update link: %w", err)
+	}
+	return link, nil
+}
+
+// validatePreview nettoie et vérifie l'aperçu demandé.
+func validatePreview(preview *models.LinkPreview) error {
+	preview.Title = strings.TrimSpace(preview.Title)
+	if utf8.RuneCountInString(preview.Title) > models.MaxPreviewTitle {
+		return fmt.Errorf("%w: og_title exceeds %d characters", ErrInvalidLinkOptions, models.MaxPreviewTitle)
+	}
+	preview.Description = strings.TrimSpace(preview.Description)
+	if utf8.RuneCountInString(preview.Description) > models.MaxPreviewDescription {
+		return fmt.Errorf("%w: og_description exceeds %d characters", ErrInvalidLinkOptions, models.MaxPreviewDescription)
+	}
+	preview.Image = strings.TrimSpace(preview.Image)
+	if preview.Image == "" {
+		return nil
+	}
+	if len(preview.Image) > models.MaxPreviewImage {
+		return fmt.Errorf("%w: og_image exceeds %d characters", ErrInvalidLinkOptions, models.MaxPreviewImage)
+	}
+	// Les robots ne téléchargent l'image que depuis une URL absolue en http(s).
+	u, err := url.Parse(preview.Image)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: og_image must be an absolute http(s) URL", ErrInvalidLinkOptions)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// maxLinkRules borne le nombre de règles de ciblage d'un lien.
+const maxLinkRules = 20
+
+// Visitor décrit la requête de redirection telle que vue par les règles de ciblage.
+// Il est construit une seule fois par requête, puis confronté aux règles du lien.
+type Visitor struct {
+	OS       string    // Voir models.OS*, vide si inconnu
+	Device   string    // Voir models.Device*
+	Language string    // Langue préférée (Accept-Language), en minuscules
+	Country  string    // Pays ISO (base GeoIP), vide si inconnu ; voir LinkService.VisitorCountry
+	Now      time.Time // Heure de la requête
+}
+
+// NewVisitor analyse les en-têtes User-Agent et Accept-Language d'une requête.
+func NewVisitor(userAgent, acceptLanguage string, now time.Time) Visitor {
+	os, device := parseUserAgent(userAgent)
+	return Visitor{OS: os, Device: device, Language: preferredLanguage(acceptLanguage), Now: now}
+}
+
+// MatchRule retourne la première règle (par position) dont toutes les conditions
+// correspondent au visiteur, ou nil. Les règles sont déjà triées par le repository.
+func MatchRule(rules []models.LinkRule, v Visitor) *models.LinkRule {
+	for i := range rules {
+		if ruleMatches(&rules[i], v) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func ruleMatches(rule *models.LinkRule, v Visitor) bool {
+	if rule.OS != "" && rule.OS != v.OS {
+		return false
+	}
+	if rule.Device != "" && rule.Device != v.Device {
+		return false
+	}
+	if rule.Languages != "" && !languageMatches(rule.Languages, v.Language) {
+		return false
+	}
+	if rule.Countries != "" && (v.Country == "" || !containsCode(rule.Countries, v.Country)) {
+		return false
+	}
+	if rule.TimeFrom != "" && !inTimeWindow(rule, v.Now) {
+		return false
+	}
+	return true
+}
+
+// languageMatches indique si la langue du visiteur figure dans la liste de la règle.
+// "fr" couvre "fr-ch" ; "fr-ca" ne couvre que "fr-ca".
+func languageMatches(languages, language string) bool {
+	if language == "" {
+		return false
+	}
+	for _, want := range strings.Split(languages, ",") {
+		if language == want || strings.HasPrefix(language, want+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsCode indique si code figure dans une liste séparée par des virgules.
+func containsCode(list, code string) bool {
+	for _, c := range strings.Split(list, ",") {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// inTimeWindow indique si now tombe dans la plage [TimeFrom, TimeTo) de la règle,
+// dans son fuseau. Une plage dont la fin précède le début passe minuit.
+func inTimeWindow(rule *models.LinkRule, now time.Time) bool {
+	from, err1 := parseClock(rule.TimeFrom)
+	to, err2 := parseClock(rule.TimeTo)
+	loc, err3 := loadLocation(rule.Timezone)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return false // Règle validée à l'enregistrement : ne devrait pas arriver
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// parseClock convertit "HH:MM" en minutes depuis minuit.
+func parseClock(value string) (int, error) {
+	h, m, ok := strings.Cut(value, ":")
+	if !ok || len(h) != 2 || len(m) != 2 {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", value)
+	}
+	hour, err := strconv.Atoi(h)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", value)
+	}
+	min, err := strconv.Atoi(m)
+	if err != nil || min < 0 || min > 59 {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", value)
+	}
+	return hour*60 + min, nil
+}
+
+// locations évite de relire la base des fuseaux horaires à chaque redirection.
+var locations sync.Map // nom -> *time.Location
+
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// parseUserAgent déduit le système et le type d'appareil d'un User-Agent.
+func parseUserAgent(ua string) (os, device string) {
+	switch {
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPod"):
+		return models.OSIOS, models.DeviceMobile
+	case strings.Contains(ua, "iPad"):
+		return models.OSIOS, models.DeviceTablet
+	case strings.Contains(ua, "Android"):
+		// Les tablettes Android n'annoncent pas "Mobile".
+		if strings.Contains(ua, "Mobile") {
+			return models.OSAndroid, models.DeviceMobile
+		}
+		return models.OSAndroid, models.DeviceTablet
+	case strings.Contains(ua, "CrOS"):
+		return models.OSChromeOS, models.DeviceDesktop
+	case strings.Contains(ua, "Windows"):
+		if strings.Contains(ua, "Windows Phone") {
+			return models.OSWindows, models.DeviceMobile
+		}
+		return models.OSWindows, models.DeviceDesktop
+	case strings.Contains(ua, "Macintosh"), strings.Contains(ua, "Mac OS X"):
+		return models.OSMacOS, models.DeviceDesktop
+	case strings.Contains(ua, "Linux"):
+		return models.OSLinux, models.DeviceDesktop
+	}
+	if strings.Contains(ua, "Mobi") {
+		return "", models.DeviceMobile
+	}
+	return "", models.DeviceDesktop
+}
+
+// preferredLanguage retourne la langue de plus haute priorité d'un en-tête Accept-Language
+// (ex: "fr-CH, fr;q=0.9, en;q=0.8" -> "fr-ch"), ou "" s'il n'y en a pas.
+func preferredLanguage(header string) string {
+	type tag struct {
+		lang string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if lang == "" || lang == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, tag{lang, q})
+		}
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	// Tri stable : à priorité égale, l'ordre de l'en-tête est conservé.
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	return tags[0].lang
+}
+
+// normalizeRules valide les règles demandées, normalise leurs conditions et leurs destinations.
+func (s *LinkService) normalizeRules(rules []models.LinkRule) error {
+	if len(rules) > maxLinkRules {
+		return fmt.Errorf("%w: at most %d targeting rules", ErrInvalidLinkOptions, maxLinkRules)
+	}
+	for i := range rules {
+		rule := &rules[i]
+		field := fmt.Sprintf("rules[%d]", i)
+
+		rule.OS = strings.ToLower(strings.TrimSpace(rule.OS))
+		switch rule.OS {
+		case "", models.OSIOS, models.OSAndroid, models.OSWindows, models.OSMacOS, models.OSLinux, models.OSChromeOS:
+		default:
+			return fmt.Errorf("%w: %s: unknown os %q (ios, android, windows, macos, linux or chromeos)", ErrInvalidLinkOptions, field, rule.OS)
+		}
+
+		rule.Device = strings.ToLower(strings.TrimSpace(rule.Device))
+		switch rule.Device {
+		case "", models.DeviceMobile, models.DeviceTablet, models.DeviceDesktop:
+		default:
+			return fmt.Errorf("%w: %s: unknown device %q (mobile, tablet or desktop)", ErrInvalidLinkOptions, field, rule.Device)
+		}
+
+		var languages []string
+		for _, lang := range strings.Split(rule.Languages, ",") {
+			if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+				languages = append(languages, lang)
+			}
+		}
+		rule.Languages = strings.Join(languages, ",")
+		if len(rule.Languages) > 255 {
+			return fmt.Errorf("%w: %s: too many languages", ErrInvalidLinkOptions, field)
+		}
+
+		var countries []string
+		for _, country := range strings.Split(rule.Countries, ",") {
+			if country = strings.ToUpper(strings.TrimSpace(country)); country == "" {
+				continue
+			}
+			if !validCountryCode(country) {
+				return fmt.Errorf("%w: %s: invalid country %q (ISO 3166-1 alpha-2 code expected)", ErrInvalidLinkOptions, field, country)
+			}
+			countries = append(countries, country)
+		}
+		rule.Countries = strings.Join(countries, ",")
+		if len(rule.Countries) > 255 {
+			return fmt.Errorf("%w: %s: too many countries", ErrInvalidLinkOptions, field)
+		}
+
+		if (rule.TimeFrom == "") != (rule.TimeTo == "") {
+			return fmt.Errorf("%w: %s: time_from and time_to must be set together", ErrInvalidLinkOptions, field)
+		}
+		if rule.TimeFrom != "" {
+			from, err := parseClock(rule.TimeFrom)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrInvalidLinkOptions, field, err)
+			}
+			to, err := parseClock(rule.TimeTo)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrInvalidLinkOptions, field, err)
+			}
+			if from == to {
+				return fmt.Errorf("%w: %s: empty time window", ErrInvalidLinkOptions, field)
+			}
+		}
+		if _, err := loadLocation(rule.Timezone); err != nil {
+			return fmt.Errorf("%w: %s: unknown timezone %q", ErrInvalidLinkOptions, field, rule.Timezone)
+		}
+
+		if rule.OS == "" && rule.Device == "" && rule.Languages == "" && rule.Countries == "" && rule.TimeFrom == "" {
+			return fmt.Errorf("%w: %s: a rule needs at least one condition", ErrInvalidLinkOptions, field)
+		}
+
+		destination, err := s.checkURL(field+".destination", rule.Destination)
+		if err != nil {
+			return err
+		}
+		rule.Destination = destination
+	}
+	return nil
+}
+
+// validCountryCode vérifie la forme d'un code pays ISO 3166-1 alpha-2 (deux lettres majuscules).
+func validCountryCode(code string) bool {
+	return len(code) == 2 && code[0] >= 'A' && code[0] <= 'Z' && code[1] >= 'A' && code[1] <= 'Z'
+}
+
+// SetRules remplace les règles de ciblage d'un lien.
+func (s *LinkService) SetRules(shortCode string, rules []models.LinkRule) (*models.Link, error) {
+	if err := s.normalizeRules(rules); err != nil {
+		return nil, err
+	}
+	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch link: %w", err)
+	}
+	if err := s.linkRepo.ReplaceRules(link, rules); err != nil {
+		return nil, fmt.Errorf("failed to save targeting rules: %w", err)
+	}
+	return link, nil
+}
+
+// CountClicksByRule retourne le nombre de clics de chaque règle de ciblage d'un lien.
+func (s *LinkService) CountClicksByRule(link *models.Link) (map[uint]int, error) {
+	if len(link.Rules) == 0 {
+		return map[uint]int{}, nil
+	}
+	counts, err := s.linkRepo.CountClicksByRule(link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks by rule: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+)
+
+// ErrInvalidURL est la cause commune des erreurs de validation d'URL (voir URLError).
+var ErrInvalidURL = errors.New("invalid URL")
+
+// Codes des erreurs de validation d'URL, repris dans le champ "code" des réponses de l'API.
+const (
+	URLErrInvalid          = "invalid_url"             // Syntaxe invalide ou URL relative
+	URLErrTooLong          = "url_too_long"            // Plus longue que la limite configurée
+	URLErrSchemeNotAllowed = "scheme_not_allowed"      // Schéma absent de la liste autorisée
+	URLErrMissingHost      = "missing_host"            // Pas de nom d'hôte
+	URLErrInvalidHost      = "invalid_host"            // Nom d'hôte impossible à convertir en punycode
+	URLErrCredentials      = "credentials_not_allowed" // Identifiants (user:password@) dans l'URL
+)
+
+// URLError décrit le refus d'une URL : Code est stable et destiné aux clients,
+// Field désigne le champ concerné (long_url, fallback_url).
+type URLError struct {
+	Field  string
+	Code   string
+	Reason string
+}
+
+func (e *URLError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+func (e *URLError) Unwrap() error {
+	return ErrInvalidURL
+}
+
+// URLPolicy regroupe les règles appliquées aux URL de destination.
+type URLPolicy struct {
+	AllowedSchemes []string // Schémas acceptés (en minuscules)
+	MaxLength      int      // Longueur maximale de l'URL normalisée, 0 = illimitée
+
+	StripTrackingParams bool     // Retirer les paramètres de suivi de la requête
+	TrackingParams      []string // Noms exacts, ou préfixes terminés par "*" (ex : utm_*)
+}
+
+// DefaultURLPolicy retourne les règles par défaut : http et https seulement, 2048 caractères.
+func DefaultURLPolicy() URLPolicy {
+	return URLPolicy{
+		AllowedSchemes: []string{"http", "https"},
+		MaxLength:      2048,
+		TrackingParams: DefaultTrackingParams,
+	}
+}
+
+// DefaultTrackingParams liste les paramètres de suivi retirés quand links.strip_tracking_params est actif.
+var DefaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid",
+	"mc_cid", "mc_eid", "igshid", "yclid", "_hsenc", "_hsmi",
+}
+
+// NewURLPolicy construit les règles à partir de la section links de la configuration.
+func NewURLPolicy(cfg config.LinksConfig) URLPolicy {
+	policy := DefaultURLPolicy()
+	if len(cfg.AllowedSchemes) > 0 {
+		policy.AllowedSchemes = cfg.AllowedSchemes
+	}
+	policy.MaxLength = cfg.MaxURLLength
+	policy.StripTrackingParams = cfg.StripTrackingParams
+	if len(cfg.TrackingParams) > 0 {
+		policy.TrackingParams = cfg.TrackingParams
+	}
+	return policy
+}
+
+// URLValidator valide et normalise les URL de destination selon une URLPolicy.
+type URLValidator struct {
+	policy  URLPolicy
+	schemes map[string]bool
+}
+
+// NewURLValidator crée un validateur pour les règles données.
+func NewURLValidator(policy URLPolicy) *URLValidator {
+	schemes := make(map[string]bool, len(policy.AllowedSchemes))
+	for _, s := range policy.AllowedSchemes {
+		schemes[strings.ToLower(strings.TrimSpace(s))] = true
+	}
+	return &URLValidator{policy: policy, schemes: schemes}
+}
+
+// Normalize vérifie l'URL et retourne la forme enregistrée : schéma et hôte en minuscules,
+// hôte internationalisé converti en punycode et, si configuré, paramètres de suivi retirés.
+// Le chemin, l'ordre des paramètres restants et le fragment sont conservés.
+// field nomme le champ dans l'erreur retournée (toujours un *URLError).
+func (v *URLValidator) Normalize(field, raw string) (string, error) {
+	fail := func(code, format string, args ...any) (string, error) {
+		return "", &URLError{Field: field, Code: code, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fail(URLErrInvalid, "URL is empty")
+	}
+	if v.policy.MaxLength > 0 && len(raw) > v.policy.MaxLength {
+		return fail(URLErrTooLong, "URL exceeds %d characters", v.policy.MaxLength)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fail(URLErrInvalid, "%v", err)
+	}
+	if u.Scheme == "" {
+		return fail(URLErrInvalid, "URL must be absolute")
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if !v.schemes[u.Scheme] {
+		return fail(URLErrSchemeNotAllowed, "scheme %q is not allowed", u.Scheme)
+	}
+	if u.User != nil {
+		return fail(URLErrCredentials, "URL must not contain credentials")
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return fail(URLErrMissingHost, "URL has no host")
+	}
+	if ip := net.ParseIP(host); ip == nil {
+		ascii, err := idna.Lookup.ToASCII(host)
+		if err != nil {
+			return fail(URLErrInvalidHost, "invalid host %q: %v", host, err)
+		}
+		host = ascii
+	} else if ip.To4() == nil {
+		host = "[" + host + "]"
+	}
+	if port := u.Port(); port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	if v.policy.StripTrackingParams && u.RawQuery != "" {
+		u.RawQuery = v.stripTracking(u.RawQuery)
+	}
+
+	normalized := u.String()
+	if v.policy.MaxLength > 0 && len(normalized) > v.policy.MaxLength {
+		return fail(URLErrTooLong, "URL exceeds %d characters", v.policy.MaxLength)
+	}
+	return normalized, nil
+}
+
+// stripTracking retire les paramètres de suivi en conservant l'ordre et l'encodage des autres.
+func (v *URLValidator) stripTracking(rawQuery string) string {
+	kept := make([]string, 0, strings.Count(rawQuery, "&")+1)
+	for _, pair := range strings.Split(rawQuery, "&") {
+		name, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if !v.isTrackingParam(strings.ToLower(name)) {
+			kept = append(kept, pair)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+func (v *URLValidator) isTrackingParam(name string) bool {
+	for _, p := range v.policy.TrackingParams {
+		p = strings.ToLower(p)
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == p {
+			return true
+		}
+	}
+	return false
+}
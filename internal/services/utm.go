@@ -0,0 +1,102 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// maxUTMValue est la taille des colonnes utm_*.
+const maxUTMValue = 100
+
+// CampaignLinkStats est le nombre de clics d'un lien d'une campagne.
+type CampaignLinkStats struct {
+	Link   models.Link
+	Clicks int
+}
+
+// NewUTMParams convertit la section 'utm' de la configuration.
+func NewUTMParams(cfg config.UTMConfig) models.UTMParams {
+	return models.UTMParams{
+		Source:   strings.TrimSpace(cfg.Source),
+		Medium:   strings.TrimSpace(cfg.Medium),
+		Campaign: strings.TrimSpace(cfg.Campaign),
+		Term:     strings.TrimSpace(cfg.Term),
+		Content:  strings.TrimSpace(cfg.Content),
+	}
+}
+
+// SetUTMDefaults définit les paramètres UTM appliqués aux liens qui ne les renseignent pas.
+func (s *LinkService) SetUTMDefaults(defaults models.UTMParams) {
+	s.utmDefaults = defaults
+}
+
+// EffectiveUTM retourne les paramètres UTM d'un lien complétés par les valeurs par défaut.
+func (s *LinkService) EffectiveUTM(link *models.Link) models.UTMParams {
+	return link.UTM.WithDefaults(s.utmDefaults)
+}
+
+// CampaignStats retourne les liens d'une campagne et leurs clics. Les liens sans campagne
+// sont comptés dans la campagne par défaut, puisque c'est elle qu'ils transmettent.
+func (s *LinkService) CampaignStats(campaign string) ([]CampaignLinkStats, int, error) {
+	campaign = strings.TrimSpace(campaign)
+	if campaign == "" {
+		return nil, 0, fmt.Errorf("%w: campaign is required", ErrInvalidLinkOptions)
+	}
+	links, err := s.linkRepo.ListLinksByCampaign(campaign, campaign == s.utmDefaults.Campaign)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list campaign links: %w", err)
+	}
+
+	stats := make([]CampaignLinkStats, 0, len(links))
+	total := 0
+	for _, link := range links {
+		count, err := s.linkRepo.CountClicksByLinkID(link.ID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to count clicks: %w", err)
+		}
+		stats = append(stats, CampaignLinkStats{Link: link, Clicks: count})
+		total += count
+	}
+	return stats, total, nil
+}
+
+// applyUTM ajoute les paramètres UTM absents de la requête ; un paramètre déjà présent
+// (dans la destination ou transmis par le visiteur) n'est jamais remplacé.
+func applyUTM(rawQuery string, utm models.UTMParams) string {
+	present := queryKeys(rawQuery)
+	pairs := []string{}
+	if rawQuery != "" {
+		pairs = append(pairs, rawQuery)
+	}
+	for _, kv := range utm.Pairs() {
+		if present[kv[0]] {
+			continue
+		}
+		pairs = append(pairs, kv[0]+"="+url.QueryEscape(kv[1]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// validateUTM nettoie et vérifie les paramètres UTM demandés.
+func validateUTM(utm *models.UTMParams) error {
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"utm_source", &utm.Source},
+		{"utm_medium", &utm.Medium},
+		{"utm_campaign", &utm.Campaign},
+		{"utm_term", &utm.Term},
+		{"utm_content", &utm.Content},
+	} {
+		*field.value = strings.TrimSpace(*field.value)
+		if len(*field.value) > maxUTMValue {
+			return fmt.Errorf("%w: %s exceeds %d characters", ErrInvalidLinkOptions, field.name, maxUTMValue)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Bornes des tests A/B d'un lien.
+const (
+	maxLinkVariants  = 10
+	maxVariantWeight = 10000
+)
+
+// VariantStats est la répartition observée du trafic d'une variante A/B.
+type VariantStats struct {
+	Variant     models.LinkVariant
+	Clicks      int
+	Share       float64 // % des clics servis par une variante du lien
+	TargetShare float64 // % attendu d'après les poids
+}
+
+// PickVariant tire une variante au hasard, proportionnellement aux poids. Retourne nil sans variante.
+func PickVariant(variants []models.LinkVariant) *models.LinkVariant {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+	n := rand.IntN(total)
+	for i := range variants {
+		if n < variants[i].Weight {
+			return &variants[i]
+		}
+		n -= variants[i].Weight
+	}
+	return nil
+}
+
+// VariantByID retourne la variante d'identifiant id, ou nil si elle n'existe plus
+// (variantes remplacées depuis l'attribution d'un visiteur).
+func VariantByID(variants []models.LinkVariant, id uint) *models.LinkVariant {
+	for i := range variants {
+		if variants[i].ID == id {
+			return &variants[i]
+		}
+	}
+	return nil
+}
+
+// normalizeVariants valide les variantes demandées : au moins deux, des noms uniques
+// (lettres A, B, ... par défaut), des poids positifs (0 = 1) et des destinations valides.
+func (s *LinkService) normalizeVariants(variants []models.LinkVariant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+	if len(variants) < 2 {
+		return fmt.Errorf("%w: an A/B split needs at least 2 variants", ErrInvalidLinkOptions)
+	}
+	if len(variants) > maxLinkVariants {
+		return fmt.Errorf("%w: at most %d variants", ErrInvalidLinkOptions, maxLinkVariants)
+	}
+	names := make(map[string]bool, len(variants))
+	for i := range variants {
+		variant := &variants[i]
+		field := fmt.Sprintf("variants[%d]", i)
+
+		variant.Name = strings.TrimSpace(variant.Name)
+		if variant.Name == "" {
+			variant.Name = string(rune('A' + i))
+		}
+		if len(variant.Name) > 50 {
+			return fmt.Errorf("%w: %s: name must be at most 50 characters", ErrInvalidLinkOptions, field)
+		}
+		if names[variant.Name] {
+			return fmt.Errorf("%w: %s: duplicate variant name %q", ErrInvalidLinkOptions, field, variant.Name)
+		}
+		names[variant.Name] = true
+
+		if variant.Weight == 0 {
+			variant.Weight = 1
+		}
+		if variant.Weight < 0 || variant.Weight > maxVariantWeight {
+			return fmt.Errorf("%w: %s: weight must be between 1 and %d", ErrInvalidLinkOptions, field, maxVariantWeight)
+		}
+
+		destination, err := s.checkURL(field+".destination", variant.Destination)
+		if err != nil {
+			return err
+		}
+		variant.Destination = destination
+	}
+	return nil
+}
+
+// SetVariants remplace les variantes A/B d'un lien et son réglage d'attribution persistante.
+func (s *LinkService) SetVariants(shortCode string, variants []models.LinkVariant, sticky bool) (*models.Link, error) {
+	if err := s.normalizeVariants(variants); err != nil {
+		return nil, err
+	}
+	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch link: %w", err)
+	}
+	link.StickyVariants = sticky
+	if err := s.linkRepo.ReplaceVariants(link, variants); err != nil {
+		return nil, fmt.Errorf("failed to save variants: %w", err)
+	}
+	return link, nil
+}
+
+// VariantStats retourne les clics de chaque variante A/B d'un lien et la part du trafic
+// qu'elle a reçue, comparée à celle attendue d'après son poids.
+func (s *LinkService) VariantStats(link *models.Link) ([]VariantStats, error) {
+	if len(link.Variants) == 0 {
+		return nil, nil
+	}
+	counts, err := s.linkRepo.CountClicksByVariant(link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks by variant: %w", err)
+	}
+	totalClicks, totalWeight := 0, 0
+	for _, v := range link.Variants {
+		totalClicks += counts[v.ID]
+		totalWeight += v.Weight
+	}
+	stats := make([]VariantStats, 0, len(link.Variants))
+	for _, v := range link.Variants {
+		st := VariantStats{Variant: v, Clicks: counts[v.ID], TargetShare: percent(v.Weight, totalWeight)}
+		st.Share = percent(st.Clicks, totalClicks)
+		stats = append(stats, st)
+	}
+	return stats, nil
+}
+
+// percent retourne part/total en pourcentage arrondi au dixième, 0 si total est nul.
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
@@ -0,0 +1,83 @@
+package threats
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Nombre maximal d'hôtes et de chemins combinés, comme les clients Safe Browsing.
+const (
+	maxHostExpressions = 5
+	maxPathExpressions = 6
+)
+
+// urlExpressions retourne les expressions "hôte/chemin" dont les empreintes sont comparées
+// aux listes de préfixes, à la manière de Safe Browsing : l'hôte exact et jusqu'à quatre
+// domaines parents, combinés au chemin exact (avec puis sans requête) et à ses préfixes.
+// Pour http://a.b.c/1/2.html?p=1 : a.b.c/1/2.html?p=1, a.b.c/1/2.html, a.b.c/, a.b.c/1/,
+// b.c/1/2.html?p=1, ...
+func urlExpressions(u *url.URL) []string {
+	hosts := hostSuffixes(strings.ToLower(u.Hostname()))
+	paths := pathPrefixes(u)
+
+	exprs := make([]string, 0, len(hosts)*len(paths))
+	for _, h := range hosts {
+		for _, p := range paths {
+			exprs = append(exprs, h+p)
+		}
+	}
+	return exprs
+}
+
+// hostSuffixes retourne l'hôte exact puis ses domaines parents, formés à partir des
+// cinq derniers composants en retirant successivement le premier (sans le TLD seul).
+func hostSuffixes(host string) []string {
+	hosts := []string{host}
+	if net.ParseIP(host) != nil {
+		return hosts
+	}
+	parts := strings.Split(host, ".")
+	start := 1
+	if len(parts) > maxHostExpressions {
+		start = len(parts) - maxHostExpressions
+	}
+	for i := start; i < len(parts)-1 && len(hosts) < maxHostExpressions; i++ {
+		hosts = append(hosts, strings.Join(parts[i:], "."))
+	}
+	return hosts
+}
+
+// pathPrefixes retourne le chemin exact avec sa requête, sans sa requête, puis la racine
+// et les préfixes successifs du chemin (jusqu'à six expressions en tout).
+func pathPrefixes(u *url.URL) []string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	var paths []string
+	add := func(p string) {
+		for _, existing := range paths {
+			if existing == p {
+				return
+			}
+		}
+		if len(paths) < maxPathExpressions {
+			paths = append(paths, p)
+		}
+	}
+
+	if u.RawQuery != "" {
+		add(path + "?" + u.RawQuery)
+	}
+	add(path)
+	add("/")
+	components := strings.Split(strings.Trim(path, "/"), "/")
+	prefix := "/"
+	for i := 0; i < len(components)-1 && i < 4; i++ {
+		prefix += components[i] + "/"
+		add(prefix)
+	}
+	return paths
+}
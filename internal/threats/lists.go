@@ -0,0 +1,141 @@
+package threats
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Types de règles.
+const (
+	KindDomain     = "domain"      // Domaine (et ses sous-domaines)
+	KindHashPrefix = "hash_prefix" // Préfixe SHA-256 d'une expression d'URL (format Safe Browsing)
+	KindRegex      = "regex"       // Expression régulière appliquée à l'URL complète
+)
+
+// Longueurs acceptées pour un préfixe d'empreinte, en octets (4 à 32, comme Safe Browsing).
+const (
+	minHashPrefixBytes = 4
+	maxHashPrefixBytes = 32
+)
+
+// ruleSet est un jeu de règles chargé depuis les fichiers ; il n'est jamais modifié
+// après son chargement et peut donc être lu sans verrou.
+type ruleSet struct {
+	domains  map[string]source         // Domaine -> liste d'origine
+	prefixes map[int]map[string]source // Longueur en octets -> préfixe (binaire) -> liste d'origine
+	regexes  []regexRule
+	size     int
+}
+
+type regexRule struct {
+	re *regexp.Regexp
+	source
+}
+
+// source situe une règle dans les fichiers de listes.
+type source struct {
+	list string // Nom du fichier
+	line int
+}
+
+func newRuleSet() *ruleSet {
+	return &ruleSet{
+		domains:  make(map[string]source),
+		prefixes: make(map[int]map[string]source),
+	}
+}
+
+// readLines appelle fn pour chaque ligne utile du fichier (sans commentaire ni espaces).
+func readLines(path string, fn func(line string, n int) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := fn(line, n); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// loadDomains lit une liste de domaines : un domaine par ligne, ou le format hosts
+// ("0.0.0.0 domaine" : le dernier champ est retenu).
+func (rs *ruleSet) loadDomains(path string) error {
+	name := filepath.Base(path)
+	return readLines(path, func(line string, n int) error {
+		fields := strings.Fields(line)
+		domain := strings.TrimSuffix(strings.ToLower(fields[len(fields)-1]), ".")
+		domain = strings.TrimPrefix(domain, "*.")
+		if ascii, err := idna.Lookup.ToASCII(domain); err == nil {
+			domain = ascii
+		}
+		if domain == "" || strings.ContainsAny(domain, "/:") {
+			return fmt.Errorf("invalid domain %q", line)
+		}
+		rs.domains[domain] = source{list: name, line: n}
+		rs.size++
+		return nil
+	})
+}
+
+// loadHashPrefixes lit une liste de préfixes d'empreintes : un préfixe hexadécimal par ligne,
+// de 4 à 32 octets, calculé sur une expression d'URL "hôte/chemin" (voir urlExpressions).
+func (rs *ruleSet) loadHashPrefixes(path string) error {
+	name := filepath.Base(path)
+	return readLines(path, func(line string, n int) error {
+		prefix, err := hex.DecodeString(strings.ToLower(line))
+		if err != nil {
+			return fmt.Errorf("invalid hash prefix %q: %v", line, err)
+		}
+		if len(prefix) < minHashPrefixBytes || len(prefix) > maxHashPrefixBytes {
+			return fmt.Errorf("hash prefix %q must be %d to %d bytes long", line, minHashPrefixBytes, maxHashPrefixBytes)
+		}
+		set, ok := rs.prefixes[len(prefix)]
+		if !ok {
+			set = make(map[string]source)
+			rs.prefixes[len(prefix)] = set
+		}
+		set[string(prefix)] = source{list: name, line: n}
+		rs.size++
+		return nil
+	})
+}
+
+// loadRegexes lit une liste d'expressions régulières (syntaxe RE2), une par ligne.
+func (rs *ruleSet) loadRegexes(path string) error {
+	name := filepath.Base(path)
+	return readLines(path, func(line string, n int) error {
+		re, err := regexp.Compile(line)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q: %v", line, err)
+		}
+		rs.regexes = append(rs.regexes, regexRule{re: re, source: source{list: name, line: n}})
+		rs.size++
+		return nil
+	})
+}
@@ -0,0 +1,240 @@
+// Package threats filtre les URL de destination à l'aide de listes de menaces locales
+// (domaines, préfixes d'empreintes façon Safe Browsing et expressions régulières),
+// rechargeables sans redémarrer le service.
+package threats
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+)
+
+// Match décrit la règle qui a bloqué une URL.
+type Match struct {
+	Kind string // KindDomain, KindHashPrefix ou KindRegex
+	Rule string // Domaine, préfixe hexadécimal ou expression
+	List string // Fichier d'origine
+	Line int
+}
+
+func (m *Match) String() string {
+	return fmt.Sprintf("%s %s (%s:%d)", m.Kind, m.Rule, m.List, m.Line)
+}
+
+// Sources liste les fichiers chargés par un Screener.
+type Sources struct {
+	DomainLists     []string
+	HashPrefixLists []string
+	RegexLists      []string
+}
+
+func (s Sources) empty() bool {
+	return len(s.DomainLists)+len(s.HashPrefixLists)+len(s.RegexLists) == 0
+}
+
+// Screener confronte les URL aux listes chargées. Il est sûr pour un usage concurrent :
+// un rechargement remplace le jeu de règles d'un bloc.
+type Screener struct {
+	sources Sources
+
+	mu       sync.RWMutex
+	rules    *ruleSet
+	version  uint64               // Incrémentée à chaque rechargement réussi
+	modTimes map[string]time.Time // Date de modification des fichiers au dernier chargement
+}
+
+// NewScreener crée un Screener et charge les listes une première fois.
+func NewScreener(sources Sources) (*Screener, error) {
+	s := &Screener{sources: sources, rules: newRuleSet()}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewScreenerFromConfig crée un Screener à partir de la section 'threats' de la configuration.
+// Il retourne nil, sans erreur, si aucune liste n'est configurée.
+func NewScreenerFromConfig(cfg config.ThreatsConfig) (*Screener, error) {
+	sources := Sources{
+		DomainLists:     cfg.DomainLists,
+		HashPrefixLists: cfg.HashPrefixLists,
+		RegexLists:      cfg.RegexLists,
+	}
+	if sources.empty() {
+		return nil, nil
+	}
+	return NewScreener(sources)
+}
+
+// Reload relit toutes les listes. En cas d'erreur, les règles précédentes restent en place.
+func (s *Screener) Reload() error {
+	rules := newRuleSet()
+	modTimes := make(map[string]time.Time)
+
+	load := func(paths []string, fn func(string) error) error {
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			if err := fn(path); err != nil {
+				return err
+			}
+			modTimes[path] = info.ModTime()
+		}
+		return nil
+	}
+	if err := load(s.sources.DomainLists, rules.loadDomains); err != nil {
+		return err
+	}
+	if err := load(s.sources.HashPrefixLists, rules.loadHashPrefixes); err != nil {
+		return err
+	}
+	if err := load(s.sources.RegexLists, rules.loadRegexes); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.rules = rules
+	s.modTimes = modTimes
+	s.version++
+	s.mu.Unlock()
+	return nil
+}
+
+// Version change à chaque rechargement : un appelant peut ainsi savoir si les règles
+// ont changé depuis son dernier passage.
+func (s *Screener) Version() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// Size retourne le nombre de règles chargées.
+func (s *Screener) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rules.size
+}
+
+// Watch recharge les listes dès que l'un des fichiers est modifié, en vérifiant
+// toutes les every. Une liste invalide n'est réessayée qu'après une nouvelle modification.
+// Cette fonction est conçue pour être lancée dans une goroutine.
+func (s *Screener) Watch(every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	var failed map[string]time.Time // Fichiers tels qu'au dernier rechargement en échec
+	for range ticker.C {
+		current := s.sources.modTimes()
+		s.mu.RLock()
+		loaded := s.modTimes
+		s.mu.RUnlock()
+		if sameModTimes(current, loaded) || sameModTimes(current, failed) {
+			continue
+		}
+		if err := s.Reload(); err != nil {
+			failed = current
+			log.Printf("[THREATS] ERREUR lors du rechargement des listes, règles précédentes conservées : %v", err)
+			continue
+		}
+		failed = nil
+		log.Printf("[THREATS] Listes de menaces rechargées (%d règle(s)).", s.Size())
+	}
+}
+
+// modTimes retourne la date de modification de chaque fichier (zéro s'il est illisible).
+func (s Sources) modTimes() map[string]time.Time {
+	times := make(map[string]time.Time)
+	for _, paths := range [][]string{s.DomainLists, s.HashPrefixLists, s.RegexLists} {
+		for _, path := range paths {
+			var t time.Time
+			if info, err := os.Stat(path); err == nil {
+				t = info.ModTime()
+			}
+			times[path] = t
+		}
+	}
+	return times
+}
+
+func sameModTimes(a, b map[string]time.Time) bool {
+	if a == nil || b == nil || len(a) != len(b) {
+		return false
+	}
+	for path, t := range a {
+		if other, ok := b[path]; !ok || !other.Equal(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check retourne la première règle correspondant à l'URL, ou nil si elle n'est pas listée.
+// Une URL impossible à analyser n'est confrontée qu'aux expressions régulières.
+func (s *Screener) Check(rawURL string) *Match {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	rules := s.rules
+	s.mu.RUnlock()
+
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+		if m := rules.matchDomain(strings.ToLower(u.Hostname())); m != nil {
+			return m
+		}
+		if m := rules.matchHashPrefix(u); m != nil {
+			return m
+		}
+	}
+	return rules.matchRegex(rawURL)
+}
+
+// matchDomain cherche l'hôte puis chacun de ses domaines parents.
+func (rs *ruleSet) matchDomain(host string) *Match {
+	for h := host; h != ""; {
+		if src, ok := rs.domains[h]; ok {
+			return &Match{Kind: KindDomain, Rule: h, List: src.list, Line: src.line}
+		}
+		i := strings.Index(h, ".")
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+	return nil
+}
+
+// matchHashPrefix compare l'empreinte de chaque expression de l'URL aux préfixes listés.
+// Faute de service de confirmation, un préfixe correspondant suffit à bloquer :
+// les listes locales doivent donc utiliser des préfixes assez longs (ou des empreintes complètes).
+func (rs *ruleSet) matchHashPrefix(u *url.URL) *Match {
+	if len(rs.prefixes) == 0 {
+		return nil
+	}
+	for _, expr := range urlExpressions(u) {
+		sum := sha256.Sum256([]byte(expr))
+		for length, set := range rs.prefixes {
+			if src, ok := set[string(sum[:length])]; ok {
+				return &Match{Kind: KindHashPrefix, Rule: fmt.Sprintf("%x", sum[:length]), List: src.list, Line: src.line}
+			}
+		}
+	}
+	return nil
+}
+
+func (rs *ruleSet) matchRegex(rawURL string) *Match {
+	for _, r := range rs.regexes {
+		if r.re.MatchString(rawURL) {
+			return &Match{Kind: KindRegex, Rule: r.re.String(), List: r.list, Line: r.line}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,732 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Writer écrit un flux d'enregistrements ; les clics d'un lien sont écrits juste après lui.
+type Writer interface {
+	WriteLink(link *LinkRecord) error
+	WriteClick(click *ClickRecord) error
+	// Close termine le document (sans fermer le io.Writer sous-jacent).
+	Close() error
+}
+
+// Reader lit un flux d'enregistrements ; Next retourne io.EOF à la fin du flux.
+type Reader interface {
+	Next() (*Record, error)
+}
+
+// NewWriter retourne l'encodeur du format demandé.
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatJSONL:
+		return newJSONLWriter(w), nil
+	case FormatArchive:
+		return newArchiveWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected csv, jsonl or archive)", format)
+	}
+}
+
+// NewReader retourne le décodeur du format demandé.
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSONL:
+		return newJSONLReader(r), nil
+	case FormatArchive:
+		return newArchiveReader(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected csv, jsonl or archive)", format)
+	}
+}
+
+// --- CSV ---
+
+// csvHeader liste les colonnes du CSV ; les colonnes inutiles pour un type de ligne restent vides.
+var csvHeader = []string{
+	"type", "short_code", "long_url", "created_at",
+	"failure_policy", "failure_threshold", "fallback_url", "content_monitoring",
+	"monitor_disabled", "monitor_interval_minutes", "monitor_priority", "historical_clicks", "metadata",
+	"interstitial", "redirect_type", "forward_query", "forward_path",
+	"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+	"og_title", "og_description", "og_image", "password_hash", "require_signature",
+	"disabled", "disabled_reason", "disabled_at", "flagged",
+	"rules", "variants", "sticky_variants", "track_conversions", "conversion_goal",
+	"timestamp", "user_agent", "ip_address",
+	"country", "city", "source", "token", "rule", "variant",
+}
+
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) write(row []string) error {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	return c.w.Write(row)
+}
+
+// jsonColumn encode une valeur composée en JSON dans une seule colonne (vide si absente).
+func jsonColumn(v any, empty bool) (string, error) {
+	if empty {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (c *csvWriter) WriteLink(link *LinkRecord) error {
+	// Les métadonnées, les règles et les variantes sont stockées en JSON dans une seule colonne.
+	metadata, err := jsonColumn(link.Metadata, len(link.Metadata) == 0)
+	if err != nil {
+		return err
+	}
+	rules, err := jsonColumn(link.Rules, len(link.Rules) == 0)
+	if err != nil {
+		return err
+	}
+	variants, err := jsonColumn(link.Variants, len(link.Variants) == 0)
+	if err != nil {
+		return err
+	}
+	return c.write([]string{
+		KindLink, link.ShortCode, link.LongURL, formatTime(link.CreatedAt),
+		link.FailurePolicy, strconv.Itoa(link.FailureThreshold), link.FallbackURL, strconv.FormatBool(link.ContentMonitoring),
+		strconv.FormatBool(link.MonitorDisabled), strconv.Itoa(link.MonitorIntervalMinutes), strconv.Itoa(link.MonitorPriority),
+		strconv.Itoa(link.HistoricalClicks), metadata,
+		strconv.FormatBool(link.Interstitial), strconv.Itoa(link.RedirectType),
+		strconv.FormatBool(link.ForwardQuery), strconv.FormatBool(link.ForwardPath),
+		link.UTMSource, link.UTMMedium, link.UTMCampaign, link.UTMTerm, link.UTMContent,
+		link.OGTitle, link.OGDescription, link.OGImage, link.PasswordHash, strconv.FormatBool(link.RequireSignature),
+		strconv.FormatBool(link.Disabled), link.DisabledReason, formatTimePtr(link.DisabledAt), strconv.FormatBool(link.Flagged),
+		rules, variants, strconv.FormatBool(link.StickyVariants), strconv.FormatBool(link.TrackConversions), link.ConversionGoal,
+		"", "", "",
+		"", "", "", "", "", "",
+	})
+}
+
+func (c *csvWriter) WriteClick(click *ClickRecord) error {
+	return c.write([]string{
+		KindClick, click.ShortCode, "", "",
+		"", "", "", "",
+		"", "", "", "", "",
+		"", "", "", "",
+		"", "", "", "", "",
+		"", "", "", "", "",
+		"", "", "", "",
+		"", "", "", "", "",
+		formatTime(click.Timestamp), click.UserAgent, click.IPAddress,
+		click.Country, click.City, click.Source, click.Token, formatIntPtr(click.Rule), formatIntPtr(click.Variant),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("empty CSV file")
+		}
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, required := range []string{"type", "short_code"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing column %q", required)
+		}
+	}
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func (c *csvReader) Next() (*Record, error) {
+	row, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := c.r.FieldPos(0)
+	col := func(name string) string {
+		if i, ok := c.columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	var p fieldParser
+	switch kind := col("type"); kind {
+	case KindLink:
+		link := &LinkRecord{
+			ShortCode:              col("short_code"),
+			LongURL:                col("long_url"),
+			CreatedAt:              p.time("created_at", col("created_at")),
+			FailurePolicy:          col("failure_policy"),
+			FailureThreshold:       p.int("failure_threshold", col("failure_threshold")),
+			FallbackURL:            col("fallback_url"),
+			ContentMonitoring:      p.bool("content_monitoring", col("content_monitoring")),
+			MonitorDisabled:        p.bool("monitor_disabled", col("monitor_disabled")),
+			MonitorIntervalMinutes: p.int("monitor_interval_minutes", col("monitor_interval_minutes")),
+			MonitorPriority:        p.int("monitor_priority", col("monitor_priority")),
+			HistoricalClicks:       p.int("historical_clicks", col("historical_clicks")),
+			Metadata:               p.metadata("metadata", col("metadata")),
+			Interstitial:           p.bool("interstitial", col("interstitial")),
+			RedirectType:           p.int("redirect_type", col("redirect_type")),
+			ForwardQuery:           p.bool("forward_query", col("forward_query")),
+			ForwardPath:            p.bool("forward_path", col("forward_path")),
+			UTMSource:              col("utm_source"),
+			UTMMedium:              col("utm_medium"),
+			UTMCampaign:            col("utm_campaign"),
+			UTMTerm:                col("utm_term"),
+			UTMContent:             col("utm_content"),
+			OGTitle:                col("og_title"),
+			OGDescription:          col("og_description"),
+			OGImage:                col("og_image"),
+			PasswordHash:           col("password_hash"),
+			RequireSignature:       p.bool("require_signature", col("require_signature")),
+			Disabled:               p.bool("disabled", col("disabled")),
+			DisabledReason:         col("disabled_reason"),
+			DisabledAt:             p.timePtr("disabled_at", col("disabled_at")),
+			Flagged:                p.bool("flagged", col("flagged")),
+			StickyVariants:         p.bool("sticky_variants", col("sticky_variants")),
+			TrackConversions:       p.bool("track_conversions", col("track_conversions")),
+			ConversionGoal:         col("conversion_goal"),
+		}
+		p.json("rules", col("rules"), &link.Rules)
+		p.json("variants", col("variants"), &link.Variants)
+		if p.err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, p.err)
+		}
+		return &Record{Kind: KindLink, Link: link}, nil
+	case KindClick:
+		click := &ClickRecord{
+			ShortCode: col("short_code"),
+			Timestamp: p.time("timestamp", col("timestamp")),
+			UserAgent: col("user_agent"),
+			IPAddress: col("ip_address"),
+			Country:   col("country"),
+			City:      col("city"),
+			Source:    col("source"),
+			Token:     col("token"),
+			Rule:      p.intPtr("rule", col("rule")),
+			Variant:   p.intPtr("variant", col("variant")),
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, p.err)
+		}
+		return &Record{Kind: KindClick, Click: click}, nil
+	default:
+		return nil, fmt.Errorf("line %d: unknown record type %q", line, kind)
+	}
+}
+
+// fieldParser convertit les colonnes CSV et garde la première erreur rencontrée.
+type fieldParser struct {
+	err error
+}
+
+func (p *fieldParser) int(name, value string) int {
+	if value == "" || p.err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.err = fmt.Errorf("invalid %s %q", name, value)
+	}
+	return n
+}
+
+func (p *fieldParser) intPtr(name, value string) *int {
+	if value == "" || p.err != nil {
+		return nil
+	}
+	n := p.int(name, value)
+	return &n
+}
+
+func (p *fieldParser) bool(name, value string) bool {
+	if value == "" || p.err != nil {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.err = fmt.Errorf("invalid %s %q", name, value)
+	}
+	return b
+}
+
+func (p *fieldParser) time(name, value string) time.Time {
+	if value == "" || p.err != nil {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		p.err = fmt.Errorf("invalid %s %q (expected RFC 3339)", name, value)
+	}
+	return t
+}
+
+func (p *fieldParser) timePtr(name, value string) *time.Time {
+	if t := p.time(name, value); !t.IsZero() {
+		return &t
+	}
+	return nil
+}
+
+func (p *fieldParser) metadata(name, value string) map[string]string {
+	if value == "" || p.err != nil {
+		return nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal([]byte(value), &m); err != nil {
+		p.err = fmt.Errorf("invalid %s %q (expected a JSON object)", name, value)
+	}
+	return m
+}
+
+// json décode une colonne JSON (règles, variantes) dans v.
+func (p *fieldParser) json(name, value string, v any) {
+	if value == "" || p.err != nil {
+		return
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		p.err = fmt.Errorf("invalid %s %q (expected a JSON array)", name, value)
+	}
+}
+
+func formatIntPtr(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+// --- JSON Lines ---
+
+// jsonlEnvelope est une ligne du format JSON Lines.
+type jsonlEnvelope struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonlWriter{enc: enc}
+}
+
+func (j *jsonlWriter) write(kind string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return j.enc.Encode(jsonlEnvelope{Type: kind, Data: data})
+}
+
+func (j *jsonlWriter) WriteLink(link *LinkRecord) error    { return j.write(KindLink, link) }
+func (j *jsonlWriter) WriteClick(click *ClickRecord) error { return j.write(KindClick, click) }
+func (j *jsonlWriter) Close() error                        { return nil }
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLReader(r io.Reader) *jsonlReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	return &jsonlReader{scanner: scanner}
+}
+
+func (j *jsonlReader) Next() (*Record, error) {
+	for j.scanner.Scan() {
+		j.line++
+		raw := j.scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+
+		var env jsonlEnvelope
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return nil, fmt.Errorf("line %d: %w", j.line, err)
+		}
+		switch env.Type {
+		case KindLink:
+			var link LinkRecord
+			if err := json.Unmarshal(env.Data, &link); err != nil {
+				return nil, fmt.Errorf("line %d: %w", j.line, err)
+			}
+			return &Record{Kind: KindLink, Link: &link}, nil
+		case KindClick:
+			var click ClickRecord
+			if err := json.Unmarshal(env.Data, &click); err != nil {
+				return nil, fmt.Errorf("line %d: %w", j.line, err)
+			}
+			return &Record{Kind: KindClick, Click: &click}, nil
+		default:
+			return nil, fmt.Errorf("line %d: unknown record type %q", j.line, env.Type)
+		}
+	}
+	if err := j.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// --- Archive JSON ---
+
+// L'archive est un unique document JSON :
+//
+//	{"format":"urlshortener-archive","version":1,"exported_at":"...",
+//	 "links":[{"short_code":"...",...,"clicks":[{...},...]},...]}
+//
+// Elle est écrite et relue au fil de l'eau : la mémoire utilisée ne dépend pas
+// du nombre de liens ni de clics.
+type archiveWriter struct {
+	w          *bufio.Writer
+	started    bool
+	linkOpen   bool
+	firstLink  bool
+	firstClick bool
+}
+
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	return &archiveWriter{w: bufio.NewWriter(w), firstLink: true}
+}
+
+func (a *archiveWriter) start() error {
+	if a.started {
+		return nil
+	}
+	a.started = true
+	_, err := fmt.Fprintf(a.w, `{"format":%q,"version":%d,"exported_at":%q,"links":[`,
+		ArchiveFormat, ArchiveVersion, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+func (a *archiveWriter) closeLink() error {
+	if !a.linkOpen {
+		return nil
+	}
+	a.linkOpen = false
+	_, err := a.w.WriteString("]}")
+	return err
+}
+
+func (a *archiveWriter) WriteLink(link *LinkRecord) error {
+	if err := a.start(); err != nil {
+		return err
+	}
+	if err := a.closeLink(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+	if !a.firstLink {
+		if err := a.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	a.firstLink = false
+
+	// L'objet du lien reste ouvert : ses clics sont ajoutés dans le tableau "clicks".
+	a.w.WriteString("\n")
+	a.w.Write(data[:len(data)-1])
+	_, err = a.w.WriteString(`,"clicks":[`)
+	a.linkOpen, a.firstClick = true, true
+	return err
+}
+
+func (a *archiveWriter) WriteClick(click *ClickRecord) error {
+	if !a.linkOpen {
+		return fmt.Errorf("click for %s written before its link", click.ShortCode)
+	}
+	// Le code court est implicite : c'est celui du lien englobant.
+	data, err := json.Marshal(archiveClick{
+		Timestamp: click.Timestamp,
+		UserAgent: click.UserAgent,
+		IPAddress: click.IPAddress,
+		Country:   click.Country,
+		City:      click.City,
+		Source:    click.Source,
+		Token:     click.Token,
+		Rule:      click.Rule,
+		Variant:   click.Variant,
+	})
+	if err != nil {
+		return err
+	}
+	if !a.firstClick {
+		a.w.WriteByte(',')
+	}
+	a.firstClick = false
+	_, err = a.w.Write(data)
+	return err
+}
+
+func (a *archiveWriter) Close() error {
+	if err := a.start(); err != nil {
+		return err
+	}
+	if err := a.closeLink(); err != nil {
+		return err
+	}
+	if _, err := a.w.WriteString("\n]}\n"); err != nil {
+		return err
+	}
+	return a.w.Flush()
+}
+
+// archiveClick est un clic tel qu'il apparaît dans l'archive, sous son lien.
+type archiveClick struct {
+	Timestamp time.Time `json:"timestamp"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	City      string    `json:"city,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	Token     string    `json:"token,omitempty"`
+	Rule      *int      `json:"rule,omitempty"`
+	Variant   *int      `json:"variant,omitempty"`
+}
+
+type archiveReader struct {
+	dec      *json.Decoder
+	done     bool
+	inClicks bool
+	current  string // Code court du lien dont on lit les clics
+}
+
+func newArchiveReader(r io.Reader) (*archiveReader, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, fmt.Errorf("not a JSON archive: %w", err)
+	}
+
+	var format string
+	var version int
+	for {
+		key, err := nextKey(dec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive header: %w", err)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid archive: missing \"links\"")
+		}
+		switch key {
+		case "format":
+			err = dec.Decode(&format)
+		case "version":
+			err = dec.Decode(&version)
+		case "links":
+			if format != ArchiveFormat {
+				return nil, fmt.Errorf("invalid archive: format %q, expected %q", format, ArchiveFormat)
+			}
+			if version < 1 || version > ArchiveVersion {
+				return nil, fmt.Errorf("unsupported archive version %d (this build reads up to %d)", version, ArchiveVersion)
+			}
+			if err := expectDelim(dec, '['); err != nil {
+				return nil, fmt.Errorf("invalid archive: %w", err)
+			}
+			return &archiveReader{dec: dec}, nil
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive header: %w", err)
+		}
+	}
+}
+
+func (a *archiveReader) Next() (*Record, error) {
+	if a.done {
+		return nil, io.EOF
+	}
+
+	if a.inClicks {
+		if a.dec.More() {
+			var click archiveClick
+			if err := a.dec.Decode(&click); err != nil {
+				return nil, fmt.Errorf("invalid click for %s: %w", a.current, err)
+			}
+			return &Record{Kind: KindClick, Click: &ClickRecord{
+				ShortCode: a.current,
+				Timestamp: click.Timestamp,
+				UserAgent: click.UserAgent,
+				IPAddress: click.IPAddress,
+				Country:   click.Country,
+				City:      click.City,
+				Source:    click.Source,
+				Token:     click.Token,
+				Rule:      click.Rule,
+				Variant:   click.Variant,
+			}}, nil
+		}
+		if err := expectDelim(a.dec, ']'); err != nil {
+			return nil, err
+		}
+		a.inClicks = false
+		// Champs éventuels après "clicks" : ignorés, le lien a déjà été retourné.
+		if err := skipRemainingKeys(a.dec); err != nil {
+			return nil, err
+		}
+	}
+
+	if !a.dec.More() {
+		if err := expectDelim(a.dec, ']'); err != nil {
+			return nil, err
+		}
+		a.done = true
+		return nil, io.EOF
+	}
+	return a.readLink()
+}
+
+// readLink lit les champs d'un lien jusqu'à "clicks" (ou la fin de l'objet) et le retourne.
+func (a *archiveReader) readLink() (*Record, error) {
+	if err := expectDelim(a.dec, '{'); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	for {
+		key, err := nextKey(a.dec)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" || key == "clicks" {
+			if key == "clicks" {
+				if err := expectDelim(a.dec, '['); err != nil {
+					return nil, err
+				}
+				a.inClicks = true
+			}
+			break
+		}
+		var raw json.RawMessage
+		if err := a.dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		fields[key] = raw
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var link LinkRecord
+	if err := json.Unmarshal(data, &link); err != nil {
+		return nil, fmt.Errorf("invalid link: %w", err)
+	}
+	a.current = link.ShortCode
+	return &Record{Kind: KindLink, Link: &link}, nil
+}
+
+// nextKey retourne la clé suivante de l'objet courant, ou "" à la fin de l'objet.
+func nextKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	switch t := tok.(type) {
+	case string:
+		return t, nil
+	case json.Delim:
+		if t == '}' {
+			return "", nil
+		}
+	}
+	return "", fmt.Errorf("unexpected token %v", tok)
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+func skipValue(dec *json.Decoder) error {
+	var raw json.RawMessage
+	return dec.Decode(&raw)
+}
+
+func skipRemainingKeys(dec *json.Decoder) error {
+	for {
+		key, err := nextKey(dec)
+		if err != nil || key == "" {
+			return err
+		}
+		if err := skipValue(dec); err != nil {
+			return err
+		}
+	}
+}
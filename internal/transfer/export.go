@@ -0,0 +1,76 @@
+package transfer
+
+import (
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// defaultBatchSize est le nombre de lignes lues ou écrites en base par lot.
+const defaultBatchSize = 1000
+
+// ExportOptions configure un export.
+type ExportOptions struct {
+	IncludeClicks bool
+	BatchSize     int
+}
+
+// ExportReport résume un export.
+type ExportReport struct {
+	Links  int `json:"links"`
+	Clicks int `json:"clicks"`
+}
+
+// Exporter écrit les liens (et leurs clics) de la base dans un Writer.
+type Exporter struct {
+	linkRepo  repository.LinkRepository
+	clickRepo repository.ClickRepository
+}
+
+// NewExporter crée un Exporter.
+func NewExporter(linkRepo repository.LinkRepository, clickRepo repository.ClickRepository) *Exporter {
+	return &Exporter{linkRepo: linkRepo, clickRepo: clickRepo}
+}
+
+// Export parcourt la base par lots et écrit chaque lien suivi de ses clics.
+// Le Writer n'est pas fermé : c'est à l'appelant d'appeler Close.
+func (e *Exporter) Export(w Writer, opts ExportOptions) (ExportReport, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	var report ExportReport
+	err := e.linkRepo.StreamLinks(batchSize, func(links []models.Link) error {
+		for i := range links {
+			link := &links[i]
+			rec := NewLinkRecord(link)
+			historical, err := e.clickRepo.GetHistoricalClicks(link.ID)
+			if err != nil {
+				return err
+			}
+			rec.HistoricalClicks = historical
+			if err := w.WriteLink(rec); err != nil {
+				return err
+			}
+			report.Links++
+
+			if !opts.IncludeClicks {
+				continue
+			}
+			err = e.clickRepo.StreamClicksByLinkID(link.ID, batchSize, func(clicks []models.Click) error {
+				for j := range clicks {
+					if err := w.WriteClick(NewClickRecord(link, &clicks[j])); err != nil {
+						return err
+					}
+					report.Clicks++
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return report, err
+}
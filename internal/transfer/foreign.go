@@ -0,0 +1,408 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Raccourcisseurs dont les exports peuvent être importés avec --from.
+const (
+	SourceBitly  = "bitly"
+	SourceYOURLS = "yourls"
+	SourceKutt   = "kutt"
+	SourceShlink = "shlink"
+)
+
+// foreignFormat décrit l'export d'un autre raccourcisseur. Les noms de champs sont
+// comparés après normalisation (minuscules, sans espaces ni ponctuation), ce qui couvre
+// à la fois les en-têtes CSV ("Long URL") et les clés JSON ("long_url").
+type foreignFormat struct {
+	jsonPath []string // Chemin du tableau (ou objet) de liens dans l'export JSON
+	code     []string // Champs candidats, par ordre de préférence (le premier code valide l'emporte)
+	longURL  []string
+	created  []string
+	clicks   []string
+}
+
+var foreignFormats = map[string]foreignFormat{
+	// Export CSV du tableau de bord ou réponse de GET /v4/groups/{guid}/bitlinks.
+	SourceBitly: {
+		jsonPath: []string{"links"},
+		code:     []string{"custombitlinks", "customlink", "bitlink", "id", "link", "shortlink", "shorturl"},
+		longURL:  []string{"longurl", "destinationurl", "originalurl", "destination"},
+		created:  []string{"createdat", "datecreated", "created"},
+		clicks:   []string{"clicks", "totalclicks", "userclicks", "engagements"},
+	},
+	// Export de la table yourls_url (CSV) ou réponse de l'API action=stats (JSON).
+	SourceYOURLS: {
+		jsonPath: []string{"links"},
+		code:     []string{"keyword", "shorturl"},
+		longURL:  []string{"url", "longurl"},
+		created:  []string{"timestamp", "date"},
+		clicks:   []string{"clicks"},
+	},
+	// Réponse de GET /api/v2/links.
+	SourceKutt: {
+		jsonPath: []string{"data"},
+		code:     []string{"address", "customurl", "link"},
+		longURL:  []string{"target"},
+		created:  []string{"createdat"},
+		clicks:   []string{"visitcount", "visits"},
+	},
+	// Réponse de GET /rest/v3/short-urls ou export CSV du client web.
+	SourceShlink: {
+		jsonPath: []string{"shortUrls", "data"},
+		code:     []string{"shortcode", "shorturl"},
+		longURL:  []string{"longurl"},
+		created:  []string{"datecreated", "createdat"},
+		clicks:   []string{"visitssummary", "visitscount", "visits"},
+	},
+}
+
+// foreignTimeLayouts couvre les formats de date rencontrés dans ces exports.
+var foreignTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05-0700", // Bitly
+	"2006-01-02 15:04:05",      // YOURLS (heure du serveur, lue comme UTC)
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700 MST",
+	"2006-01-02",
+}
+
+// ForeignSources liste les valeurs acceptées par --from.
+func ForeignSources() []string {
+	return []string{SourceBitly, SourceYOURLS, SourceKutt, SourceShlink}
+}
+
+// NewForeignReader lit l'export d'un autre raccourcisseur (CSV ou JSON, détecté d'après
+// le contenu) et le présente comme un flux de liens, avec leur total de clics historique.
+func NewForeignReader(r io.Reader, source string) (Reader, error) {
+	format, ok := foreignFormats[source]
+	if !ok {
+		return nil, fmt.Errorf("unknown source %q (expected %s)", source, strings.Join(ForeignSources(), ", "))
+	}
+
+	br := bufio.NewReader(r)
+	first, err := firstNonSpace(br)
+	if err != nil {
+		return nil, fmt.Errorf("empty %s export", source)
+	}
+	if first == '{' || first == '[' {
+		return newForeignJSONReader(br, format)
+	}
+	return newForeignCSVReader(br, format)
+}
+
+func firstNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		// Marque d'ordre des octets ajoutée par certains tableurs
+		if bytes.HasPrefix(b, []byte{0xEF}) {
+			if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+				br.Discard(3)
+				continue
+			}
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			return b[0], nil
+		}
+		br.Discard(1)
+	}
+}
+
+// normalizeField ramène un nom de champ à sa forme de comparaison.
+func normalizeField(name string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(name) {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// toRecord construit un lien à partir des champs d'un enregistrement étranger.
+func (f foreignFormat) toRecord(get func(name string) (string, bool)) (*LinkRecord, error) {
+	pick := func(candidates []string) string {
+		for _, name := range candidates {
+			if v, ok := get(name); ok && strings.TrimSpace(v) != "" {
+				return strings.TrimSpace(v)
+			}
+		}
+		return ""
+	}
+
+	rec := &LinkRecord{
+		ShortCode: pickCode(f.code, get),
+		LongURL:   pick(f.longURL),
+	}
+	if rec.ShortCode == "" {
+		return nil, fmt.Errorf("missing short code for %q", rec.LongURL)
+	}
+
+	if created := pick(f.created); created != "" {
+		t, err := parseForeignTime(created)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rec.ShortCode, err)
+		}
+		rec.CreatedAt = t
+	}
+	if clicks := pick(f.clicks); clicks != "" {
+		n, err := strconv.Atoi(strings.ReplaceAll(clicks, ",", ""))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s: invalid click count %q", rec.ShortCode, clicks)
+		}
+		rec.HistoricalClicks = n
+	}
+	return rec, nil
+}
+
+// pickCode retient le premier code candidat qui peut être conservé tel quel
+// (un lien personnalisé trop long cède la place au code généré), sinon le premier trouvé.
+func pickCode(candidates []string, get func(name string) (string, bool)) string {
+	first := ""
+	for _, name := range candidates {
+		v, ok := get(name)
+		if !ok {
+			continue
+		}
+		code := codeFromShortURL(strings.TrimSpace(v))
+		if validShortCode(code) {
+			return code
+		}
+		if first == "" {
+			first = code
+		}
+	}
+	return first
+}
+
+// codeFromShortURL extrait le code d'une URL courte ("https://bit.ly/abc", "bit.ly/abc")
+// ou retourne la valeur telle quelle si c'est déjà un code.
+func codeFromShortURL(value string) string {
+	if !strings.Contains(value, "/") {
+		return value
+	}
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+	path := strings.Trim(u.Path, "/")
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+func parseForeignTime(value string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	for _, layout := range foreignTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+// --- CSV ---
+
+type foreignCSVReader struct {
+	r       *csv.Reader
+	format  foreignFormat
+	columns map[string]int
+}
+
+func newForeignCSVReader(r io.Reader, format foreignFormat) (*foreignCSVReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if key := normalizeField(name); key != "" {
+			if _, dup := columns[key]; !dup {
+				columns[key] = i
+			}
+		}
+	}
+	return &foreignCSVReader{r: cr, format: format, columns: columns}, nil
+}
+
+func (c *foreignCSVReader) Next() (*Record, error) {
+	row, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := c.r.FieldPos(0)
+
+	rec, err := c.format.toRecord(func(name string) (string, bool) {
+		i, ok := c.columns[name]
+		if !ok || i >= len(row) {
+			return "", false
+		}
+		return row[i], true
+	})
+	if err != nil {
+		return nil, &RecordError{Err: fmt.Errorf("line %d: %w", line, err)}
+	}
+	return &Record{Kind: KindLink, Link: rec}, nil
+}
+
+// --- JSON ---
+
+// foreignJSONReader parcourt au fil de l'eau le tableau de liens d'un export JSON.
+// Le conteneur peut aussi être un objet dont chaque valeur est un lien (API YOURLS).
+type foreignJSONReader struct {
+	dec    *json.Decoder
+	format foreignFormat
+	isMap  bool
+	done   bool
+}
+
+func newForeignJSONReader(r io.Reader, format foreignFormat) (*foreignJSONReader, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON export: %w", err)
+	}
+	if tok == json.Delim('[') {
+		return &foreignJSONReader{dec: dec, format: format}, nil
+	}
+
+	// Descente vers le conteneur des liens en suivant jsonPath.
+	for depth, key := range format.jsonPath {
+		found := false
+		for !found {
+			k, err := nextKey(dec)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON export: %w", err)
+			}
+			if k == "" {
+				return nil, fmt.Errorf("invalid JSON export: %q not found", strings.Join(format.jsonPath, "."))
+			}
+			if k != key {
+				if err := skipValue(dec); err != nil {
+					return nil, fmt.Errorf("invalid JSON export: %w", err)
+				}
+				continue
+			}
+			found = true
+		}
+
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON export: %w", err)
+		}
+		last := depth == len(format.jsonPath)-1
+		switch {
+		case tok == json.Delim('[') && last:
+			return &foreignJSONReader{dec: dec, format: format}, nil
+		case tok == json.Delim('{') && last:
+			return &foreignJSONReader{dec: dec, format: format, isMap: true}, nil
+		case tok == json.Delim('{'):
+			continue
+		default:
+			return nil, fmt.Errorf("invalid JSON export: unexpected value for %q", key)
+		}
+	}
+	return nil, fmt.Errorf("invalid JSON export")
+}
+
+func (j *foreignJSONReader) Next() (*Record, error) {
+	if j.done || !j.dec.More() {
+		j.done = true
+		return nil, io.EOF
+	}
+
+	if j.isMap {
+		// Clé de l'entrée ("link_1", ...) : sans intérêt.
+		if _, err := j.dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := j.dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	normalized := make(map[string]json.RawMessage, len(fields))
+	for k, v := range fields {
+		normalized[normalizeField(k)] = v
+	}
+
+	rec, err := j.format.toRecord(func(name string) (string, bool) {
+		raw, ok := normalized[name]
+		if !ok {
+			return "", false
+		}
+		return jsonScalar(raw)
+	})
+	if err != nil {
+		return nil, &RecordError{Err: err}
+	}
+	return &Record{Kind: KindLink, Link: rec}, nil
+}
+
+// jsonScalar retourne une valeur JSON sous forme de texte. Pour un tableau, le premier
+// élément est retenu (custom_bitlinks) ; pour un objet, son champ "total" (visitsSummary de Shlink).
+func jsonScalar(raw json.RawMessage) (string, bool) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return "", false
+	}
+	switch t := v.(type) {
+	case string:
+		return t, true
+	case json.Number:
+		return t.String(), true
+	case []any:
+		if len(t) > 0 {
+			if s, ok := t[0].(string); ok {
+				return s, true
+			}
+		}
+	case map[string]any:
+		if total, ok := t["total"].(json.Number); ok {
+			return total.String(), true
+		}
+	}
+	return "", false
+}
+
+// RecordError signale un enregistrement illisible : l'import le compte en erreur
+// et passe au suivant, contrairement à une erreur de lecture du flux.
+type RecordError struct {
+	Err error
+}
+
+func (e *RecordError) Error() string { return e.Err.Error() }
+func (e *RecordError) Unwrap() error { return e.Err }
+
+// isRecordError indique si err concerne un seul enregistrement.
+func isRecordError(err error) bool {
+	var recErr *RecordError
+	return errors.As(err, &recErr)
+}
@@ -0,0 +1,446 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Politiques appliquées quand un code court importé existe déjà en base.
+const (
+	ConflictSkip      = "skip"      // Le lien existant est conservé, l'enregistrement et ses clics sont ignorés
+	ConflictOverwrite = "overwrite" // Le lien existant est remplacé et ses clics par ceux de l'import
+	ConflictRename    = "rename"    // Le lien importé reçoit un nouveau code court
+)
+
+// SourceNative identifie les totaux historiques réimportés depuis un export de ce service.
+const SourceNative = "import"
+
+// maxRenameAttempts borne la recherche d'un code libre pour la politique rename.
+const maxRenameAttempts = 5
+
+// maxReportedErrors limite le nombre d'erreurs détaillées conservées dans le rapport.
+const maxReportedErrors = 100
+
+// ImportOptions configure un import.
+type ImportOptions struct {
+	OnConflict string
+	DryRun     bool   // N'écrit rien : le rapport décrit ce qui serait fait
+	Source     string // Origine enregistrée avec les totaux historiques (SourceNative par défaut)
+	BatchSize  int
+}
+
+// ImportReport résume un import (ou ce qu'il ferait, en dry-run).
+type ImportReport struct {
+	DryRun           bool              `json:"dry_run"`
+	LinksCreated     int               `json:"links_created"`
+	LinksSkipped     int               `json:"links_skipped"`
+	LinksOverwritten int               `json:"links_overwritten"`
+	LinksRenamed     int               `json:"links_renamed"`
+	Renamed          map[string]string `json:"renamed,omitempty"` // Ancien code -> nouveau code
+	ClicksImported   int               `json:"clicks_imported"`
+	ClicksSkipped    int               `json:"clicks_skipped"`
+	Failed           int               `json:"failed"`
+	Errors           []string          `json:"errors,omitempty"`
+}
+
+func (r *ImportReport) fail(format string, args ...any) {
+	r.Failed++
+	if len(r.Errors) < maxReportedErrors {
+		r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+	}
+}
+
+// Importer charge un flux d'enregistrements dans la base.
+type Importer struct {
+	linkRepo     repository.LinkRepository
+	clickRepo    repository.ClickRepository
+	generateCode func(length int) (string, error)
+	normalize    func(link *models.Link) error
+}
+
+// NewImporter crée un Importer ; generateCode fournit les nouveaux codes de la politique rename,
+// normalize valide et normalise chaque lien comme à sa création par l'API (destination, règles
+// de ciblage, variantes A/B, réglages) : les liens refusés sont comptés en échec.
+func NewImporter(linkRepo repository.LinkRepository, clickRepo repository.ClickRepository,
+	generateCode func(length int) (string, error), normalize func(link *models.Link) error) *Importer {
+	return &Importer{linkRepo: linkRepo, clickRepo: clickRepo, generateCode: generateCode, normalize: normalize}
+}
+
+// importTarget indique où vont les clics d'un code court de l'import.
+type importTarget struct {
+	linkID   uint
+	skip     bool
+	rules    map[int]uint // Position de la règle -> ID en base
+	variants map[int]uint // Position de la variante -> ID en base
+}
+
+// newImportTarget retourne la cible des clics d'un lien enregistré avec ses règles et ses variantes.
+func newImportTarget(link *models.Link) importTarget {
+	target := importTarget{linkID: link.ID}
+	if len(link.Rules) > 0 {
+		target.rules = make(map[int]uint, len(link.Rules))
+		for _, rule := range link.Rules {
+			target.rules[rule.Position] = rule.ID
+		}
+	}
+	if len(link.Variants) > 0 {
+		target.variants = make(map[int]uint, len(link.Variants))
+		for _, variant := range link.Variants {
+			target.variants[variant.Position] = variant.ID
+		}
+	}
+	return target
+}
+
+// importRun porte l'état d'un import en cours.
+type importRun struct {
+	*Importer
+	opts    ImportOptions
+	report  ImportReport
+	targets map[string]importTarget // Code court de l'import -> lien cible
+	taken   map[string]bool         // Codes réservés en dry-run (rien n'est écrit en base)
+	pending []models.Click
+	nextID  uint // IDs fictifs attribués en dry-run
+}
+
+// Import lit le flux jusqu'au bout et applique la politique de conflit.
+// Les enregistrements invalides sont comptés dans le rapport sans interrompre l'import ;
+// une erreur de lecture du flux ou de la base l'interrompt.
+// Les clics sont insérés par lots ; l'import n'est pas atomique.
+func (i *Importer) Import(r Reader, opts ImportOptions) (ImportReport, error) {
+	switch opts.OnConflict {
+	case "":
+		opts.OnConflict = ConflictSkip
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return ImportReport{}, fmt.Errorf("unknown conflict policy %q (expected skip, overwrite or rename)", opts.OnConflict)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.Source == "" {
+		opts.Source = SourceNative
+	}
+
+	run := &importRun{
+		Importer: i,
+		opts:     opts,
+		report:   ImportReport{DryRun: opts.DryRun},
+		targets:  make(map[string]importTarget),
+		taken:    make(map[string]bool),
+	}
+
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if isRecordError(err) {
+			run.report.fail("%v", err)
+			continue
+		}
+		if err != nil {
+			return run.report, err
+		}
+
+		switch rec.Kind {
+		case KindLink:
+			err = run.importLink(rec.Link)
+		case KindClick:
+			err = run.importClick(rec.Click)
+		}
+		if err != nil {
+			return run.report, err
+		}
+	}
+
+	return run.report, run.flush()
+}
+
+func (r *importRun) importLink(rec *LinkRecord) error {
+	// Les clics en attente appartiennent au lien précédent.
+	if err := r.flush(); err != nil {
+		return err
+	}
+
+	if err := rec.validate(); err != nil {
+		r.report.fail("link: %v", err)
+		r.targets[rec.ShortCode] = importTarget{skip: true}
+		return nil
+	}
+	if _, seen := r.targets[rec.ShortCode]; seen {
+		r.report.fail("link %s: duplicate short code in import", rec.ShortCode)
+		return nil
+	}
+	if r.normalize != nil {
+		// Le lien est validé sous sa forme de modèle, puis l'enregistrement reprend les
+		// valeurs normalisées.
+		link := &models.Link{}
+		rec.apply(link)
+		if err := r.normalize(link); err != nil {
+			r.report.fail("link %s: %v", rec.ShortCode, err)
+			r.targets[rec.ShortCode] = importTarget{skip: true}
+			return nil
+		}
+		normalized := NewLinkRecord(link)
+		normalized.HistoricalClicks = rec.HistoricalClicks
+		*rec = *normalized
+	}
+
+	// Un code qui ne respecte pas le format des codes courts ne peut pas être conservé.
+	if !validShortCode(rec.ShortCode) {
+		return r.rename(rec)
+	}
+
+	existing, err := r.lookup(rec.ShortCode)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		target, err := r.create(rec)
+		if err != nil {
+			return err
+		}
+		r.report.LinksCreated++
+		r.targets[rec.ShortCode] = target
+		return nil
+	}
+
+	switch r.opts.OnConflict {
+	case ConflictOverwrite:
+		if !r.opts.DryRun {
+			rec.apply(existing)
+			if err := r.linkRepo.UpdateLink(existing); err != nil {
+				return fmt.Errorf("failed to overwrite link %s: %w", rec.ShortCode, err)
+			}
+			// Les règles et les variantes de l'import remplacent celles du lien existant.
+			if err := r.linkRepo.ReplaceRules(existing, existing.Rules); err != nil {
+				return fmt.Errorf("failed to replace rules of %s: %w", rec.ShortCode, err)
+			}
+			if err := r.linkRepo.ReplaceVariants(existing, existing.Variants); err != nil {
+				return fmt.Errorf("failed to replace variants of %s: %w", rec.ShortCode, err)
+			}
+			if err := r.clickRepo.DeleteClicksByLinkID(existing.ID); err != nil {
+				return fmt.Errorf("failed to delete clicks of %s: %w", rec.ShortCode, err)
+			}
+			if err := r.clickRepo.SetHistoricalClicks(existing.ID, r.opts.Source, rec.HistoricalClicks); err != nil {
+				return fmt.Errorf("failed to store click totals of %s: %w", rec.ShortCode, err)
+			}
+		}
+		r.report.LinksOverwritten++
+		r.targets[rec.ShortCode] = newImportTarget(existing)
+		return nil
+
+	case ConflictRename:
+		return r.rename(rec)
+
+	default:
+		r.report.LinksSkipped++
+		r.targets[rec.ShortCode] = importTarget{skip: true}
+		return nil
+	}
+}
+
+// rename importe le lien sous un nouveau code court ; ses clics suivent via l'ancien code.
+func (r *importRun) rename(rec *LinkRecord) error {
+	code, err := r.freeCode(len(rec.ShortCode))
+	if err != nil {
+		return err
+	}
+	original := rec.ShortCode
+	rec.ShortCode = code
+	target, err := r.create(rec)
+	if err != nil {
+		return err
+	}
+	r.report.LinksRenamed++
+	if r.report.Renamed == nil {
+		r.report.Renamed = make(map[string]string)
+	}
+	r.report.Renamed[original] = code
+	r.targets[original] = target
+	return nil
+}
+
+func (r *importRun) importClick(rec *ClickRecord) error {
+	target, ok := r.targets[rec.ShortCode]
+	if !ok {
+		// Clic d'un lien absent de l'import : rattaché au lien existant s'il y en a un.
+		link, err := r.lookup(rec.ShortCode)
+		if err != nil {
+			return err
+		}
+		if link == nil {
+			target = importTarget{skip: true}
+			r.report.fail("clicks for %s: unknown short code", rec.ShortCode)
+		} else {
+			target = newImportTarget(link)
+		}
+		r.targets[rec.ShortCode] = target
+	}
+
+	if target.skip {
+		r.report.ClicksSkipped++
+		return nil
+	}
+
+	click := models.Click{
+		LinkID:    target.linkID,
+		Timestamp: rec.Timestamp,
+		UserAgent: rec.UserAgent,
+		IPAddress: rec.IPAddress,
+		Country:   rec.Country,
+		City:      truncate(rec.City, maxClickCity),
+		Source:    truncate(rec.Source, maxClickSource),
+	}
+	if len(click.Country) != 2 {
+		click.Country = ""
+	}
+	if rec.Token != "" && len(rec.Token) <= maxClickToken {
+		token := rec.Token
+		click.Token = &token
+	}
+	// Une position inconnue (règle ou variante absente du lien cible) laisse le clic sans référence.
+	if rec.Rule != nil {
+		if id, ok := target.rules[*rec.Rule]; ok {
+			click.RuleID = &id
+		}
+	}
+	if rec.Variant != nil {
+		if id, ok := target.variants[*rec.Variant]; ok {
+			click.VariantID = &id
+		}
+	}
+	r.pending = append(r.pending, click)
+	if len(r.pending) >= r.opts.BatchSize {
+		return r.flush()
+	}
+	return nil
+}
+
+// Tailles des colonnes de la table clicks pour les champs importés.
+const (
+	maxClickCity   = 100
+	maxClickSource = 20
+	maxClickToken  = 32
+)
+
+// truncate limite s à n caractères (les tailles de colonnes sont en caractères).
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+// dropTakenTokens retire des clics en attente les jetons déjà attribués (en base ou plus
+// tôt dans le lot) : un jeton identifie un seul clic pour l'attribution des conversions.
+func (r *importRun) dropTakenTokens() error {
+	var tokens []string
+	for i := range r.pending {
+		if r.pending[i].Token != nil {
+			tokens = append(tokens, *r.pending[i].Token)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+	taken, err := r.clickRepo.ExistingTokens(tokens)
+	if err != nil {
+		return fmt.Errorf("failed to look up click tokens: %w", err)
+	}
+	for i := range r.pending {
+		token := r.pending[i].Token
+		if token == nil {
+			continue
+		}
+		if taken[*token] {
+			r.pending[i].Token = nil
+			continue
+		}
+		taken[*token] = true
+	}
+	return nil
+}
+
+// flush insère les clics en attente.
+func (r *importRun) flush() error {
+	if len(r.pending) == 0 {
+		return nil
+	}
+	if !r.opts.DryRun {
+		if err := r.dropTakenTokens(); err != nil {
+			return err
+		}
+		if err := r.clickRepo.CreateClicks(r.pending); err != nil {
+			return fmt.Errorf("failed to insert clicks: %w", err)
+		}
+	}
+	r.report.ClicksImported += len(r.pending)
+	r.pending = r.pending[:0]
+	return nil
+}
+
+// lookup retourne le lien existant pour ce code, ou nil s'il n'existe pas.
+func (r *importRun) lookup(shortCode string) (*models.Link, error) {
+	link, err := r.linkRepo.GetLinkByShortCode(shortCode)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if r.taken[shortCode] {
+			return &models.Link{ShortCode: shortCode}, nil
+		}
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("database error looking up %s: %w", shortCode, err)
+	}
+	return link, nil
+}
+
+// create insère le lien avec ses règles et ses variantes (sauf en dry-run) et retourne
+// la cible de ses clics.
+func (r *importRun) create(rec *LinkRecord) (importTarget, error) {
+	if r.opts.DryRun {
+		r.taken[rec.ShortCode] = true
+		r.nextID++
+		return importTarget{linkID: r.nextID}, nil
+	}
+
+	var link models.Link
+	rec.apply(&link)
+	if err := r.linkRepo.CreateLink(&link); err != nil {
+		return importTarget{}, fmt.Errorf("failed to create link %s: %w", rec.ShortCode, err)
+	}
+	if rec.HistoricalClicks > 0 {
+		if err := r.clickRepo.SetHistoricalClicks(link.ID, r.opts.Source, rec.HistoricalClicks); err != nil {
+			return importTarget{}, fmt.Errorf("failed to store click totals of %s: %w", rec.ShortCode, err)
+		}
+	}
+	return newImportTarget(&link), nil
+}
+
+// freeCode génère un code court inutilisé de la longueur demandée.
+func (r *importRun) freeCode(length int) (string, error) {
+	if length < 6 || length > 10 {
+		length = 6
+	}
+	for attempt := 0; attempt < maxRenameAttempts; attempt++ {
+		code, err := r.generateCode(length)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate short code: %w", err)
+		}
+		existing, err := r.lookup(code)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return code, nil
+		}
+	}
+	return "", errors.New("failed to generate a unique short code after several attempts")
+}
@@ -0,0 +1,86 @@
+package transfer_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/database/dbtest"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/transfer"
+	"gorm.io/gorm"
+)
+
+// importJSONL importe des lignes JSON Lines dans une base neuve, avec la validation de la CLI.
+func importJSONL(t *testing.T, lines ...string) (transfer.ImportReport, repository.LinkRepository) {
+	t.Helper()
+	db := dbtest.Migrated(t, dbtest.Targets(t)[0])
+	linkRepo := repository.NewLinkRepository(db)
+	linkService := services.NewLinkService(linkRepo)
+	importer := transfer.NewImporter(linkRepo, repository.NewClickRepository(db),
+		linkService.GenerateShortCode, linkService.NormalizeLink)
+
+	reader, err := transfer.NewReader(strings.NewReader(strings.Join(lines, "\n")+"\n"), transfer.FormatJSONL)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	report, err := importer.Import(reader, transfer.ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	return report, linkRepo
+}
+
+func TestImportRejectsInvalidRedirectType(t *testing.T) {
+	report, linkRepo := importJSONL(t,
+		`{"type":"link","data":{"short_code":"bad","long_url":"https://example.com/","redirect_type":200}}`,
+		`{"type":"link","data":{"short_code":"good","long_url":"https://example.com/","redirect_type":307}}`,
+	)
+
+	if report.LinksCreated != 1 || report.Failed != 1 {
+		t.Errorf("report: %d created, %d failed; want 1 created, 1 failed (errors: %v)", report.LinksCreated, report.Failed, report.Errors)
+	}
+	if _, err := linkRepo.GetLinkByShortCode("bad"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("link with redirect_type 200 was stored (err = %v)", err)
+	}
+	link, err := linkRepo.GetLinkByShortCode("good")
+	if err != nil {
+		t.Fatalf("GetLinkByShortCode: %v", err)
+	}
+	if link.RedirectType != 307 {
+		t.Errorf("RedirectType = %d, want 307", link.RedirectType)
+	}
+}
+
+func TestImportValidatesLinkSettings(t *testing.T) {
+	longUTM := strings.Repeat("x", 300)
+	report, linkRepo := importJSONL(t,
+		`{"type":"link","data":{"short_code":"js","long_url":"https://example.com/","failure_policy":"fallback","fallback_url":"javascript:alert(1)"}}`,
+		`{"type":"link","data":{"short_code":"nofb","long_url":"https://example.com/","failure_policy":"fallback"}}`,
+		`{"type":"link","data":{"short_code":"policy","long_url":"https://example.com/","failure_policy":"redirect"}}`,
+		`{"type":"link","data":{"short_code":"utm","long_url":"https://example.com/","utm_source":"`+longUTM+`"}}`,
+		`{"type":"link","data":{"short_code":"image","long_url":"https://example.com/","og_image":"ftp://example.com/a.png"}}`,
+		`{"type":"link","data":{"short_code":"ok","long_url":"https://example.com/","failure_policy":"fallback","fallback_url":"https://Example.com/repli","utm_source":" news "}}`,
+	)
+
+	if report.LinksCreated != 1 || report.Failed != 5 {
+		t.Errorf("report: %d created, %d failed; want 1 created, 5 failed (errors: %v)", report.LinksCreated, report.Failed, report.Errors)
+	}
+	for _, code := range []string{"js", "nofb", "policy", "utm", "image"} {
+		if _, err := linkRepo.GetLinkByShortCode(code); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("invalid link %s was stored (err = %v)", code, err)
+		}
+	}
+
+	link, err := linkRepo.GetLinkByShortCode("ok")
+	if err != nil {
+		t.Fatalf("GetLinkByShortCode: %v", err)
+	}
+	if link.FallbackURL != "https://example.com/repli" {
+		t.Errorf("FallbackURL = %q, want the normalized URL", link.FallbackURL)
+	}
+	if link.UTM.Source != "news" {
+		t.Errorf("UTM source = %q, want %q", link.UTM.Source, "news")
+	}
+}
@@ -0,0 +1,352 @@
+package transfer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Formats d'échange supportés par l'export et l'import.
+const (
+	FormatCSV     = "csv"     // Un fichier, une ligne par lien ou par clic (colonne "type")
+	FormatJSONL   = "jsonl"   // JSON Lines, un enregistrement par ligne
+	FormatArchive = "archive" // Document JSON versionné : liens avec leur historique de clics
+)
+
+// ArchiveFormat et ArchiveVersion identifient une archive JSON produite par ce service.
+const (
+	ArchiveFormat  = "urlshortener-archive"
+	ArchiveVersion = 1
+)
+
+// Types d'enregistrements.
+const (
+	KindLink  = "link"
+	KindClick = "click"
+)
+
+// LinkRecord est la représentation portable d'un lien.
+type LinkRecord struct {
+	ShortCode string    `json:"short_code"`
+	LongURL   string    `json:"long_url"`
+	CreatedAt time.Time `json:"created_at"`
+
+	FailurePolicy    string `json:"failure_policy,omitempty"`
+	FailureThreshold int    `json:"failure_threshold,omitempty"`
+	FallbackURL      string `json:"fallback_url,omitempty"`
+
+	ContentMonitoring bool `json:"content_monitoring,omitempty"`
+	Interstitial      bool `json:"interstitial,omitempty"`
+
+	RedirectType int  `json:"redirect_type,omitempty"`
+	ForwardQuery bool `json:"forward_query,omitempty"`
+	ForwardPath  bool `json:"forward_path,omitempty"`
+
+	UTMSource   string `json:"utm_source,omitempty"`
+	UTMMedium   string `json:"utm_medium,omitempty"`
+	UTMCampaign string `json:"utm_campaign,omitempty"`
+	UTMTerm     string `json:"utm_term,omitempty"`
+	UTMContent  string `json:"utm_content,omitempty"`
+
+	OGTitle       string `json:"og_title,omitempty"`
+	OGDescription string `json:"og_description,omitempty"`
+	OGImage       string `json:"og_image,omitempty"`
+
+	PasswordHash     string `json:"password_hash,omitempty"` // Empreinte bcrypt : la protection survit à l'export
+	RequireSignature bool   `json:"require_signature,omitempty"`
+
+	// Décisions de modération : un lien bloqué ou signalé le reste après réimport.
+	Disabled       bool       `json:"disabled,omitempty"`
+	DisabledReason string     `json:"disabled_reason,omitempty"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	Flagged        bool       `json:"flagged,omitempty"`
+
+	MonitorDisabled        bool `json:"monitor_disabled,omitempty"`
+	MonitorIntervalMinutes int  `json:"monitor_interval_minutes,omitempty"`
+	MonitorPriority        int  `json:"monitor_priority,omitempty"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	Rules          []RuleRecord    `json:"rules,omitempty"`    // Règles de ciblage, par ordre d'évaluation
+	Variants       []VariantRecord `json:"variants,omitempty"` // Variantes A/B
+	StickyVariants bool            `json:"sticky_variants,omitempty"`
+
+	TrackConversions bool   `json:"track_conversions,omitempty"`
+	ConversionGoal   string `json:"conversion_goal,omitempty"`
+
+	// Clics antérieurs connus seulement par leur total (import depuis un autre raccourcisseur).
+	HistoricalClicks int `json:"historical_clicks,omitempty"`
+}
+
+// RuleRecord est la représentation portable d'une règle de ciblage.
+type RuleRecord struct {
+	OS          string `json:"os,omitempty"`
+	Device      string `json:"device,omitempty"`
+	Languages   string `json:"languages,omitempty"` // Séparées par des virgules, comme en base
+	Countries   string `json:"countries,omitempty"` // Séparés par des virgules, comme en base
+	TimeFrom    string `json:"time_from,omitempty"`
+	TimeTo      string `json:"time_to,omitempty"`
+	Timezone    string `json:"timezone,omitempty"`
+	Destination string `json:"destination"`
+}
+
+// VariantRecord est la représentation portable d'une variante A/B.
+type VariantRecord struct {
+	Name        string `json:"name,omitempty"`
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight,omitempty"`
+}
+
+// ClickRecord est la représentation portable d'un clic ; il référence son lien par code court,
+// et la règle ou la variante appliquée par sa position dans le lien (les IDs ne sont pas portables).
+type ClickRecord struct {
+	ShortCode string    `json:"short_code"`
+	Timestamp time.Time `json:"timestamp"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	IPAddress string    `json:"ip_address,omitempty"`
+
+	Country string `json:"country,omitempty"`
+	City    string `json:"city,omitempty"`
+	Source  string `json:"source,omitempty"`
+	Token   string `json:"token,omitempty"`
+
+	Rule    *int `json:"rule,omitempty"`    // Position de la règle de ciblage appliquée
+	Variant *int `json:"variant,omitempty"` // Position de la variante A/B servie
+}
+
+// Record est un enregistrement lu ou écrit : soit un lien, soit un clic.
+// Les clics d'un lien suivent toujours le lien lui-même.
+type Record struct {
+	Kind  string
+	Link  *LinkRecord
+	Click *ClickRecord
+}
+
+// NewLinkRecord convertit un modèle en enregistrement portable.
+func NewLinkRecord(link *models.Link) *LinkRecord {
+	return &LinkRecord{
+		ShortCode:              link.ShortCode,
+		LongURL:                link.LongURL,
+		CreatedAt:              link.CreatedAt,
+		FailurePolicy:          link.FailurePolicy,
+		FailureThreshold:       link.FailureThreshold,
+		FallbackURL:            link.FallbackURL,
+		ContentMonitoring:      link.ContentMonitoring,
+		Interstitial:           link.Interstitial,
+		RedirectType:           link.RedirectType,
+		ForwardQuery:           link.ForwardQuery,
+		ForwardPath:            link.ForwardPath,
+		UTMSource:              link.UTM.Source,
+		UTMMedium:              link.UTM.Medium,
+		UTMCampaign:            link.UTM.Campaign,
+		UTMTerm:                link.UTM.Term,
+		UTMContent:             link.UTM.Content,
+		OGTitle:                link.Preview.Title,
+		OGDescription:          link.Preview.Description,
+		OGImage:                link.Preview.Image,
+		PasswordHash:           link.PasswordHash,
+		RequireSignature:       link.RequireSignature,
+		Disabled:               link.Disabled,
+		DisabledReason:         link.DisabledReason,
+		DisabledAt:             link.DisabledAt,
+		Flagged:                link.Flagged,
+		MonitorDisabled:        link.MonitorDisabled,
+		MonitorIntervalMinutes: link.MonitorIntervalMinutes,
+		MonitorPriority:        link.MonitorPriority,
+		Metadata:               link.Metadata,
+		Rules:                  newRuleRecords(link.Rules),
+		Variants:               newVariantRecords(link.Variants),
+		StickyVariants:         link.StickyVariants,
+		TrackConversions:       link.TrackConversions,
+		ConversionGoal:         link.ConversionGoal,
+	}
+}
+
+func newRuleRecords(rules []models.LinkRule) []RuleRecord {
+	if len(rules) == 0 {
+		return nil
+	}
+	records := make([]RuleRecord, len(rules))
+	for i, rule := range rules {
+		records[i] = RuleRecord{
+			OS:          rule.OS,
+			Device:      rule.Device,
+			Languages:   rule.Languages,
+			Countries:   rule.Countries,
+			TimeFrom:    rule.TimeFrom,
+			TimeTo:      rule.TimeTo,
+			Timezone:    rule.Timezone,
+			Destination: rule.Destination,
+		}
+	}
+	return records
+}
+
+func newVariantRecords(variants []models.LinkVariant) []VariantRecord {
+	if len(variants) == 0 {
+		return nil
+	}
+	records := make([]VariantRecord, len(variants))
+	for i, variant := range variants {
+		records[i] = VariantRecord{Name: variant.Name, Destination: variant.Destination, Weight: variant.Weight}
+	}
+	return records
+}
+
+// NewClickRecord convertit un clic du lien (chargé avec ses règles et ses variantes)
+// en enregistrement portable.
+func NewClickRecord(link *models.Link, click *models.Click) *ClickRecord {
+	rec := &ClickRecord{
+		ShortCode: link.ShortCode,
+		Timestamp: click.Timestamp,
+		UserAgent: click.UserAgent,
+		IPAddress: click.IPAddress,
+		Country:   click.Country,
+		City:      click.City,
+		Source:    click.Source,
+	}
+	if click.Token != nil {
+		rec.Token = *click.Token
+	}
+	if click.RuleID != nil {
+		for _, rule := range link.Rules {
+			if rule.ID == *click.RuleID {
+				position := rule.Position
+				rec.Rule = &position
+				break
+			}
+		}
+	}
+	if click.VariantID != nil {
+		for _, variant := range link.Variants {
+			if variant.ID == *click.VariantID {
+				position := variant.Position
+				rec.Variant = &position
+				break
+			}
+		}
+	}
+	return rec
+}
+
+// apply reporte l'enregistrement sur un modèle (l'ID n'est pas modifié).
+func (r *LinkRecord) apply(link *models.Link) {
+	link.ShortCode = r.ShortCode
+	link.LongURL = r.LongURL
+	if !r.CreatedAt.IsZero() {
+		link.CreatedAt = r.CreatedAt
+	}
+	link.FailurePolicy = r.FailurePolicy
+	if link.FailurePolicy == "" {
+		link.FailurePolicy = models.FailurePolicyKeep
+	}
+	link.FailureThreshold = r.FailureThreshold
+	link.FallbackURL = r.FallbackURL
+	link.ContentMonitoring = r.ContentMonitoring
+	link.Interstitial = r.Interstitial
+	link.RedirectType = r.RedirectType
+	link.ForwardQuery = r.ForwardQuery
+	link.ForwardPath = r.ForwardPath
+	link.UTM = models.UTMParams{
+		Source:   r.UTMSource,
+		Medium:   r.UTMMedium,
+		Campaign: r.UTMCampaign,
+		Term:     r.UTMTerm,
+		Content:  r.UTMContent,
+	}
+	link.Preview = models.LinkPreview{Title: r.OGTitle, Description: r.OGDescription, Image: r.OGImage}
+	link.PasswordHash = r.PasswordHash
+	link.RequireSignature = r.RequireSignature
+	link.Disabled = r.Disabled
+	link.DisabledReason = r.DisabledReason
+	link.DisabledAt = r.DisabledAt
+	if link.Disabled && link.DisabledAt == nil {
+		now := time.Now()
+		link.DisabledAt = &now
+	}
+	link.Flagged = r.Flagged
+	link.MonitorDisabled = r.MonitorDisabled
+	link.MonitorIntervalMinutes = r.MonitorIntervalMinutes
+	link.MonitorPriority = r.MonitorPriority
+	link.Metadata = r.Metadata
+	link.Rules = r.ruleModels()
+	link.Variants = r.variantModels()
+	link.StickyVariants = r.StickyVariants
+	link.TrackConversions = r.TrackConversions
+	link.ConversionGoal = r.ConversionGoal
+}
+
+func (r *LinkRecord) ruleModels() []models.LinkRule {
+	if len(r.Rules) == 0 {
+		return nil
+	}
+	rules := make([]models.LinkRule, len(r.Rules))
+	for i, rule := range r.Rules {
+		rules[i] = models.LinkRule{
+			OS:          rule.OS,
+			Device:      rule.Device,
+			Languages:   rule.Languages,
+			Countries:   rule.Countries,
+			TimeFrom:    rule.TimeFrom,
+			TimeTo:      rule.TimeTo,
+			Timezone:    rule.Timezone,
+			Destination: rule.Destination,
+		}
+	}
+	return rules
+}
+
+func (r *LinkRecord) variantModels() []models.LinkVariant {
+	if len(r.Variants) == 0 {
+		return nil
+	}
+	variants := make([]models.LinkVariant, len(r.Variants))
+	for i, variant := range r.Variants {
+		variants[i] = models.LinkVariant{Name: variant.Name, Destination: variant.Destination, Weight: variant.Weight}
+	}
+	return variants
+}
+
+// validShortCode indique si un code importé peut être conservé tel quel
+// (même alphabet et longueur maximale que les codes de la table links).
+func validShortCode(code string) bool {
+	if code == "" || len(code) > 10 {
+		return false
+	}
+	for _, c := range code {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validate vérifie les champs indispensables d'un lien importé.
+func (r *LinkRecord) validate() error {
+	if r.ShortCode == "" {
+		return fmt.Errorf("missing short_code")
+	}
+	if r.LongURL == "" {
+		return fmt.Errorf("missing long_url for %s", r.ShortCode)
+	}
+	return nil
+}
+
+// DetectFormat déduit le format de l'extension du fichier.
+func DetectFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".json":
+		return FormatArchive, nil
+	default:
+		return "", fmt.Errorf("cannot detect format of %q, use --format (csv, jsonl or archive)", path)
+	}
+}
@@ -0,0 +1,58 @@
+// Package urlnorm ramène une URL de destination à une forme canonique, afin de
+// reconnaître deux écritures d'une même destination.
+package urlnorm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Normalize retourne la forme canonique d'une URL :
+//   - schéma et hôte en minuscules, port par défaut retiré ;
+//   - chemin vide remplacé par "/" ;
+//   - paramètres de requête triés par nom (l'ordre des valeurs d'un même nom est conservé) ;
+//   - fragment supprimé.
+func Normalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !isDefaultPort(u.Scheme, port) {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6 sans port
+	}
+	u.Host = host
+
+	if u.Path == "" && u.Opaque == "" {
+		u.Path = "/"
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = u.Query().Encode()
+	}
+	u.ForceQuery = false
+	u.Fragment, u.RawFragment = "", ""
+
+	return u.String(), nil
+}
+
+// Hash retourne l'empreinte SHA-256 (hexadécimale) de la forme canonique de l'URL.
+// Une URL impossible à analyser est hachée telle quelle.
+func Hash(raw string) string {
+	normalized, err := Normalize(raw)
+	if err != nil {
+		normalized = raw
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func isDefaultPort(scheme, port string) bool {
+	return (scheme == "http" && port == "80") || (scheme == "https" && port == "443")
+}
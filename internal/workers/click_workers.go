@@ -3,32 +3,45 @@ package workers
 import (
 	"log"
 
+	"github.com/axellelanca/urlshortener/internal/geoip"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository" // Nécessaire pour interagir avec le ClickRepository
 )
 
 // StartClickWorkers lance un pool de goroutines "workers" pour traiter les événements de clic.
 // Chaque worker lira depuis le même 'clickEventsChan' et utilisera le 'clickRepo' pour la persistance.
-func StartClickWorkers(workerCount int, clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository) {
+// Si 'locator' n'est pas nil, le pays et la ville de chaque clic sont déduits de son adresse IP.
+func StartClickWorkers(workerCount int, clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository, locator *geoip.Locator) {
 	log.Printf("Starting %d click worker(s)...", workerCount)
 	for i := 0; i < workerCount; i++ {
 		// Lance chaque worker dans sa propre goroutine.
 		// Le channel est passé en lecture seule (<-chan) pour renforcer l'immutabilité du channel à l'intérieur du worker.
-		go clickWorker(clickEventsChan, clickRepo)
+		go clickWorker(clickEventsChan, clickRepo, locator)
 	}
 }
 
 // clickWorker est la fonction exécutée par chaque goroutine worker.
 // Elle tourne indéfiniment, lisant les événements de clic dès qu'ils sont disponibles dans le channel.
-func clickWorker(clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository) {
+func clickWorker(clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository, locator *geoip.Locator) {
 	for event := range clickEventsChan { // Boucle qui lit les événements du channel
 		//  1: Convertir le 'ClickEvent' (reçu du channel) en un modèle 'models.Click'.
 
 		click := &models.Click{
 			LinkID:    event.LinkID,
+			Timestamp: event.Timestamp,
 			UserAgent: event.UserAgent,
 			IPAddress: event.IPAddress,
+			RuleID:    event.RuleID,
+			VariantID: event.VariantID,
+			Source:    event.Source,
 		}
+		if event.Token != "" {
+			click.Token = &event.Token
+		}
+
+		// Enrichissement géographique (sans effet si aucune base GeoIP n'est configurée)
+		location := locator.Lookup(event.IPAddress)
+		click.Country, click.City = location.Country, location.City
 
 		//  2: Persister le clic en base de données via le 'clickRepo' (CreateClick).
 		err := clickRepo.CreateClick(click)
@@ -0,0 +1,84 @@
+package workers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/geoip"
+	"github.com/axellelanca/urlshortener/internal/geoip/geoiptest"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// recordingClickRepo transmet les clics enregistrés par les workers au test.
+type recordingClickRepo struct {
+	repository.ClickRepository // Méthodes non utilisées par les workers
+	clicks                     chan *models.Click
+}
+
+func (r *recordingClickRepo) CreateClick(click *models.Click) error {
+	r.clicks <- click
+	return nil
+}
+
+// process fait traiter les événements par un worker et retourne les clics enregistrés, dans l'ordre.
+func process(t *testing.T, locator *geoip.Locator, events ...models.ClickEvent) []*models.Click {
+	t.Helper()
+	repo := &recordingClickRepo{clicks: make(chan *models.Click, len(events))}
+	eventsChan := make(chan models.ClickEvent, len(events))
+	for _, event := range events {
+		eventsChan <- event
+	}
+	close(eventsChan)
+	go clickWorker(eventsChan, repo, locator)
+
+	clicks := make([]*models.Click, 0, len(events))
+	for range events {
+		select {
+		case click := <-repo.clicks:
+			clicks = append(clicks, click)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d of %d clicks recorded", len(clicks), len(events))
+		}
+	}
+	return clicks
+}
+
+func TestClickWorkerAddsLocation(t *testing.T) {
+	locator := geoiptest.Open(t)
+	ruleID, variantID := uint(3), uint(4)
+	clicks := process(t, locator,
+		models.ClickEvent{LinkID: 1, IPAddress: geoiptest.IPParis, RuleID: &ruleID, VariantID: &variantID,
+			Token: "tok", Source: models.ClickSourceQR},
+		models.ClickEvent{LinkID: 1, IPAddress: geoiptest.IPSwiss},
+		models.ClickEvent{LinkID: 2, IPAddress: geoiptest.IPBrussels},
+		models.ClickEvent{LinkID: 2, IPAddress: geoiptest.IPUnknown},
+	)
+
+	want := []struct{ country, city string }{{"FR", "Paris"}, {"CH", ""}, {"BE", "Brussels"}, {"", ""}}
+	for i, click := range clicks {
+		if click.Country != want[i].country || click.City != want[i].city {
+			t.Errorf("click %d (%s): location = %q/%q, want %q/%q",
+				i, click.IPAddress, click.Country, click.City, want[i].country, want[i].city)
+		}
+	}
+
+	// Les autres champs de l'événement sont conservés.
+	first := clicks[0]
+	if first.LinkID != 1 || first.RuleID == nil || *first.RuleID != ruleID || first.VariantID == nil || *first.VariantID != variantID {
+		t.Errorf("click 0: link/rule/variant not kept: %+v", first)
+	}
+	if first.Token == nil || *first.Token != "tok" || first.Source != models.ClickSourceQR {
+		t.Errorf("click 0: token/source not kept: %+v", first)
+	}
+	if clicks[1].Token != nil {
+		t.Errorf("click 1: token = %q, want nil", *clicks[1].Token)
+	}
+}
+
+func TestClickWorkerWithoutLocator(t *testing.T) {
+	clicks := process(t, nil, models.ClickEvent{LinkID: 1, IPAddress: geoiptest.IPParis})
+	if clicks[0].Country != "" || clicks[0].City != "" {
+		t.Errorf("location without GeoIP database = %q/%q, want empty", clicks[0].Country, clicks[0].City)
+	}
+}